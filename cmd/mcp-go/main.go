@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
@@ -42,6 +43,10 @@ func main() {
 	initDebugLogging(cfg)
 
 	debugLog("Configuration loaded: DebugMCP=%v, WorkingDirectory=%s", cfg.DebugMCP, cfg.WorkingDirectory)
+	if cfg.ConfigFile != "" {
+		log.Printf("Using configuration file %s", cfg.ConfigFile)
+	}
+	reportConfigErrors(cfg.ConfigErrors)
 
 	// Create MCP server
 	server := mcp.NewServer(
@@ -49,15 +54,56 @@ func main() {
 			Name:    name,
 			Version: version,
 		},
-		nil,
+		&mcp.ServerOptions{
+			SubscribeHandler:   tools.SubscribeResource,
+			UnsubscribeHandler: tools.UnsubscribeResource,
+		},
 	)
 
 	debugLog("MCP server created: %s v%s", name, version)
 
+	// Set up audit logging
+	if err := utils.InitAuditLogger(cfg); err != nil {
+		log.Printf("Failed to open audit log %s: %v", cfg.AuditLogPath, err)
+	} else if cfg.AuditLogPath != "" {
+		debugLog("Audit log enabled: %s", cfg.AuditLogPath)
+	}
+	server.AddReceivingMiddleware(tools.AuditMiddleware())
+
 	// Initialize subsystems
 	tools.InitServerManager()
 	tools.InitPackageDocsCache()
-	debugLog("Subsystems initialized: ServerManager, PackageDocsCache")
+	tools.InitJobManager(cfg)
+	debugLog("Subsystems initialized: ServerManager, PackageDocsCache, JobManager")
+
+	// Restore servers of an earlier run, which may still be running
+	if report, err := tools.RestoreServers(cfg); err != nil {
+		log.Printf("Failed to restore servers from %s: %v", cfg.ServerStateFile, err)
+	} else {
+		for _, id := range report.Reattached {
+			log.Printf("Reattached to server %s", id)
+		}
+		for _, id := range report.Stopped {
+			log.Printf("Stopped orphaned server %s", id)
+		}
+		for _, id := range report.Ignored {
+			log.Printf("Ignored orphaned server %s", id)
+		}
+		for _, id := range report.Unverified {
+			log.Printf("Server %s may still be running but cannot be verified on this platform", id)
+		}
+		if n := len(report.Exited) + len(report.Restored); n > 0 {
+			debugLog("Restored %d stopped servers", n)
+		}
+	}
+
+	// Apply the retention period to server log files of earlier runs,
+	// keeping those of servers that are still running
+	if removed, err := tools.PurgeExpiredServerLogs(cfg); err != nil {
+		log.Printf("Failed to clean up server logs in %s: %v", cfg.ServerLogDir, err)
+	} else if removed > 0 {
+		debugLog("Removed %d expired server log files", removed)
+	}
 
 	// Register all tools
 	log.Println("Registering tools...")
@@ -74,9 +120,15 @@ func main() {
 	serverToolsCount := tools.RegisterServerTools(server, cfg)
 	debugLog("Registered server tools: %d tools", serverToolsCount)
 	toolCount += serverToolsCount
+	stackToolsCount := tools.RegisterStackTools(server, cfg)
+	debugLog("Registered stack tools: %d tools", stackToolsCount)
+	toolCount += stackToolsCount
 	pkgDocsToolsCount := tools.RegisterPackageDocsTools(server, cfg)
 	debugLog("Registered package docs tools: %d tools", pkgDocsToolsCount)
 	toolCount += pkgDocsToolsCount
+	jobToolsCount := tools.RegisterJobTools(server, cfg)
+	debugLog("Registered job tools: %d tools", jobToolsCount)
+	toolCount += jobToolsCount
 
 	// Conditionally register LSP tools
 	if cfg.EnableLSP {
@@ -90,6 +142,7 @@ func main() {
 	// Register resources
 	log.Println("Registering resources...")
 	resourceCount := resources.RegisterGoResources(server, cfg)
+	resourceCount += tools.RegisterServerResources(server, cfg)
 	debugLog("Registered %d resources", resourceCount)
 	log.Printf("Registered %d resources", resourceCount)
 
@@ -99,6 +152,13 @@ func main() {
 	debugLog("Registered %d prompts", promptCount)
 	log.Printf("Registered %d prompts", promptCount)
 
+	// Report tools, resources and prompts disabled in the configuration file
+	disabledTools, disabledResources, disabledPrompts, problems := checkEnablement(cfg)
+	reportConfigErrors(problems)
+	if disabledTools+disabledResources+disabledPrompts > 0 {
+		log.Printf("Disabled by configuration: %d tools, %d resources, %d prompts", disabledTools, disabledResources, disabledPrompts)
+	}
+
 	debugLog("Server initialization complete: %d tools, %d resources, %d prompts", toolCount, resourceCount, promptCount)
 
 	// Set up signal handling for graceful shutdown
@@ -113,3 +173,46 @@ func main() {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// checkEnablement returns how many tools, resources and prompts the
+// configuration disabled, along with problems for names that do not match
+// any tool, resource or prompt.
+func checkEnablement(cfg *config.Config) (int, int, int, []string) {
+	var problems []string
+	var toolCount, resourceCount, promptCount int
+
+	for name := range cfg.Tools {
+		switch {
+		case resources.IsToolDisabled(name):
+			toolCount++
+		case !resources.IsToolRegistered(name):
+			problems = append(problems, fmt.Sprintf("tools.%s: unknown tool", name))
+		}
+	}
+	for _, uri := range cfg.DisabledResources {
+		if resources.IsResourceDisabled(uri) {
+			resourceCount++
+		} else {
+			problems = append(problems, fmt.Sprintf("resources.disabled: unknown resource %s", uri))
+		}
+	}
+	for _, name := range cfg.DisabledPrompts {
+		if resources.IsPromptDisabled(name) {
+			promptCount++
+		} else {
+			problems = append(problems, fmt.Sprintf("prompts.disabled: unknown prompt %s", name))
+		}
+	}
+	return toolCount, resourceCount, promptCount, problems
+}
+
+// reportConfigErrors logs configuration validation problems
+func reportConfigErrors(problems []string) {
+	if len(problems) == 0 {
+		return
+	}
+	log.Printf("Configuration has %d problem(s):", len(problems))
+	for _, problem := range problems {
+		log.Printf("  - %s", problem)
+	}
+}
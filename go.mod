@@ -2,7 +2,10 @@ module github.com/inja-online/golang-mcp
 
 go 1.23.0
 
-require github.com/modelcontextprotocol/go-sdk v1.1.0
+require (
+	github.com/modelcontextprotocol/go-sdk v1.1.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/google/jsonschema-go v0.3.0 // indirect
@@ -4,6 +4,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds the server configuration
@@ -17,19 +20,46 @@ type Config struct {
 	GoArch               string
 	GoProxy              string
 	WorkingDirectory     string
+	MaxConcurrentJobs    int
+	JobResultTTL         time.Duration
+	AuditLogPath         string
+	AuditLogMaxSize      int64
+	AuditLogMaxBackups   int
+	ServerLogDir         string
+	ServerLogMaxSize     int64
+	ServerLogMaxBackups  int
+	ServerLogRetention   time.Duration
+	ServerStateFile      string
+	ServerOrphanPolicy   string
+	EnvAllowlist         []string
+	EnvDenylist          []string
+	DisableRedaction     bool
+	Offline              bool
+	OfflineModMode       string
+	WorkspaceRoots       []string
+	RestrictToWorkspace  bool
+	PackageLayers        []PackageLayer
+	PackageRules         []PackageRule
+	Tools                map[string]ToolConfig
+	DisabledResources    []string
+	DisabledPrompts      []string
+	ConfigFile           string
+	ConfigErrors         []string
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from the optional configuration file and from
+// environment variables. Environment variables override file values.
 func Load() *Config {
 	cfg := &Config{
-		DisableNotifications: os.Getenv("DISABLE_NOTIFICATIONS") == "true",
-		DebugMCP:             os.Getenv("DEBUG_MCP") == "true",
-		EnableLSP:            os.Getenv("ENABLE_LSP") == "true",
-		GoRoot:               os.Getenv("GOROOT"),
-		GoPath:               os.Getenv("GOPATH"),
-		GoOS:                 getEnvOrDefault("GOOS", ""),
-		GoArch:               getEnvOrDefault("GOARCH", ""),
-		GoProxy:              os.Getenv("GOPROXY"),
+		MaxConcurrentJobs:   4,
+		JobResultTTL:        time.Hour,
+		AuditLogMaxSize:     10 * 1024 * 1024,
+		AuditLogMaxBackups:  5,
+		ServerLogMaxSize:    10 * 1024 * 1024,
+		ServerLogMaxBackups: 3,
+		ServerLogRetention:  7 * 24 * time.Hour,
+		OfflineModMode:      "mod",
+		ServerOrphanPolicy:  "reattach",
 	}
 
 	// Get working directory
@@ -40,6 +70,38 @@ func Load() *Config {
 		cfg.WorkingDirectory = "."
 	}
 
+	// Load configuration file if present
+	if path, trusted := findConfigFile(cfg.WorkingDirectory); path != "" {
+		cfg.ConfigFile = path
+		cfg.loadFile(path, trusted)
+	}
+
+	cfg.DisableNotifications = getEnvBool("DISABLE_NOTIFICATIONS", cfg.DisableNotifications)
+	cfg.DebugMCP = getEnvBool("DEBUG_MCP", cfg.DebugMCP)
+	cfg.EnableLSP = getEnvBool("ENABLE_LSP", cfg.EnableLSP)
+	cfg.GoRoot = getEnvOrDefault("GOROOT", cfg.GoRoot)
+	cfg.GoPath = getEnvOrDefault("GOPATH", cfg.GoPath)
+	cfg.GoOS = getEnvOrDefault("GOOS", cfg.GoOS)
+	cfg.GoArch = getEnvOrDefault("GOARCH", cfg.GoArch)
+	cfg.GoProxy = getEnvOrDefault("GOPROXY", cfg.GoProxy)
+	cfg.MaxConcurrentJobs = getEnvInt("MAX_CONCURRENT_JOBS", cfg.MaxConcurrentJobs)
+	cfg.JobResultTTL = getEnvDuration("JOB_RESULT_TTL", cfg.JobResultTTL)
+	cfg.AuditLogPath = getEnvOrDefault("AUDIT_LOG_PATH", cfg.AuditLogPath)
+	cfg.AuditLogMaxSize = int64(getEnvInt("AUDIT_LOG_MAX_SIZE", int(cfg.AuditLogMaxSize)))
+	cfg.AuditLogMaxBackups = getEnvInt("AUDIT_LOG_MAX_BACKUPS", cfg.AuditLogMaxBackups)
+	cfg.ServerLogDir = getEnvOrDefault("SERVER_LOG_DIR", cfg.ServerLogDir)
+	cfg.ServerLogMaxSize = int64(getEnvInt("SERVER_LOG_MAX_SIZE", int(cfg.ServerLogMaxSize)))
+	cfg.ServerLogMaxBackups = getEnvInt("SERVER_LOG_MAX_BACKUPS", cfg.ServerLogMaxBackups)
+	cfg.ServerLogRetention = getEnvDuration("SERVER_LOG_RETENTION", cfg.ServerLogRetention)
+	cfg.ServerStateFile = getEnvOrDefault("SERVER_STATE_FILE", cfg.ServerStateFile)
+	cfg.ServerOrphanPolicy = getEnvOrDefault("SERVER_ORPHAN_POLICY", cfg.ServerOrphanPolicy)
+	cfg.EnvAllowlist = getEnvList("ENV_ALLOWLIST", cfg.EnvAllowlist)
+	cfg.EnvDenylist = getEnvList("ENV_DENYLIST", cfg.EnvDenylist)
+	cfg.DisableRedaction = getEnvBool("DISABLE_REDACTION", cfg.DisableRedaction)
+	cfg.Offline = getEnvBool("OFFLINE_MODE", cfg.Offline)
+	cfg.OfflineModMode = getEnvOrDefault("OFFLINE_MOD_MODE", cfg.OfflineModMode)
+	cfg.validate()
+
 	// Detect Go environment if not set
 	if cfg.GoRoot == "" {
 		cfg.GoRoot = detectGoRoot()
@@ -68,9 +130,32 @@ func (c *Config) GetGoEnv() map[string]string {
 		env["GOPROXY"] = c.GoProxy
 	}
 
+	// Offline mode resolves modules from the local cache or vendor directory only
+	if c.Offline {
+		env["GOPROXY"] = "off"
+		env["GOSUMDB"] = "off"
+		env["GOVCS"] = "*:off"
+		env["GOTOOLCHAIN"] = "local"
+		env["GOFLAGS"] = offlineGoFlags(os.Getenv("GOFLAGS"), c.OfflineModMode)
+	}
+
 	return env
 }
 
+// offlineGoFlags replaces any -mod flag in goFlags with the offline module mode
+func offlineGoFlags(goFlags, modMode string) string {
+	if modMode != "vendor" {
+		modMode = "mod"
+	}
+	flags := []string{"-mod=" + modMode}
+	for _, flag := range strings.Fields(goFlags) {
+		if !strings.HasPrefix(flag, "-mod=") && !strings.HasPrefix(flag, "--mod=") {
+			flags = append(flags, flag)
+		}
+	}
+	return strings.Join(flags, " ")
+}
+
 // getEnvOrDefault returns the environment variable value or a default
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -79,6 +164,49 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt returns the environment variable parsed as a positive integer or a default
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration returns the environment variable parsed as a duration or a default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool returns whether the environment variable is "true", or a default when unset
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		return value == "true"
+	}
+	return defaultValue
+}
+
+// getEnvList returns the comma-separated environment variable as a list or a default
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 // detectGoRoot attempts to detect GOROOT by finding the go binary
 func detectGoRoot() string {
 	goBin, err := exec.LookPath("go")
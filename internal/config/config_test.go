@@ -153,6 +153,23 @@ func TestGetGoEnv_EmptyValues(t *testing.T) {
 	}
 }
 
+func TestGetGoEnv_Offline(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=readonly -trimpath")
+	cfg := &Config{GoProxy: "https://proxy.golang.org", Offline: true, OfflineModMode: "vendor"}
+
+	env := cfg.GetGoEnv()
+
+	if env["GOPROXY"] != "off" {
+		t.Errorf("Expected GOPROXY to be off, got %q", env["GOPROXY"])
+	}
+	if env["GOSUMDB"] != "off" {
+		t.Errorf("Expected GOSUMDB to be off, got %q", env["GOSUMDB"])
+	}
+	if env["GOFLAGS"] != "-mod=vendor -trimpath" {
+		t.Errorf("Expected GOFLAGS to be %q, got %q", "-mod=vendor -trimpath", env["GOFLAGS"])
+	}
+}
+
 func TestDetectGoRoot(t *testing.T) {
 	// This test depends on having Go installed
 	// We'll just verify it doesn't panic and returns something reasonable
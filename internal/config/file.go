@@ -0,0 +1,411 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the configuration file looked up in the
+// working directory.
+const ConfigFileName = ".mcp-go.yaml"
+
+// ToolConfig holds per-tool settings from the configuration file.
+type ToolConfig struct {
+	Enabled *bool         // nil means enabled
+	Tags    []string      // default build tags
+	Race    bool          // enable the race detector by default
+	Timeout time.Duration // default timeout
+}
+
+// PackageLayer is a named group of packages in the layer rules of the
+// configuration file. Layers are listed from the top: a package may import
+// packages of its own layer and of the layers below it, but not above.
+type PackageLayer struct {
+	Name     string   `yaml:"name"`
+	Packages []string `yaml:"packages"` // import path patterns, optionally relative to the module
+}
+
+// PackageRule forbids packages matching From to import packages matching
+// any of Deny.
+type PackageRule struct {
+	From   string   `yaml:"from"`
+	Deny   []string `yaml:"deny"`
+	Reason string   `yaml:"reason"`
+}
+
+// fileConfig mirrors the layout of the configuration file.
+type fileConfig struct {
+	DisableNotifications *bool          `yaml:"disable_notifications"`
+	Debug                *bool          `yaml:"debug"`
+	EnableLSP            *bool          `yaml:"enable_lsp"`
+	Offline              *bool          `yaml:"offline"`
+	OfflineModMode       string         `yaml:"offline_mod_mode"`
+	DisableRedaction     *bool          `yaml:"disable_redaction"`
+	MaxConcurrentJobs    int            `yaml:"max_concurrent_jobs"`
+	JobResultTTL         string         `yaml:"job_result_ttl"`
+	Go                   goFile         `yaml:"go"`
+	Audit                auditFile      `yaml:"audit"`
+	ServerLogs           serverLogsFile `yaml:"server_logs"`
+	Servers              serversFile    `yaml:"servers"`
+	Env                  envFile        `yaml:"env"`
+	Packages             packagesFile   `yaml:"packages"`
+
+	Workspace struct {
+		Roots    []string `yaml:"roots"`
+		Restrict bool     `yaml:"restrict"`
+	} `yaml:"workspace"`
+
+	Tools     map[string]toolFile `yaml:"tools"`
+	Resources enablementFile      `yaml:"resources"`
+	Prompts   enablementFile      `yaml:"prompts"`
+}
+
+type goFile struct {
+	Root  string `yaml:"root"`
+	Path  string `yaml:"path"`
+	OS    string `yaml:"os"`
+	Arch  string `yaml:"arch"`
+	Proxy string `yaml:"proxy"`
+}
+
+type auditFile struct {
+	Path       string `yaml:"path"`
+	MaxSize    int64  `yaml:"max_size"`
+	MaxBackups int    `yaml:"max_backups"`
+}
+
+type serverLogsFile struct {
+	Dir        string `yaml:"dir"`
+	MaxSize    int64  `yaml:"max_size"`
+	MaxBackups int    `yaml:"max_backups"`
+	Retention  string `yaml:"retention"`
+}
+
+type serversFile struct {
+	StateFile string `yaml:"state_file"`
+	Orphans   string `yaml:"orphans"`
+}
+
+type packagesFile struct {
+	Layers []PackageLayer `yaml:"layers"`
+	Rules  []PackageRule  `yaml:"rules"`
+}
+
+type envFile struct {
+	Allowlist []string `yaml:"allowlist"`
+	Denylist  []string `yaml:"denylist"`
+}
+
+type toolFile struct {
+	Enabled *bool    `yaml:"enabled"`
+	Tags    []string `yaml:"tags"`
+	Race    bool     `yaml:"race"`
+	Timeout string   `yaml:"timeout"`
+}
+
+type enablementFile struct {
+	Disabled []string `yaml:"disabled"`
+}
+
+// findConfigFile returns the configuration file to load: MCP_GO_CONFIG if set,
+// then .mcp-go.yaml in the working directory, then mcp-go/config.yaml in the
+// user configuration directory (XDG_CONFIG_HOME on Linux). trusted is false
+// for the file in the working directory, which comes with the code being
+// worked on rather than from the user.
+func findConfigFile(workingDir string) (path string, trusted bool) {
+	if path := os.Getenv("MCP_GO_CONFIG"); path != "" {
+		return path, true
+	}
+
+	if path := filepath.Join(workingDir, ConfigFileName); fileExists(path) {
+		return path, false
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		if path := filepath.Join(dir, "mcp-go", "config.yaml"); fileExists(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// untrustedSettings returns the security settings set in file, which an
+// untrusted file may not change: they turn off permission prompts or
+// redaction, widen the environment passed to commands or move the audit log.
+func untrustedSettings(file *fileConfig) []string {
+	var keys []string
+	if file.DisableNotifications != nil {
+		keys = append(keys, "disable_notifications")
+	}
+	if file.DisableRedaction != nil {
+		keys = append(keys, "disable_redaction")
+	}
+	if len(file.Env.Allowlist) > 0 {
+		keys = append(keys, "env.allowlist")
+	}
+	if len(file.Env.Denylist) > 0 {
+		keys = append(keys, "env.denylist")
+	}
+	if file.Audit.Path != "" {
+		keys = append(keys, "audit.path")
+	}
+	return keys
+}
+
+// loadFile reads the configuration file at path into cfg. Problems are
+// appended to cfg.ConfigErrors; valid settings are still applied. Security
+// settings in an untrusted file are reported and ignored.
+func (c *Config) loadFile(path string, trusted bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.addConfigError("%v", err)
+		return
+	}
+
+	var file fileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		c.addConfigError("%v", err)
+		return
+	}
+
+	if !trusted {
+		for _, key := range untrustedSettings(&file) {
+			c.addConfigError("%s is ignored in %s; set it in MCP_GO_CONFIG or the user configuration file", key, ConfigFileName)
+		}
+		file.DisableNotifications = nil
+		file.DisableRedaction = nil
+		file.Env = envFile{}
+		file.Audit.Path = ""
+	}
+
+	setBool(&c.DisableNotifications, file.DisableNotifications)
+	setBool(&c.DebugMCP, file.Debug)
+	setBool(&c.EnableLSP, file.EnableLSP)
+	setBool(&c.Offline, file.Offline)
+	setBool(&c.DisableRedaction, file.DisableRedaction)
+	setString(&c.OfflineModMode, file.OfflineModMode)
+	setString(&c.GoRoot, file.Go.Root)
+	setString(&c.GoPath, file.Go.Path)
+	setString(&c.GoOS, file.Go.OS)
+	setString(&c.GoArch, file.Go.Arch)
+	setString(&c.GoProxy, file.Go.Proxy)
+	setString(&c.AuditLogPath, file.Audit.Path)
+
+	if file.MaxConcurrentJobs < 0 {
+		c.addConfigError("max_concurrent_jobs must be positive, got %d", file.MaxConcurrentJobs)
+	} else if file.MaxConcurrentJobs > 0 {
+		c.MaxConcurrentJobs = file.MaxConcurrentJobs
+	}
+	if file.JobResultTTL != "" {
+		if d, err := parsePositiveDuration(file.JobResultTTL); err != nil {
+			c.addConfigError("job_result_ttl: %v", err)
+		} else {
+			c.JobResultTTL = d
+		}
+	}
+	if file.Audit.MaxSize > 0 {
+		c.AuditLogMaxSize = file.Audit.MaxSize
+	}
+	if file.Audit.MaxBackups > 0 {
+		c.AuditLogMaxBackups = file.Audit.MaxBackups
+	}
+	if file.ServerLogs.Dir != "" {
+		dir := file.ServerLogs.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		c.ServerLogDir = filepath.Clean(dir)
+	}
+	if file.ServerLogs.MaxSize > 0 {
+		c.ServerLogMaxSize = file.ServerLogs.MaxSize
+	}
+	if file.ServerLogs.MaxBackups > 0 {
+		c.ServerLogMaxBackups = file.ServerLogs.MaxBackups
+	}
+	if file.ServerLogs.Retention != "" {
+		if d, err := parsePositiveDuration(file.ServerLogs.Retention); err != nil {
+			c.addConfigError("server_logs.retention: %v", err)
+		} else {
+			c.ServerLogRetention = d
+		}
+	}
+	if file.Servers.StateFile != "" {
+		stateFile := file.Servers.StateFile
+		if !filepath.IsAbs(stateFile) {
+			stateFile = filepath.Join(filepath.Dir(path), stateFile)
+		}
+		c.ServerStateFile = filepath.Clean(stateFile)
+	}
+	if file.Servers.Orphans != "" {
+		c.ServerOrphanPolicy = file.Servers.Orphans
+	}
+	if len(file.Env.Allowlist) > 0 {
+		c.EnvAllowlist = file.Env.Allowlist
+	}
+	if len(file.Env.Denylist) > 0 {
+		c.EnvDenylist = file.Env.Denylist
+	}
+
+	layerNames := make(map[string]bool)
+	for i, layer := range file.Packages.Layers {
+		switch {
+		case layer.Name == "":
+			c.addConfigError("packages.layers[%d]: name is required", i)
+		case layerNames[layer.Name]:
+			c.addConfigError("packages.layers[%d]: duplicate layer %s", i, layer.Name)
+		case len(layer.Packages) == 0:
+			c.addConfigError("packages.layers.%s: packages are required", layer.Name)
+		default:
+			layerNames[layer.Name] = true
+			c.PackageLayers = append(c.PackageLayers, layer)
+		}
+	}
+	for i, rule := range file.Packages.Rules {
+		if rule.From == "" || len(rule.Deny) == 0 {
+			c.addConfigError("packages.rules[%d]: from and deny are required", i)
+			continue
+		}
+		c.PackageRules = append(c.PackageRules, rule)
+	}
+
+	// Relative workspace roots are resolved against the file's directory
+	for _, root := range file.Workspace.Roots {
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(filepath.Dir(path), root)
+		}
+		root = filepath.Clean(root)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			c.addConfigError("workspace root %s is not a directory", root)
+			continue
+		}
+		c.WorkspaceRoots = append(c.WorkspaceRoots, root)
+	}
+	c.RestrictToWorkspace = file.Workspace.Restrict
+
+	for name, tool := range file.Tools {
+		toolCfg := ToolConfig{Enabled: tool.Enabled, Tags: tool.Tags, Race: tool.Race}
+		if tool.Timeout != "" {
+			d, err := parsePositiveDuration(tool.Timeout)
+			if err != nil {
+				c.addConfigError("tools.%s.timeout: %v", name, err)
+			}
+			toolCfg.Timeout = d
+		}
+		if c.Tools == nil {
+			c.Tools = make(map[string]ToolConfig)
+		}
+		c.Tools[name] = toolCfg
+	}
+	c.DisabledResources = file.Resources.Disabled
+	c.DisabledPrompts = file.Prompts.Disabled
+}
+
+// validate checks settings that may come from either the file or the environment
+func (c *Config) validate() {
+	if c.OfflineModMode != "mod" && c.OfflineModMode != "vendor" {
+		c.addConfigError("offline_mod_mode must be \"mod\" or \"vendor\", got %q", c.OfflineModMode)
+		c.OfflineModMode = "mod"
+	}
+	switch c.ServerOrphanPolicy {
+	case "reattach", "stop", "ignore":
+	default:
+		c.addConfigError("servers.orphans must be \"reattach\", \"stop\" or \"ignore\", got %q", c.ServerOrphanPolicy)
+		c.ServerOrphanPolicy = "reattach"
+	}
+	if c.RestrictToWorkspace && len(c.WorkspaceRoots) == 0 {
+		c.WorkspaceRoots = []string{c.WorkingDirectory}
+	}
+}
+
+func (c *Config) addConfigError(format string, args ...interface{}) {
+	c.ConfigErrors = append(c.ConfigErrors, fmt.Sprintf(format, args...))
+}
+
+// ToolEnabled reports whether the tool should be registered.
+func (c *Config) ToolEnabled(name string) bool {
+	tool, ok := c.Tools[name]
+	return !ok || tool.Enabled == nil || *tool.Enabled
+}
+
+// ToolDefaults returns the configured defaults for a tool.
+func (c *Config) ToolDefaults(name string) ToolConfig {
+	return c.Tools[name]
+}
+
+// ResourceEnabled reports whether the resource with the given URI should be registered.
+func (c *Config) ResourceEnabled(uri string) bool {
+	return !containsString(c.DisabledResources, uri)
+}
+
+// PromptEnabled reports whether the prompt should be registered.
+func (c *Config) PromptEnabled(name string) bool {
+	return !containsString(c.DisabledPrompts, name)
+}
+
+// CheckWorkingDir returns an error if workspace restriction is enabled and
+// dir is outside every workspace root. An empty dir means the working directory.
+func (c *Config) CheckWorkingDir(dir string) error {
+	if !c.RestrictToWorkspace {
+		return nil
+	}
+	if dir == "" {
+		dir = c.WorkingDirectory
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid working directory %s: %w", dir, err)
+	}
+	for _, root := range c.WorkspaceRoots {
+		rel, err := filepath.Rel(root, abs)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("working directory %s is outside the configured workspace roots", dir)
+}
+
+func setBool(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive, got %s", value)
+	}
+	return d, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
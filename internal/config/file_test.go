@@ -0,0 +1,229 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+// unsetEnv unsets key for the duration of the test
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+offline: true
+max_concurrent_jobs: 8
+job_result_ttl: 10m
+env:
+  denylist: [NPM_TOKEN]
+server_logs:
+  dir: logs
+  max_backups: 2
+  retention: 48h
+servers:
+  state_file: state/servers.json
+  orphans: stop
+workspace:
+  roots: [.]
+  restrict: true
+packages:
+  layers:
+    - name: cmd
+      packages: [cmd/...]
+    - name: internal
+      packages: [internal/...]
+  rules:
+    - from: internal/utils/...
+      deny: [internal/tools/...]
+      reason: utils must not depend on tool registration
+tools:
+  go_server_start:
+    enabled: false
+  go_test:
+    tags: [integration]
+    race: true
+    timeout: 5m
+resources:
+  disabled: ["go://audit"]
+prompts:
+  disabled: [go-code-review]
+`)
+	t.Setenv("MCP_GO_CONFIG", path)
+	t.Setenv("MAX_CONCURRENT_JOBS", "2")
+	unsetEnv(t, "OFFLINE_MODE")
+	unsetEnv(t, "SERVER_LOG_DIR")
+	unsetEnv(t, "SERVER_STATE_FILE")
+	unsetEnv(t, "SERVER_ORPHAN_POLICY")
+
+	cfg := Load()
+
+	if cfg.ConfigFile != path {
+		t.Errorf("Expected ConfigFile %q, got %q", path, cfg.ConfigFile)
+	}
+	if len(cfg.ConfigErrors) != 0 {
+		t.Errorf("Unexpected config errors: %v", cfg.ConfigErrors)
+	}
+	if !cfg.Offline {
+		t.Error("Expected offline mode from config file")
+	}
+	if cfg.MaxConcurrentJobs != 2 {
+		t.Errorf("Expected environment to override max_concurrent_jobs, got %d", cfg.MaxConcurrentJobs)
+	}
+	if cfg.JobResultTTL != 10*time.Minute {
+		t.Errorf("Expected job_result_ttl of 10m, got %v", cfg.JobResultTTL)
+	}
+	if len(cfg.EnvDenylist) != 1 || cfg.EnvDenylist[0] != "NPM_TOKEN" {
+		t.Errorf("Unexpected env denylist: %v", cfg.EnvDenylist)
+	}
+	if cfg.ServerLogDir != filepath.Join(filepath.Dir(path), "logs") {
+		t.Errorf("Expected server log dir relative to the config file, got %q", cfg.ServerLogDir)
+	}
+	if cfg.ServerLogMaxBackups != 2 || cfg.ServerLogRetention != 48*time.Hour {
+		t.Errorf("Unexpected server log settings: backups %d, retention %v", cfg.ServerLogMaxBackups, cfg.ServerLogRetention)
+	}
+	if cfg.ServerStateFile != filepath.Join(filepath.Dir(path), "state", "servers.json") || cfg.ServerOrphanPolicy != "stop" {
+		t.Errorf("Unexpected server state settings: file %q, orphans %q", cfg.ServerStateFile, cfg.ServerOrphanPolicy)
+	}
+	if len(cfg.PackageLayers) != 2 || cfg.PackageLayers[1].Name != "internal" || cfg.PackageLayers[0].Packages[0] != "cmd/..." {
+		t.Errorf("Unexpected package layers: %+v", cfg.PackageLayers)
+	}
+	if len(cfg.PackageRules) != 1 || cfg.PackageRules[0].From != "internal/utils/..." || cfg.PackageRules[0].Reason == "" {
+		t.Errorf("Unexpected package rules: %+v", cfg.PackageRules)
+	}
+
+	if cfg.ToolEnabled("go_server_start") {
+		t.Error("Expected go_server_start to be disabled")
+	}
+	if !cfg.ToolEnabled("go_build") {
+		t.Error("Expected unconfigured tool to be enabled")
+	}
+	defaults := cfg.ToolDefaults("go_test")
+	if !defaults.Race || defaults.Timeout != 5*time.Minute || len(defaults.Tags) != 1 {
+		t.Errorf("Unexpected go_test defaults: %+v", defaults)
+	}
+	if cfg.ResourceEnabled("go://audit") || !cfg.ResourceEnabled("go://modules") {
+		t.Error("Unexpected resource enablement")
+	}
+	if cfg.PromptEnabled("go-code-review") {
+		t.Error("Expected go-code-review prompt to be disabled")
+	}
+
+	root := filepath.Dir(path)
+	if err := cfg.CheckWorkingDir(filepath.Join(root, "sub")); err != nil {
+		t.Errorf("Expected directory inside workspace root to be allowed: %v", err)
+	}
+	if err := cfg.CheckWorkingDir(filepath.Dir(root)); err == nil {
+		t.Error("Expected directory outside workspace root to be rejected")
+	}
+}
+
+func TestLoad_ConfigFileErrors(t *testing.T) {
+	path := writeConfigFile(t, `
+offline_mod_mode: readonly
+job_result_ttl: soon
+servers:
+  orphans: adopt
+packages:
+  layers:
+    - name: api
+    - packages: [internal/...]
+  rules:
+    - from: internal/...
+workspace:
+  roots: [does-not-exist]
+tools:
+  go_test:
+    timeout: -1s
+`)
+	t.Setenv("MCP_GO_CONFIG", path)
+	unsetEnv(t, "OFFLINE_MOD_MODE")
+	unsetEnv(t, "SERVER_ORPHAN_POLICY")
+
+	cfg := Load()
+
+	for _, want := range []string{"offline_mod_mode", "job_result_ttl", "servers.orphans", "does-not-exist", "tools.go_test.timeout", "packages.layers.api", "packages.layers[1]", "packages.rules[0]"} {
+		found := false
+		for _, problem := range cfg.ConfigErrors {
+			if strings.Contains(problem, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a problem mentioning %q, got %v", want, cfg.ConfigErrors)
+		}
+	}
+	if cfg.OfflineModMode != "mod" {
+		t.Errorf("Expected invalid offline_mod_mode to fall back to mod, got %q", cfg.OfflineModMode)
+	}
+	if cfg.ServerOrphanPolicy != "reattach" {
+		t.Errorf("Expected invalid servers.orphans to fall back to reattach, got %q", cfg.ServerOrphanPolicy)
+	}
+
+	t.Setenv("MCP_GO_CONFIG", writeConfigFile(t, "unknown_key: 1\n"))
+	if cfg := Load(); len(cfg.ConfigErrors) != 1 {
+		t.Errorf("Expected one error for unknown key, got %v", cfg.ConfigErrors)
+	}
+}
+
+func TestLoad_WorkspaceConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+disable_notifications: true
+disable_redaction: true
+offline: true
+audit:
+  path: /tmp/audit.jsonl
+env:
+  allowlist: [AWS_SECRET_ACCESS_KEY]
+`)
+	unsetEnv(t, "MCP_GO_CONFIG")
+	if found, trusted := findConfigFile(filepath.Dir(path)); found != path || trusted {
+		t.Errorf("findConfigFile = %q, %v, want %q, false", found, trusted, path)
+	}
+
+	cfg := &Config{}
+	cfg.loadFile(path, false)
+	if cfg.DisableNotifications || cfg.DisableRedaction || cfg.AuditLogPath != "" || len(cfg.EnvAllowlist) != 0 {
+		t.Errorf("Expected security settings from the working directory to be ignored, got %+v", cfg)
+	}
+	if !cfg.Offline {
+		t.Error("Expected other settings from the working directory to apply")
+	}
+	for _, key := range []string{"disable_notifications", "disable_redaction", "audit.path", "env.allowlist"} {
+		found := false
+		for _, problem := range cfg.ConfigErrors {
+			if strings.Contains(problem, key) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a problem mentioning %q, got %v", key, cfg.ConfigErrors)
+		}
+	}
+
+	// The same file is trusted when named by MCP_GO_CONFIG
+	t.Setenv("MCP_GO_CONFIG", path)
+	if _, trusted := findConfigFile(t.TempDir()); !trusted {
+		t.Error("Expected the file in MCP_GO_CONFIG to be trusted")
+	}
+	cfg = &Config{}
+	cfg.loadFile(path, true)
+	if !cfg.DisableNotifications || !cfg.DisableRedaction || len(cfg.ConfigErrors) != 0 {
+		t.Errorf("Expected security settings from a trusted file, got %+v", cfg)
+	}
+}
@@ -23,8 +23,7 @@ func RegisterGoPrompts(server *mcp.Server, cfg *config.Config) int {
 			Required:    false,
 		},
 	}
-	resources.RegisterPrompt("setup-go-project", "Guide for setting up a new Go project with module initialization, directory structure, and best practices", setupArgs)
-	server.AddPrompt(&mcp.Prompt{
+	count += resources.AddPrompt(server, cfg, &mcp.Prompt{
 		Name:        "setup-go-project",
 		Description: "Guide for setting up a new Go project with module initialization, directory structure, and best practices",
 		Arguments:   setupArgs,
@@ -66,7 +65,6 @@ Use go_build to verify the project builds correctly.`
 			},
 		}, nil
 	})
-	count++
 
 	writeTestsArgs := []*mcp.PromptArgument{
 		{
@@ -80,8 +78,7 @@ Use go_build to verify the project builds correctly.`
 			Required:    false,
 		},
 	}
-	resources.RegisterPrompt("write-go-tests", "Template for writing comprehensive Go tests including unit tests, benchmarks, and table-driven tests", writeTestsArgs)
-	server.AddPrompt(&mcp.Prompt{
+	count += resources.AddPrompt(server, cfg, &mcp.Prompt{
 		Name:        "write-go-tests",
 		Description: "Template for writing comprehensive Go tests including unit tests, benchmarks, and table-driven tests",
 		Arguments:   writeTestsArgs,
@@ -143,7 +140,6 @@ Use go_race_detect tool to check for race conditions.`
 			},
 		}, nil
 	})
-	count++
 
 	optimizeArgs := []*mcp.PromptArgument{
 		{
@@ -157,8 +153,7 @@ Use go_race_detect tool to check for race conditions.`
 			Required:    false,
 		},
 	}
-	resources.RegisterPrompt("optimize-go-performance", "Guide for profiling and optimizing Go code performance using pprof, benchmarks, and race detection", optimizeArgs)
-	server.AddPrompt(&mcp.Prompt{
+	count += resources.AddPrompt(server, cfg, &mcp.Prompt{
 		Name:        "optimize-go-performance",
 		Description: "Guide for profiling and optimizing Go code performance using pprof, benchmarks, and race detection",
 		Arguments:   optimizeArgs,
@@ -225,7 +220,6 @@ Use go_optimize_suggest tool for automated optimization suggestions.`
 			},
 		}, nil
 	})
-	count++
 
 	debugArgs := []*mcp.PromptArgument{
 		{
@@ -239,8 +233,7 @@ Use go_optimize_suggest tool for automated optimization suggestions.`
 			Required:    false,
 		},
 	}
-	resources.RegisterPrompt("debug-go-issue", "Systematic approach to debugging Go programs including race conditions, panics, and performance issues", debugArgs)
-	server.AddPrompt(&mcp.Prompt{
+	count += resources.AddPrompt(server, cfg, &mcp.Prompt{
 		Name:        "debug-go-issue",
 		Description: "Systematic approach to debugging Go programs including race conditions, panics, and performance issues",
 		Arguments:   debugArgs,
@@ -319,7 +312,6 @@ After fixing, verify with:
 			},
 		}, nil
 	})
-	count++
 
 	addDepArgs := []*mcp.PromptArgument{
 		{
@@ -333,8 +325,7 @@ After fixing, verify with:
 			Required:    false,
 		},
 	}
-	resources.RegisterPrompt("add-go-dependency", "Guide for adding and managing Go dependencies using go mod", addDepArgs)
-	server.AddPrompt(&mcp.Prompt{
+	count += resources.AddPrompt(server, cfg, &mcp.Prompt{
 		Name:        "add-go-dependency",
 		Description: "Guide for adding and managing Go dependencies using go mod",
 		Arguments:   addDepArgs,
@@ -389,7 +380,6 @@ Best practices:
 			},
 		}, nil
 	})
-	count++
 
 	reviewArgs := []*mcp.PromptArgument{
 		{
@@ -398,8 +388,7 @@ Best practices:
 			Required:    false,
 		},
 	}
-	resources.RegisterPrompt("go-code-review", "Checklist for reviewing Go code including style, performance, security, and best practices", reviewArgs)
-	server.AddPrompt(&mcp.Prompt{
+	count += resources.AddPrompt(server, cfg, &mcp.Prompt{
 		Name:        "go-code-review",
 		Description: "Checklist for reviewing Go code including style, performance, security, and best practices",
 		Arguments:   reviewArgs,
@@ -470,7 +459,6 @@ Use the available tools to automate checks:
 			},
 		}, nil
 	})
-	count++
 
 	deployArgs := []*mcp.PromptArgument{
 		{
@@ -484,8 +472,7 @@ Use the available tools to automate checks:
 			Required:    false,
 		},
 	}
-	resources.RegisterPrompt("go-server-deployment", "Guide for building and deploying Go servers including cross-compilation, optimization, and production best practices", deployArgs)
-	server.AddPrompt(&mcp.Prompt{
+	count += resources.AddPrompt(server, cfg, &mcp.Prompt{
 		Name:        "go-server-deployment",
 		Description: "Guide for building and deploying Go servers including cross-compilation, optimization, and production best practices",
 		Arguments:   deployArgs,
@@ -555,6 +542,5 @@ After deployment:
 			},
 		}, nil
 	})
-	count++
 	return count
 }
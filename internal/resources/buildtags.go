@@ -0,0 +1,303 @@
+package resources
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"go/build"
+	"go/build/constraint"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// BuildTagsURITemplate is the URI template of the build tags resource with
+// evaluation for a target platform and tag set
+const BuildTagsURITemplate = "go://build-tags{?goos,goarch,tags}"
+
+// knownOS and knownArch are the values go/build recognizes in _GOOS and
+// _GOARCH file name suffixes
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+		"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+		"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+		"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// BuildTagsReport lists the build constraints of the Go files in a tree.
+type BuildTagsReport struct {
+	Tags       []string         `json:"tags"`  // tags referenced by any constraint
+	Files      []FileConstraint `json:"files"` // files with a constraint
+	FileCount  int              `json:"file_count"`
+	Evaluation *BuildEvaluation `json:"evaluation,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// FileConstraint is the build constraint of a single Go file.
+type FileConstraint struct {
+	File       string   `json:"file"`                 // slash-separated, relative to the scanned directory
+	Expression string   `json:"expression,omitempty"` // //go:build expression
+	Legacy     bool     `json:"legacy,omitempty"`     // expression built from // +build lines
+	GOOS       string   `json:"goos,omitempty"`       // implied by a _GOOS file name suffix
+	GOARCH     string   `json:"goarch,omitempty"`     // implied by a _GOARCH file name suffix
+	Tags       []string `json:"tags,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// BuildEvaluation lists the files included in a build for a platform and
+// tag set.
+type BuildEvaluation struct {
+	GOOS     string   `json:"goos"`
+	GOARCH   string   `json:"goarch"`
+	Tags     []string `json:"tags"`
+	Included []string `json:"included"`
+	Excluded []string `json:"excluded"`
+}
+
+// discoverBuildTags reports the //go:build and // +build constraints and
+// the file name constraints of the Go files under dir. Vendor, testdata and
+// hidden directories are skipped, as the go command does.
+func discoverBuildTags(dir string) *BuildTagsReport {
+	report := &BuildTagsReport{Tags: []string{}, Files: []FileConstraint{}}
+	tags := make(map[string]bool)
+
+	err := walkGoFiles(dir, func(path, rel string) {
+		report.FileCount++
+		fc := FileConstraint{File: rel}
+		fc.GOOS, fc.GOARCH = fileNameConstraint(filepath.Base(path))
+
+		if content, err := os.ReadFile(path); err != nil {
+			fc.Error = err.Error()
+		} else if expr, legacy, err := parseBuildConstraint(content); err != nil {
+			fc.Error = err.Error()
+		} else if expr != nil {
+			fc.Expression = expr.String()
+			fc.Legacy = legacy
+			fc.Tags = constraintTags(expr)
+			for _, tag := range fc.Tags {
+				tags[tag] = true
+			}
+		}
+
+		if fc.Expression != "" || fc.GOOS != "" || fc.GOARCH != "" || fc.Error != "" {
+			report.Files = append(report.Files, fc)
+		}
+	})
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	for tag := range tags {
+		report.Tags = append(report.Tags, tag)
+	}
+	sort.Strings(report.Tags)
+	return report
+}
+
+// evaluateBuildTags lists the Go files under dir that go build includes for
+// the given platform and tags. Empty goos and goarch default to the host.
+func evaluateBuildTags(dir, goos, goarch string, tags []string) *BuildEvaluation {
+	ctx := build.Default
+	if goos != "" {
+		ctx.GOOS = goos
+	}
+	if goarch != "" {
+		ctx.GOARCH = goarch
+	}
+	// Like the go command, cross builds disable cgo and the host's
+	// architecture feature tags
+	if ctx.GOOS != runtime.GOOS || ctx.GOARCH != runtime.GOARCH {
+		ctx.CgoEnabled = false
+		ctx.ToolTags = nil
+	}
+	ctx.BuildTags = tags
+
+	eval := &BuildEvaluation{GOOS: ctx.GOOS, GOARCH: ctx.GOARCH, Tags: tags, Included: []string{}, Excluded: []string{}}
+	if eval.Tags == nil {
+		eval.Tags = []string{}
+	}
+	_ = walkGoFiles(dir, func(path, rel string) {
+		if ok, err := ctx.MatchFile(filepath.Dir(path), filepath.Base(path)); err == nil && ok {
+			eval.Included = append(eval.Included, rel)
+		} else {
+			eval.Excluded = append(eval.Excluded, rel)
+		}
+	})
+	return eval
+}
+
+// parseBuildTagsQuery reads the goos, goarch and tags parameters of a build
+// tags resource URI. It reports false if none is set.
+func parseBuildTagsQuery(uri string) (goos, goarch string, tags []string, ok bool) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", nil, false
+	}
+	query := u.Query()
+	for _, value := range query["tags"] {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	goos, goarch = query.Get("goos"), query.Get("goarch")
+	return goos, goarch, tags, goos != "" || goarch != "" || query.Has("tags")
+}
+
+// walkGoFiles calls fn for every .go file under dir, skipping the
+// directories the go command ignores
+func walkGoFiles(dir string, fn func(path, rel string)) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				rel = path
+			}
+			fn(path, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+}
+
+// parseBuildConstraint parses the build constraint in the header of a Go
+// file, before the package clause. A //go:build line takes precedence over
+// // +build lines, which are combined with && as the go command does.
+func parseBuildConstraint(content []byte) (constraint.Expr, bool, error) {
+	var goBuild constraint.Expr
+	var plusBuild []constraint.Expr
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	inBlock := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inBlock {
+			if end := strings.Index(line, "*/"); end >= 0 {
+				inBlock = false
+				line = strings.TrimSpace(line[end+2:])
+			} else {
+				continue
+			}
+		}
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "/*"):
+			inBlock = !strings.Contains(line[2:], "*/")
+			continue
+		case !strings.HasPrefix(line, "//"):
+			// The header ends at the first line of code
+			return combineConstraints(goBuild, plusBuild)
+		case constraint.IsGoBuild(line):
+			if goBuild != nil {
+				return nil, false, errMultipleGoBuild
+			}
+			expr, err := constraint.Parse(line)
+			if err != nil {
+				return nil, false, err
+			}
+			goBuild = expr
+		case constraint.IsPlusBuild(line):
+			expr, err := constraint.Parse(line)
+			if err != nil {
+				return nil, false, err
+			}
+			plusBuild = append(plusBuild, expr)
+		}
+	}
+	return combineConstraints(goBuild, plusBuild)
+}
+
+var errMultipleGoBuild = errors.New("multiple //go:build comments")
+
+func combineConstraints(goBuild constraint.Expr, plusBuild []constraint.Expr) (constraint.Expr, bool, error) {
+	if goBuild != nil {
+		return goBuild, false, nil
+	}
+	if len(plusBuild) == 0 {
+		return nil, false, nil
+	}
+	expr := plusBuild[0]
+	for _, next := range plusBuild[1:] {
+		expr = &constraint.AndExpr{X: expr, Y: next}
+	}
+	return expr, true, nil
+}
+
+// constraintTags returns the sorted tags referenced by expr
+func constraintTags(expr constraint.Expr) []string {
+	seen := make(map[string]bool)
+	var walk func(constraint.Expr)
+	walk = func(e constraint.Expr) {
+		switch e := e.(type) {
+		case *constraint.TagExpr:
+			seen[e.Tag] = true
+		case *constraint.NotExpr:
+			walk(e.X)
+		case *constraint.AndExpr:
+			walk(e.X)
+			walk(e.Y)
+		case *constraint.OrExpr:
+			walk(e.X)
+			walk(e.Y)
+		}
+	}
+	walk(expr)
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// fileNameConstraint returns the GOOS and GOARCH implied by a file name of
+// the form name_GOOS_GOARCH.go, name_GOOS.go or name_GOARCH.go (optionally
+// followed by _test)
+func fileNameConstraint(name string) (goos, goarch string) {
+	name = strings.TrimSuffix(name, ".go")
+	name = strings.TrimSuffix(name, "_test")
+	// The part before the first underscore is never a constraint, so that
+	// linux.go applies to every platform
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return "", ""
+	}
+	parts := strings.Split(name[i:], "_")
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2], parts[n-1]
+	}
+	if knownOS[parts[n-1]] {
+		return parts[n-1], ""
+	}
+	if knownArch[parts[n-1]] {
+		return "", parts[n-1]
+	}
+	return "", ""
+}
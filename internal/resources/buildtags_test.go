@@ -0,0 +1,152 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeGoFiles creates files under dir from a map of slash-separated paths
+// to contents
+func writeGoFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiscoverBuildTagsConstraints(t *testing.T) {
+	dir := t.TempDir()
+	writeGoFiles(t, dir, map[string]string{
+		"main.go":               "package main\n",
+		"linux.go":              "package main\n",
+		"net_unix.go":           "//go:build unix && !(js || wasip1)\n\npackage main\n",
+		"both.go":               "// Copyright notice\n\n//go:build integration\n// +build integration\n\npackage main\n",
+		"legacy.go":             "// +build linux,386 darwin,!cgo\n// +build !purego\n\npackage main\n",
+		"sys_windows_amd64.go":  "package main\n",
+		"asm_arm64_test.go":     "package main\n",
+		"late.go":               "package main\n\n//go:build ignored\n",
+		"bad.go":                "//go:build linux &&\n\npackage main\n",
+		"vendor/dep/dep.go":     "//go:build vendored\n\npackage dep\n",
+		"testdata/x/x.go":       "//go:build testdata\n\npackage x\n",
+		".hidden/h.go":          "//go:build hidden\n\npackage h\n",
+		"internal/pkg/pkg.go":   "/* block\n   comment */\n//go:build tools\n\npackage pkg\n",
+		"internal/pkg/other.go": "package pkg\n",
+	})
+
+	report := discoverBuildTags(dir)
+
+	if report.FileCount != 11 {
+		t.Errorf("file count = %d, want 11", report.FileCount)
+	}
+	wantTags := []string{"386", "cgo", "darwin", "integration", "js", "linux", "purego", "tools", "unix", "wasip1"}
+	if !reflect.DeepEqual(report.Tags, wantTags) {
+		t.Errorf("tags = %v, want %v", report.Tags, wantTags)
+	}
+
+	files := make(map[string]FileConstraint)
+	for _, fc := range report.Files {
+		files[fc.File] = fc
+	}
+	want := map[string]FileConstraint{
+		"net_unix.go":          {File: "net_unix.go", Expression: "unix && !(js || wasip1)", Tags: []string{"js", "unix", "wasip1"}},
+		"both.go":              {File: "both.go", Expression: "integration", Tags: []string{"integration"}},
+		"legacy.go":            {File: "legacy.go", Expression: "((linux && 386) || (darwin && !cgo)) && !purego", Legacy: true, Tags: []string{"386", "cgo", "darwin", "linux", "purego"}},
+		"sys_windows_amd64.go": {File: "sys_windows_amd64.go", GOOS: "windows", GOARCH: "amd64"},
+		"asm_arm64_test.go":    {File: "asm_arm64_test.go", GOARCH: "arm64"},
+		"internal/pkg/pkg.go":  {File: "internal/pkg/pkg.go", Expression: "tools", Tags: []string{"tools"}},
+	}
+	for name, fc := range want {
+		if !reflect.DeepEqual(files[name], fc) {
+			t.Errorf("%s = %+v, want %+v", name, files[name], fc)
+		}
+	}
+	if files["bad.go"].Error == "" {
+		t.Error("expected a parse error for bad.go")
+	}
+	// Files without constraints are not listed
+	for _, name := range []string{"main.go", "linux.go", "late.go", "internal/pkg/other.go"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("%s should not have a constraint", name)
+		}
+	}
+}
+
+func TestEvaluateBuildTags(t *testing.T) {
+	dir := t.TempDir()
+	writeGoFiles(t, dir, map[string]string{
+		"main.go":         "package main\n",
+		"os_linux.go":     "package main\n",
+		"os_windows.go":   "package main\n",
+		"unix.go":         "//go:build unix\n\npackage main\n",
+		"integration.go":  "//go:build integration && !windows\n\npackage main\n",
+		"vendor/v/v.go":   "package v\n",
+		"arm64_only.go":   "//go:build arm64\n\npackage main\n",
+		"_ignored/x.go":   "package x\n",
+		"cmd/tool/run.go": "package main\n",
+	})
+
+	tests := []struct {
+		name   string
+		goos   string
+		goarch string
+		tags   []string
+		want   []string
+	}{
+		{"linux", "linux", "amd64", nil, []string{"cmd/tool/run.go", "main.go", "os_linux.go", "unix.go"}},
+		{"windows with tag", "windows", "amd64", []string{"integration"}, []string{"cmd/tool/run.go", "main.go", "os_windows.go"}},
+		{"darwin arm64 with tag", "darwin", "arm64", []string{"integration"}, []string{"arm64_only.go", "cmd/tool/run.go", "integration.go", "main.go", "unix.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval := evaluateBuildTags(dir, tt.goos, tt.goarch, tt.tags)
+			if !reflect.DeepEqual(eval.Included, tt.want) {
+				t.Errorf("included = %v, want %v", eval.Included, tt.want)
+			}
+			if len(eval.Included)+len(eval.Excluded) != 7 {
+				t.Errorf("included %d and excluded %d files, want 7 in total", len(eval.Included), len(eval.Excluded))
+			}
+		})
+	}
+}
+
+func TestParseBuildTagsQuery(t *testing.T) {
+	goos, goarch, tags, ok := parseBuildTagsQuery("go://build-tags?goos=windows&tags=integration,e2e&goarch=arm64")
+	if !ok || goos != "windows" || goarch != "arm64" || !reflect.DeepEqual(tags, []string{"integration", "e2e"}) {
+		t.Errorf("got %q %q %v %v", goos, goarch, tags, ok)
+	}
+	if _, _, _, ok := parseBuildTagsQuery("go://build-tags"); ok {
+		t.Error("expected no evaluation without parameters")
+	}
+	if _, _, tags, ok := parseBuildTagsQuery("go://build-tags?tags="); !ok || len(tags) != 0 {
+		t.Errorf("empty tags: got %v %v", tags, ok)
+	}
+}
+
+func TestFileNameConstraint(t *testing.T) {
+	tests := []struct {
+		name, goos, goarch string
+	}{
+		{"linux.go", "", ""},
+		{"file_linux.go", "linux", ""},
+		{"file_amd64.go", "", "amd64"},
+		{"file_linux_arm64_test.go", "linux", "arm64"},
+		{"file_test.go", "", ""},
+		{"zsyscall_windows_386.go", "windows", "386"},
+		{"a_b_c.go", "", ""},
+	}
+	for _, tt := range tests {
+		goos, goarch := fileNameConstraint(tt.name)
+		if goos != tt.goos || goarch != tt.goarch {
+			t.Errorf("%s: got %q %q, want %q %q", tt.name, goos, goarch, tt.goos, tt.goarch)
+		}
+	}
+}
@@ -20,6 +20,12 @@ var (
 	promptsRegistry   = make(map[string]*PromptMetadata)
 	resourcesRegistry = make(map[string]*ResourceMetadata)
 	registryMu        sync.RWMutex
+
+	// Tools, prompts and resources skipped because the configuration
+	// disables them
+	disabledTools     = make(map[string]bool)
+	disabledPrompts   = make(map[string]bool)
+	disabledResources = make(map[string]bool)
 )
 
 // ToolMetadata represents metadata for a registered tool.
@@ -78,12 +84,116 @@ func RegisterResourceMetadata(uri, name, description, mimeType string) {
 	}
 }
 
+// IsToolRegistered reports whether a tool with the given name is registered.
+func IsToolRegistered(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := toolsRegistry[name]
+	return ok
+}
+
+// IsPromptRegistered reports whether a prompt with the given name is registered.
+func IsPromptRegistered(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := promptsRegistry[name]
+	return ok
+}
+
+// IsResourceRegistered reports whether a resource with the given URI is registered.
+func IsResourceRegistered(uri string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := resourcesRegistry[uri]
+	return ok
+}
+
+// IsToolDisabled reports whether a tool was not added because the
+// configuration disables it.
+func IsToolDisabled(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return disabledTools[name]
+}
+
+// IsPromptDisabled reports whether a prompt was not added because the
+// configuration disables it.
+func IsPromptDisabled(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return disabledPrompts[name]
+}
+
+// IsResourceDisabled reports whether a resource was not added because the
+// configuration disables it.
+func IsResourceDisabled(uri string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return disabledResources[uri]
+}
+
+// AddTool adds a tool to the server and the registry unless the
+// configuration disables it. It returns the number of tools added.
+func AddTool[In, Out any](server *mcp.Server, cfg *config.Config, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) int {
+	if !cfg.ToolEnabled(tool.Name) {
+		registryMu.Lock()
+		disabledTools[tool.Name] = true
+		registryMu.Unlock()
+		return 0
+	}
+	RegisterTool(tool.Name, tool.Description, nil)
+	mcp.AddTool(server, tool, handler)
+	return 1
+}
+
+// AddPrompt adds a prompt to the server and the registry unless the
+// configuration disables it. It returns the number of prompts added.
+func AddPrompt(server *mcp.Server, cfg *config.Config, prompt *mcp.Prompt, handler mcp.PromptHandler) int {
+	if !cfg.PromptEnabled(prompt.Name) {
+		registryMu.Lock()
+		disabledPrompts[prompt.Name] = true
+		registryMu.Unlock()
+		return 0
+	}
+	RegisterPrompt(prompt.Name, prompt.Description, prompt.Arguments)
+	server.AddPrompt(prompt, handler)
+	return 1
+}
+
+// AddResource adds a resource to the server and the registry unless the
+// configuration disables it. It returns the number of resources added.
+func AddResource(server *mcp.Server, cfg *config.Config, resource *mcp.Resource, handler mcp.ResourceHandler) int {
+	if !cfg.ResourceEnabled(resource.URI) {
+		registryMu.Lock()
+		disabledResources[resource.URI] = true
+		registryMu.Unlock()
+		return 0
+	}
+	RegisterResourceMetadata(resource.URI, resource.Name, resource.Description, resource.MIMEType)
+	server.AddResource(resource, handler)
+	return 1
+}
+
+// AddResourceTemplate adds a resource template to the server and the
+// registry unless the configuration disables its URI template. It returns
+// the number of templates added.
+func AddResourceTemplate(server *mcp.Server, cfg *config.Config, template *mcp.ResourceTemplate, handler mcp.ResourceHandler) int {
+	if !cfg.ResourceEnabled(template.URITemplate) {
+		registryMu.Lock()
+		disabledResources[template.URITemplate] = true
+		registryMu.Unlock()
+		return 0
+	}
+	RegisterResourceMetadata(template.URITemplate, template.Name, template.Description, template.MIMEType)
+	server.AddResourceTemplate(template, handler)
+	return 1
+}
+
 // RegisterGoResources registers Go project discovery resources
 func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 	count := 0
 	// go://modules resource
-	RegisterResourceMetadata("go://modules", "Go Modules", "List of Go modules and dependencies in the current workspace", "application/json")
-	server.AddResource(&mcp.Resource{
+	count += AddResource(server, cfg, &mcp.Resource{
 		URI:         "go://modules",
 		Name:        "Go Modules",
 		Description: "List of Go modules and dependencies in the current workspace",
@@ -118,38 +228,46 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil
 	})
-	count++
 
-	// go://build-tags resource
-	RegisterResourceMetadata("go://build-tags", "Build Tags", "Build tags and constraints found in the project", "application/json")
-	server.AddResource(&mcp.Resource{
-		URI:         "go://build-tags",
-		Name:        "Build Tags",
-		Description: "Build tags and constraints found in the project",
-		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		// Discover build tags by scanning Go files
-		tags := discoverBuildTags(cfg.WorkingDirectory)
+	// go://build-tags resource, and a template evaluating the constraints
+	// for a platform and tag set
+	buildTagsHandler := func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		// Discover build constraints by scanning Go files
+		report := discoverBuildTags(cfg.WorkingDirectory)
+		if goos, goarch, tags, ok := parseBuildTagsQuery(req.Params.URI); ok {
+			report.Evaluation = evaluateBuildTags(cfg.WorkingDirectory, goos, goarch, tags)
+		}
 
-		jsonData, err := json.MarshalIndent(tags, "", "  ")
+		jsonData, err := json.MarshalIndent(report, "", "  ")
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal build tags: %w", err)
 		}
 
 		return &mcp.ReadResourceResult{
 			Contents: []*mcp.ResourceContents{
-				{URI: "go://build-tags", MIMEType: "application/json", Text: string(jsonData)},
+				{URI: req.Params.URI, MIMEType: "application/json", Text: string(jsonData)},
 			},
 		}, nil
-	})
-	count++
+	}
+	count += AddResource(server, cfg, &mcp.Resource{
+		URI:         "go://build-tags",
+		Name:        "Build Tags",
+		Description: "Build constraints of the Go files in the project: //go:build expressions, legacy // +build lines and GOOS/GOARCH file name suffixes",
+		MIMEType:    "application/json",
+	}, buildTagsHandler)
+
+	count += AddResourceTemplate(server, cfg, &mcp.ResourceTemplate{
+		URITemplate: BuildTagsURITemplate,
+		Name:        "Build Tags Evaluation",
+		Description: "Build constraints of the project and the files included in a build for the given GOOS, GOARCH and comma-separated tags",
+		MIMEType:    "application/json",
+	}, buildTagsHandler)
 
 	// go://tests resource
-	RegisterResourceMetadata("go://tests", "Test Files", "List of test files and benchmarks in the project", "application/json")
-	server.AddResource(&mcp.Resource{
+	count += AddResource(server, cfg, &mcp.Resource{
 		URI:         "go://tests",
 		Name:        "Test Files",
-		Description: "List of test files and benchmarks in the project",
+		Description: "Test, benchmark, fuzz and example functions of the project by package, with subtests, positions and -run patterns",
 		MIMEType:    "application/json",
 	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 		tests := discoverTestFiles(cfg.WorkingDirectory)
@@ -165,17 +283,15 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil
 	})
-	count++
 
 	// go://workspace resource
-	RegisterResourceMetadata("go://workspace", "Go Workspace", "Go workspace structure and configuration", "application/json")
-	server.AddResource(&mcp.Resource{
+	count += AddResource(server, cfg, &mcp.Resource{
 		URI:         "go://workspace",
 		Name:        "Go Workspace",
-		Description: "Go workspace structure and configuration",
+		Description: "Go workspace modules from go.work or go.mod: use, replace and toolchain directives and a per-module view",
 		MIMEType:    "application/json",
 	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		workspace := discoverWorkspace(cfg.WorkingDirectory)
+		workspace := DiscoverWorkspace(cfg, cfg.WorkingDirectory)
 
 		jsonData, err := json.MarshalIndent(workspace, "", "  ")
 		if err != nil {
@@ -188,11 +304,33 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil
 	})
-	count++
+
+	// go://packages resource
+	count += AddResource(server, cfg, &mcp.Resource{
+		URI:         "go://packages",
+		Name:        "Packages",
+		Description: "Packages of the main module from go list: imports, test imports, embedded files, the import graph, import cycles and package rule violations",
+		MIMEType:    "application/json",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		graph, err := utils.ListPackages(ctx, cfg, utils.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list packages: %w", err)
+		}
+
+		jsonData, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal packages: %w", err)
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{
+				{URI: "go://packages", MIMEType: "application/json", Text: string(jsonData)},
+			},
+		}, nil
+	})
 
 	// go://pkg-docs/{path} resource
-	RegisterResourceMetadata("go://pkg-docs/{path}", "Package Documentation", "Fetch package documentation from go.dev. Supports versioned paths (e.g., go://pkg-docs/encoding/json@v1.0.0)", "application/json")
-	server.AddResource(&mcp.Resource{
+	count += AddResource(server, cfg, &mcp.Resource{
 		URI:         "go://pkg-docs/{path}",
 		Name:        "Package Documentation",
 		Description: "Fetch package documentation from go.dev. Supports versioned paths (e.g., go://pkg-docs/encoding/json@v1.0.0)",
@@ -225,7 +363,7 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 		cache := utils.NewCache(24*time.Hour, true, cachePath)
 
 		// Fetch package documentation
-		doc, err := utils.FetchPackageDocs(ctx, pkgPath, version, cache)
+		doc, err := utils.FetchPackageDocs(ctx, cfg, pkgPath, version, cache)
 		if err != nil {
 			return &mcp.ReadResourceResult{
 				Contents: []*mcp.ResourceContents{
@@ -246,10 +384,47 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil
 	})
-	count++
 
-	RegisterResourceMetadata("go://tools", "Available Tools", "List of all available tools with their names, descriptions, and parameter schemas", "application/json")
-	server.AddResource(&mcp.Resource{
+	// go://audit resource
+	count += AddResource(server, cfg, &mcp.Resource{
+		URI:         "go://audit",
+		Name:        "Audit Log",
+		Description: "Recent entries from the audit log of tool calls and executed commands",
+		MIMEType:    "application/json",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		logger := utils.GetAuditLogger()
+		if logger == nil {
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{
+					{URI: "go://audit", Text: "Audit logging is disabled. Set AUDIT_LOG_PATH to enable it."},
+				},
+			}, nil
+		}
+
+		entries, err := logger.Recent(100)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		result := map[string]interface{}{
+			"path":    logger.Path(),
+			"entries": entries,
+			"count":   len(entries),
+		}
+
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit entries: %w", err)
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{
+				{URI: "go://audit", MIMEType: "application/json", Text: string(jsonData)},
+			},
+		}, nil
+	})
+
+	count += AddResource(server, cfg, &mcp.Resource{
 		URI:         "go://tools",
 		Name:        "Available Tools",
 		Description: "List of all available tools with their names, descriptions, and parameter schemas",
@@ -279,10 +454,8 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil
 	})
-	count++
 
-	RegisterResourceMetadata("go://prompts", "Available Prompts", "List of all available prompts with their names, descriptions, and arguments", "application/json")
-	server.AddResource(&mcp.Resource{
+	count += AddResource(server, cfg, &mcp.Resource{
 		URI:         "go://prompts",
 		Name:        "Available Prompts",
 		Description: "List of all available prompts with their names, descriptions, and arguments",
@@ -312,10 +485,8 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil
 	})
-	count++
 
-	RegisterResourceMetadata("go://resources", "Available Resources", "List of all available resources with their URIs, names, and descriptions", "application/json")
-	server.AddResource(&mcp.Resource{
+	count += AddResource(server, cfg, &mcp.Resource{
 		URI:         "go://resources",
 		Name:        "Available Resources",
 		Description: "List of all available resources with their URIs, names, and descriptions",
@@ -345,171 +516,5 @@ func RegisterGoResources(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil
 	})
-	count++
 	return count
 }
-
-// parseGoMod parses go.mod file content
-func parseGoMod(content string) map[string]interface{} {
-	info := map[string]interface{}{
-		"module":  "",
-		"go":      "",
-		"require": []string{},
-	}
-
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "module ") {
-			parts := strings.Fields(line)
-			if len(parts) > 1 {
-				info["module"] = parts[1]
-			}
-		} else if strings.HasPrefix(line, "go ") {
-			parts := strings.Fields(line)
-			if len(parts) > 1 {
-				info["go"] = parts[1]
-			}
-		} else if strings.HasPrefix(line, "require ") {
-			parts := strings.Fields(line)
-			if len(parts) > 1 {
-				requires := info["require"].([]string)
-				info["require"] = append(requires, strings.Join(parts[1:], " "))
-			}
-		}
-	}
-
-	return info
-}
-
-// discoverBuildTags discovers build tags in Go files
-func discoverBuildTags(dir string) map[string]interface{} {
-	tags := make(map[string]bool)
-	files := []string{}
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
-			files = append(files, path)
-			content, err := os.ReadFile(path)
-			if err == nil {
-				// Simple tag extraction (look for +build comments)
-				lines := strings.Split(string(content), "\n")
-				for _, line := range lines {
-					if strings.HasPrefix(strings.TrimSpace(line), "// +build ") {
-						tagLine := strings.TrimPrefix(strings.TrimSpace(line), "// +build ")
-						for _, tag := range strings.Fields(tagLine) {
-							tags[tag] = true
-						}
-					}
-				}
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return map[string]interface{}{
-			"error": err.Error(),
-		}
-	}
-
-	tagList := make([]string, 0, len(tags))
-	for tag := range tags {
-		tagList = append(tagList, tag)
-	}
-
-	return map[string]interface{}{
-		"tags":  tagList,
-		"files": files,
-	}
-}
-
-// discoverTestFiles discovers test files
-func discoverTestFiles(dir string) map[string]interface{} {
-	testFiles := []string{}
-	benchmarkFiles := []string{}
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() && strings.HasSuffix(path, "_test.go") {
-			content, err := os.ReadFile(path)
-			if err == nil {
-				contentStr := string(content)
-				testFiles = append(testFiles, path)
-				if strings.Contains(contentStr, "func Benchmark") {
-					benchmarkFiles = append(benchmarkFiles, path)
-				}
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return map[string]interface{}{
-			"error": err.Error(),
-		}
-	}
-
-	return map[string]interface{}{
-		"test_files":      testFiles,
-		"benchmark_files": benchmarkFiles,
-	}
-}
-
-// discoverWorkspace discovers workspace structure
-func discoverWorkspace(dir string) map[string]interface{} {
-	workspace := map[string]interface{}{
-		"working_directory": dir,
-		"has_go_mod":        false,
-		"has_go_work":       false,
-		"modules":           []string{},
-	}
-
-	// Check for go.mod
-	modPath := filepath.Join(dir, "go.mod")
-	if _, err := os.Stat(modPath); err == nil {
-		workspace["has_go_mod"] = true
-		modData, err := os.ReadFile(modPath)
-		if err == nil {
-			modInfo := parseGoMod(string(modData))
-			if module, ok := modInfo["module"].(string); ok {
-				workspace["modules"] = []string{module}
-			}
-		}
-	}
-
-	// Check for go.work
-	workPath := filepath.Join(dir, "go.work")
-	if _, err := os.Stat(workPath); err == nil {
-		workspace["has_go_work"] = true
-		// Parse go.work file
-		workData, err := os.ReadFile(workPath)
-		if err == nil {
-			modules := parseGoWork(string(workData))
-			workspace["modules"] = modules
-		}
-	}
-
-	return workspace
-}
-
-// parseGoWork parses go.work file
-func parseGoWork(content string) []string {
-	modules := []string{}
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "use ") {
-			parts := strings.Fields(line)
-			if len(parts) > 1 {
-				modules = append(modules, parts[1])
-			}
-		}
-	}
-	return modules
-}
@@ -3,6 +3,7 @@ package resources
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -32,7 +33,7 @@ func TestParseGoMod(t *testing.T) {
 	tests := []struct {
 		name    string
 		content string
-		want    map[string]interface{}
+		want    *ModFile
 	}{
 		{
 			name: "simple go.mod",
@@ -43,10 +44,10 @@ go 1.24
 require (
 	github.com/example/dep v1.0.0
 )`,
-			want: map[string]interface{}{
-				"module":  "example.com/test",
-				"go":      "1.24",
-				"require": []string{"github.com/example/dep v1.0.0"},
+			want: &ModFile{
+				Module:  "example.com/test",
+				Go:      "1.24",
+				Require: []ModRequire{{Path: "github.com/example/dep", Version: "v1.0.0", Line: 6}},
 			},
 		},
 		{
@@ -57,10 +58,10 @@ go 1.24
 
 require dep1 v1.0.0
 require dep2 v2.0.0`,
-			want: map[string]interface{}{
-				"module":  "test",
-				"go":      "1.24",
-				"require": []string{"dep1 v1.0.0", "dep2 v2.0.0"},
+			want: &ModFile{
+				Module:  "test",
+				Go:      "1.24",
+				Require: []ModRequire{{Path: "dep1", Version: "v1.0.0", Line: 5}, {Path: "dep2", Version: "v2.0.0", Line: 6}},
 			},
 		},
 	}
@@ -68,11 +69,8 @@ require dep2 v2.0.0`,
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := parseGoMod(tt.content)
-			if result["module"] != tt.want["module"] {
-				t.Errorf("module: got %q, want %q", result["module"], tt.want["module"])
-			}
-			if result["go"] != tt.want["go"] {
-				t.Errorf("go: got %q, want %q", result["go"], tt.want["go"])
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("got %+v, want %+v", result, tt.want)
 			}
 		})
 	}
@@ -99,12 +97,8 @@ func main() {
 
 	result := discoverBuildTags(dir)
 
-	if tags, ok := result["tags"].([]string); ok {
-		if len(tags) == 0 {
-			t.Error("Expected to find build tags")
-		}
-	} else {
-		t.Error("Expected tags field in result")
+	if want := []string{"darwin", "linux"}; !reflect.DeepEqual(result.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, result.Tags)
 	}
 }
 
@@ -141,24 +135,16 @@ func BenchmarkSomething(b *testing.B) {
 
 	result := discoverTestFiles(dir)
 
-	if testFiles, ok := result["test_files"].([]string); ok {
-		if len(testFiles) < 2 {
-			t.Errorf("Expected at least 2 test files, got %d", len(testFiles))
-		}
-	} else {
-		t.Error("Expected test_files field in result")
+	if len(result.TestFiles) < 2 {
+		t.Errorf("Expected at least 2 test files, got %d", len(result.TestFiles))
 	}
-
-	if benchmarkFiles, ok := result["benchmark_files"].([]string); ok {
-		if len(benchmarkFiles) < 1 {
-			t.Error("Expected at least 1 benchmark file")
-		}
-	} else {
-		t.Error("Expected benchmark_files field in result")
+	if len(result.BenchmarkFiles) < 1 {
+		t.Error("Expected at least 1 benchmark file")
 	}
 }
 
 func TestDiscoverWorkspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
 	dir := t.TempDir()
 
 	// Create go.mod
@@ -168,46 +154,50 @@ func TestDiscoverWorkspace(t *testing.T) {
 		t.Fatalf("Failed to create go.mod: %v", err)
 	}
 
-	result := discoverWorkspace(dir)
+	result := DiscoverWorkspace(&config.Config{WorkingDirectory: dir}, dir)
 
-	if hasGoMod, ok := result["has_go_mod"].(bool); ok {
-		if !hasGoMod {
-			t.Error("Expected has_go_mod to be true")
-		}
-	} else {
-		t.Error("Expected has_go_mod field in result")
+	if !result.HasGoMod || result.HasGoWork {
+		t.Errorf("has_go_mod = %v, has_go_work = %v", result.HasGoMod, result.HasGoWork)
+	}
+	if len(result.Modules) != 1 || result.Modules[0].Path != "test" || result.Modules[0].Go != "1.24" {
+		t.Errorf("modules = %+v", result.Modules)
 	}
 }
 
 func TestParseGoWork(t *testing.T) {
 	content := `go 1.24
 
+toolchain go1.24.2
+
 use (
 	./module1
-	./module2
+	./module2 // tools
 )
-`
+use ./module3
 
-	// parseGoWork is not exported, so we test it indirectly through discoverWorkspace
-	dir := t.TempDir()
+replace example.com/dep v1.0.0 => ../dep
+`
 
-	// Create go.work file (but no go.mod, so it only uses go.work)
-	workFile := filepath.Join(dir, "go.work")
-	if err := os.WriteFile(workFile, []byte(content), 0644); err != nil {
-		t.Fatalf("Failed to create go.work: %v", err)
+	work := parseGoWork(content)
+	if work.Go != "1.24" || work.Toolchain != "go1.24.2" {
+		t.Errorf("go = %q, toolchain = %q", work.Go, work.Toolchain)
 	}
-
-	workspace := discoverWorkspace(dir)
-	if hasGoWork, ok := workspace["has_go_work"].(bool); ok {
-		if !hasGoWork {
-			t.Error("Expected has_go_work to be true")
-		}
-	} else {
-		t.Error("Expected has_go_work field in workspace result")
+	wantUse := []WorkUse{{Path: "./module1", Line: 6}, {Path: "./module2", Line: 7}, {Path: "./module3", Line: 9}}
+	if !reflect.DeepEqual(work.Use, wantUse) {
+		t.Errorf("use = %+v, want %+v", work.Use, wantUse)
+	}
+	wantReplace := []ModReplace{{Old: ModVersion{Path: "example.com/dep", Version: "v1.0.0"}, New: ModVersion{Path: "../dep"}, Local: true, Line: 11}}
+	if !reflect.DeepEqual(work.Replace, wantReplace) {
+		t.Errorf("replace = %+v, want %+v", work.Replace, wantReplace)
+	}
+	if len(work.Errors) != 0 {
+		t.Errorf("unexpected errors: %+v", work.Errors)
 	}
 
-	// The modules parsing might have issues, so we just verify go.work is detected
-	// The actual parsing logic is tested indirectly
+	bad := parseGoWork("go 1.24\nuse\nrequire example.com/x v1.0.0\n")
+	if len(bad.Errors) != 2 || bad.Errors[0].Line != 2 || bad.Errors[1].Message != "unknown directive: require" {
+		t.Errorf("errors = %+v", bad.Errors)
+	}
 }
 
 func TestGoModulesResource(t *testing.T) {
@@ -237,8 +227,8 @@ func TestGoModulesResource(t *testing.T) {
 	// We can't easily call the resource handler directly, but we can test the parsing
 	// by calling parseGoMod directly
 	result := parseGoMod(modContent)
-	if result["module"] != "test" {
-		t.Errorf("Expected module 'test', got %q", result["module"])
+	if result.Module != "test" {
+		t.Errorf("Expected module 'test', got %q", result.Module)
 	}
 }
 
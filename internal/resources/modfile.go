@@ -0,0 +1,382 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ModFile is the parsed content of a go.mod file.
+type ModFile struct {
+	Module     string         `json:"module"`
+	Deprecated string         `json:"deprecated,omitempty"` // deprecation message of the module
+	Go         string         `json:"go"`
+	Toolchain  string         `json:"toolchain,omitempty"`
+	Godebug    []ModGodebug   `json:"godebug,omitempty"`
+	Require    []ModRequire   `json:"require"`
+	Exclude    []ModVersion   `json:"exclude,omitempty"`
+	Replace    []ModReplace   `json:"replace,omitempty"`
+	Retract    []ModRetract   `json:"retract,omitempty"`
+	Tool       []string       `json:"tool,omitempty"`
+	Ignore     []string       `json:"ignore,omitempty"`
+	Errors     []ModFileError `json:"errors,omitempty"` // directives that could not be parsed
+}
+
+// ModVersion is a module path with an optional version.
+type ModVersion struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+}
+
+// ModRequire is a requirement of the module.
+type ModRequire struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect,omitempty"` // marked with "// indirect"
+	Line     int    `json:"line"`
+}
+
+// ModReplace replaces a module, or one version of it, with another module or
+// a local directory.
+type ModReplace struct {
+	Old   ModVersion `json:"old"`
+	New   ModVersion `json:"new"`
+	Local bool       `json:"local,omitempty"` // New is a directory
+	Line  int        `json:"line"`
+}
+
+// ModRetract retracts a version or an inclusive range of versions.
+type ModRetract struct {
+	Low       string `json:"low"`
+	High      string `json:"high"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// ModGodebug is a default GODEBUG setting.
+type ModGodebug struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ModFileError is a problem found on a line of a go.mod file.
+type ModFileError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// modLine is a line of tokens with the comments attached to it
+type modLine struct {
+	tokens   []string
+	line     int
+	comments []string // comment lines directly above the line
+	suffix   string   // comment at the end of the line
+}
+
+// lexGoMod splits go.mod content into lines of tokens. Tokens are words,
+// quoted strings (returned unquoted) and the punctuation ( ) [ ] , and =>.
+// Comments are attached to the line they end or, for comment lines, to the
+// next line unless a blank line follows them.
+func lexGoMod(content string) ([]modLine, []ModFileError) {
+	var lines []modLine
+	var errs []ModFileError
+	var pending []string
+	current := modLine{line: 1}
+	lineNo := 1
+
+	endLine := func() {
+		if len(current.tokens) > 0 {
+			current.comments = pending
+			pending = nil
+			lines = append(lines, current)
+		}
+		current = modLine{line: lineNo + 1}
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			if len(current.tokens) == 0 && current.suffix == "" && i > 0 && isBlankLine(content, i) {
+				// A blank line detaches comments from the next directive
+				pending = nil
+			}
+			endLine()
+			lineNo++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			text := strings.TrimSpace(content[i+2 : i+end])
+			if len(current.tokens) > 0 {
+				current.suffix = text
+			} else {
+				pending = append(pending, text)
+			}
+			i += end
+		case c == '(' || c == ')' || c == '[' || c == ']' || c == ',':
+			current.tokens = append(current.tokens, string(c))
+			i++
+		case strings.HasPrefix(content[i:], "=>"):
+			current.tokens = append(current.tokens, "=>")
+			i += 2
+		case c == '"' || c == '`':
+			end := closingQuote(content, i)
+			if end < 0 {
+				errs = append(errs, ModFileError{Line: lineNo, Message: "unterminated string"})
+				i = len(content)
+				continue
+			}
+			value, err := strconv.Unquote(content[i : end+1])
+			if err != nil {
+				errs = append(errs, ModFileError{Line: lineNo, Message: fmt.Sprintf("invalid quoted string %s", content[i:end+1])})
+			}
+			current.tokens = append(current.tokens, value)
+			i = end + 1
+		default:
+			start := i
+			for i < len(content) && !strings.ContainsRune(" \t\r\n()[],\"`", rune(content[i])) && !strings.HasPrefix(content[i:], "//") && !strings.HasPrefix(content[i:], "=>") {
+				i++
+			}
+			current.tokens = append(current.tokens, content[start:i])
+		}
+	}
+	endLine()
+	return lines, errs
+}
+
+// isBlankLine reports whether the line ending at the newline at end is empty
+func isBlankLine(content string, end int) bool {
+	start := strings.LastIndexByte(content[:end], '\n') + 1
+	return strings.TrimSpace(content[start:end]) == ""
+}
+
+// closingQuote returns the index of the quote ending the string starting at
+// start, or -1 if it is not closed on the same line
+func closingQuote(content string, start int) int {
+	quote := content[start]
+	for i := start + 1; i < len(content); i++ {
+		switch {
+		case content[i] == '\n':
+			if quote == '"' {
+				return -1
+			}
+		case content[i] == '\\' && quote == '"':
+			i++
+		case content[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseGoMod parses go.mod content, including directive blocks such as
+// require ( ... ), and comments marking indirect requirements, deprecation
+// and retraction rationale. Problems are recorded in Errors and the rest of
+// the file is still parsed.
+func parseGoMod(content string) *ModFile {
+	mod := &ModFile{Require: []ModRequire{}}
+	lines, errs := lexGoMod(content)
+	mod.Errors = errs
+	forEachDirective(lines, mod.addDirective, mod.addError)
+	return mod
+}
+
+// forEachDirective calls add for every directive in lines, expanding blocks
+// of directives with the same verb: verb ( ... )
+func forEachDirective(lines []modLine, add func(verb string, args []string, line modLine), addError func(line int, message string)) {
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		verb := line.tokens[0]
+		args := line.tokens[1:]
+
+		if len(args) > 0 && args[0] == "(" {
+			if len(args) == 2 && args[1] == ")" {
+				continue
+			}
+			if len(args) > 1 {
+				addError(line.line, "unexpected tokens after (")
+			}
+			closed := false
+			for i+1 < len(lines) {
+				i++
+				inner := lines[i]
+				if inner.tokens[0] == ")" {
+					closed = true
+					break
+				}
+				// Comments above the block apply to entries without their own
+				if len(inner.comments) == 0 {
+					inner.comments = line.comments
+				}
+				add(verb, inner.tokens, inner)
+			}
+			if !closed {
+				addError(line.line, fmt.Sprintf("unterminated %s block", verb))
+			}
+			continue
+		}
+		if verb == ")" {
+			addError(line.line, "unexpected )")
+			continue
+		}
+		add(verb, args, line)
+	}
+}
+
+func (mod *ModFile) addError(line int, message string) {
+	mod.Errors = append(mod.Errors, ModFileError{Line: line, Message: message})
+}
+
+// addDirective adds a single directive with the given verb and arguments
+func (mod *ModFile) addDirective(verb string, args []string, line modLine) {
+	switch verb {
+	case "module":
+		if len(args) != 1 {
+			mod.addError(line.line, "usage: module module/path")
+			return
+		}
+		mod.Module = args[0]
+		for _, comment := range append(line.comments, line.suffix) {
+			if msg, ok := strings.CutPrefix(comment, "Deprecated:"); ok {
+				mod.Deprecated = strings.TrimSpace(msg)
+			}
+		}
+	case "go":
+		if len(args) != 1 {
+			mod.addError(line.line, "usage: go 1.23")
+			return
+		}
+		mod.Go = args[0]
+	case "toolchain":
+		if len(args) != 1 {
+			mod.addError(line.line, "usage: toolchain go1.23.1")
+			return
+		}
+		mod.Toolchain = args[0]
+	case "godebug":
+		godebug, ok := parseGodebug(args)
+		if !ok {
+			mod.addError(line.line, "usage: godebug key=value")
+			return
+		}
+		mod.Godebug = append(mod.Godebug, godebug)
+	case "require":
+		if len(args) != 2 {
+			mod.addError(line.line, "usage: require module/path v1.2.3")
+			return
+		}
+		mod.Require = append(mod.Require, ModRequire{
+			Path:     args[0],
+			Version:  args[1],
+			Indirect: isIndirectComment(line.suffix),
+			Line:     line.line,
+		})
+	case "exclude":
+		if len(args) != 2 {
+			mod.addError(line.line, "usage: exclude module/path v1.2.3")
+			return
+		}
+		mod.Exclude = append(mod.Exclude, ModVersion{Path: args[0], Version: args[1]})
+	case "replace":
+		replace, problem := parseReplace(args, line)
+		if problem != "" {
+			mod.addError(line.line, problem)
+			return
+		}
+		mod.Replace = append(mod.Replace, replace)
+	case "retract":
+		mod.addRetract(args, line)
+	case "tool":
+		if len(args) != 1 {
+			mod.addError(line.line, "usage: tool package/path")
+			return
+		}
+		mod.Tool = append(mod.Tool, args[0])
+	case "ignore":
+		if len(args) != 1 {
+			mod.addError(line.line, "usage: ignore path")
+			return
+		}
+		mod.Ignore = append(mod.Ignore, args[0])
+	default:
+		mod.addError(line.line, fmt.Sprintf("unknown directive: %s", verb))
+	}
+}
+
+// parseReplace parses "old [version] => new [version]". It returns a
+// message describing the problem if the directive is invalid.
+func parseReplace(args []string, line modLine) (ModReplace, string) {
+	arrow := -1
+	for i, arg := range args {
+		if arg == "=>" {
+			arrow = i
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+		return ModReplace{}, "usage: replace module/path [v1.2.3] => other/module v1.4 or replace module/path [v1.2.3] => ../local/directory"
+	}
+	replace := ModReplace{Old: ModVersion{Path: args[0]}, New: ModVersion{Path: args[arrow+1]}, Line: line.line}
+	if arrow == 2 {
+		replace.Old.Version = args[1]
+	}
+	if len(args) == arrow+3 {
+		replace.New.Version = args[arrow+2]
+	}
+	replace.Local = isLocalPath(replace.New.Path)
+	if replace.Local && replace.New.Version != "" {
+		return ModReplace{}, "replacement directory cannot have a version"
+	}
+	if !replace.Local && replace.New.Version == "" {
+		return ModReplace{}, "replacement module requires a version"
+	}
+	return replace, ""
+}
+
+// parseGodebug parses "key=value"
+func parseGodebug(args []string) (ModGodebug, bool) {
+	if len(args) != 1 {
+		return ModGodebug{}, false
+	}
+	key, value, ok := strings.Cut(args[0], "=")
+	return ModGodebug{Key: key, Value: value}, ok && key != ""
+}
+
+// addRetract parses "version" or "[low, high]"
+func (mod *ModFile) addRetract(args []string, line modLine) {
+	retract := ModRetract{}
+	switch {
+	case len(args) == 1 && args[0] != "[":
+		retract.Low, retract.High = args[0], args[0]
+	case len(args) == 5 && args[0] == "[" && args[2] == "," && args[4] == "]":
+		retract.Low, retract.High = args[1], args[3]
+	default:
+		mod.addError(line.line, "usage: retract v1.2.3 or retract [v1.2.3, v1.2.5]")
+		return
+	}
+	// The rationale is the comment above the directive or at its end
+	rationale := line.comments
+	if line.suffix != "" {
+		rationale = []string{line.suffix}
+	}
+	retract.Rationale = strings.TrimSpace(strings.Join(rationale, "\n"))
+	mod.Retract = append(mod.Retract, retract)
+}
+
+// isIndirectComment reports whether a line comment marks an indirect
+// requirement, e.g. "indirect" or "indirect; needed by x"
+func isIndirectComment(comment string) bool {
+	return comment == "indirect" || strings.HasPrefix(comment, "indirect;")
+}
+
+// isLocalPath reports whether a replacement is a directory rather than a
+// module path
+func isLocalPath(path string) bool {
+	return path == "." || path == ".." ||
+		strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") ||
+		strings.HasPrefix(path, "/") || strings.HasPrefix(path, `.\`) || strings.HasPrefix(path, `..\`) ||
+		(len(path) > 2 && path[1] == ':' && (path[2] == '\\' || path[2] == '/'))
+}
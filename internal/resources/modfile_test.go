@@ -0,0 +1,208 @@
+package resources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// kubernetesGoMod is an excerpt of a large real-world go.mod with several
+// require blocks, indirect markers, godebug and local replacements
+const kubernetesGoMod = `// This is a generated file. Do not edit directly.
+// Ensure you've carefully read
+// https://git.k8s.io/community/contributors/devel/sig-architecture/vendor.md
+// Run hack/pin-dependency.sh to change pinned dependency versions.
+// Run hack/update-vendor.sh to update go.mod files and the vendor directory.
+
+module k8s.io/kubernetes
+
+go 1.23.0
+
+godebug default=go1.23
+
+godebug (
+	winsymlink=0
+	x509negativeserial=1
+)
+
+require (
+	github.com/Microsoft/go-winio v0.6.2
+	github.com/blang/semver/v4 v4.0.0
+	github.com/google/go-cmp v0.6.0
+	k8s.io/api v0.0.0
+	k8s.io/client-go v0.0.0
+)
+
+require (
+	cel.dev/expr v0.18.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect; needed by prometheus
+)
+
+replace (
+	k8s.io/api => ./staging/src/k8s.io/api
+	k8s.io/client-go => ./staging/src/k8s.io/client-go
+)
+`
+
+// retractGoMod uses retract, exclude, toolchain, tool and module deprecation
+const retractGoMod = `// Deprecated: use example.com/lib/v2 instead.
+module example.com/lib
+
+go 1.24
+
+toolchain go1.24.2
+
+require golang.org/x/text v0.21.0
+
+exclude golang.org/x/text v0.3.0
+
+replace golang.org/x/net v1.2.3 => example.com/fork/net v1.4.5
+
+retract v1.0.5 // Published accidentally.
+
+// Contains a data race in the cache.
+retract [v1.1.0, v1.1.3]
+
+retract (
+	v0.9.0
+	[v0.1.0, v0.2.0] // Pre-release API.
+)
+
+tool golang.org/x/tools/cmd/stringer
+`
+
+func TestParseGoModRealWorld(t *testing.T) {
+	mod := parseGoMod(kubernetesGoMod)
+
+	if mod.Module != "k8s.io/kubernetes" || mod.Go != "1.23.0" {
+		t.Errorf("module %q go %q", mod.Module, mod.Go)
+	}
+	if len(mod.Errors) != 0 {
+		t.Errorf("unexpected errors: %+v", mod.Errors)
+	}
+	wantGodebug := []ModGodebug{{"default", "go1.23"}, {"winsymlink", "0"}, {"x509negativeserial", "1"}}
+	if !reflect.DeepEqual(mod.Godebug, wantGodebug) {
+		t.Errorf("godebug = %+v, want %+v", mod.Godebug, wantGodebug)
+	}
+	if len(mod.Require) != 8 {
+		t.Fatalf("got %d requirements, want 8", len(mod.Require))
+	}
+	indirect := 0
+	for _, req := range mod.Require {
+		if req.Indirect {
+			indirect++
+		}
+	}
+	if indirect != 3 {
+		t.Errorf("got %d indirect requirements, want 3", indirect)
+	}
+	if req := mod.Require[0]; req.Path != "github.com/Microsoft/go-winio" || req.Version != "v0.6.2" || req.Line != 19 {
+		t.Errorf("first requirement = %+v", req)
+	}
+	if len(mod.Replace) != 2 {
+		t.Fatalf("got %d replacements, want 2", len(mod.Replace))
+	}
+	want := ModReplace{
+		Old:   ModVersion{Path: "k8s.io/api"},
+		New:   ModVersion{Path: "./staging/src/k8s.io/api"},
+		Local: true,
+		Line:  33,
+	}
+	if mod.Replace[0] != want {
+		t.Errorf("replace = %+v, want %+v", mod.Replace[0], want)
+	}
+}
+
+func TestParseGoModRetract(t *testing.T) {
+	mod := parseGoMod(retractGoMod)
+
+	if len(mod.Errors) != 0 {
+		t.Errorf("unexpected errors: %+v", mod.Errors)
+	}
+	if mod.Deprecated != "use example.com/lib/v2 instead." {
+		t.Errorf("deprecated = %q", mod.Deprecated)
+	}
+	if mod.Toolchain != "go1.24.2" {
+		t.Errorf("toolchain = %q", mod.Toolchain)
+	}
+	if want := []ModVersion{{Path: "golang.org/x/text", Version: "v0.3.0"}}; !reflect.DeepEqual(mod.Exclude, want) {
+		t.Errorf("exclude = %+v", mod.Exclude)
+	}
+	wantReplace := ModReplace{
+		Old:  ModVersion{Path: "golang.org/x/net", Version: "v1.2.3"},
+		New:  ModVersion{Path: "example.com/fork/net", Version: "v1.4.5"},
+		Line: 12,
+	}
+	if len(mod.Replace) != 1 || mod.Replace[0] != wantReplace {
+		t.Errorf("replace = %+v", mod.Replace)
+	}
+	wantRetract := []ModRetract{
+		{Low: "v1.0.5", High: "v1.0.5", Rationale: "Published accidentally."},
+		{Low: "v1.1.0", High: "v1.1.3", Rationale: "Contains a data race in the cache."},
+		{Low: "v0.9.0", High: "v0.9.0"},
+		{Low: "v0.1.0", High: "v0.2.0", Rationale: "Pre-release API."},
+	}
+	if !reflect.DeepEqual(mod.Retract, wantRetract) {
+		t.Errorf("retract = %+v, want %+v", mod.Retract, wantRetract)
+	}
+	if want := []string{"golang.org/x/tools/cmd/stringer"}; !reflect.DeepEqual(mod.Tool, want) {
+		t.Errorf("tool = %v", mod.Tool)
+	}
+}
+
+func TestParseGoModQuotedAndComments(t *testing.T) {
+	content := "module \"example.com/quoted\" // trailing\n" +
+		"go 1.22\n" +
+		"require (\n" +
+		"\t`example.com/raw` v1.0.0//indirect\n" +
+		"\texample.com/direct v1.1.0 // not indirect\n" +
+		")\n"
+	mod := parseGoMod(content)
+
+	if mod.Module != "example.com/quoted" {
+		t.Errorf("module = %q", mod.Module)
+	}
+	want := []ModRequire{
+		{Path: "example.com/raw", Version: "v1.0.0", Indirect: true, Line: 4},
+		{Path: "example.com/direct", Version: "v1.1.0", Line: 5},
+	}
+	if !reflect.DeepEqual(mod.Require, want) {
+		t.Errorf("require = %+v, want %+v", mod.Require, want)
+	}
+}
+
+func TestParseGoModErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown directive", "module m\nfrobnicate x\n", "unknown directive: frobnicate"},
+		{"unterminated block", "module m\nrequire (\n\ta v1.0.0\n", "unterminated require block"},
+		{"require without version", "module m\nrequire a\n", "usage: require"},
+		{"replace without arrow", "module m\nreplace a v1.0.0 b v1.0.0\n", "usage: replace"},
+		{"versioned directory", "module m\nreplace a => ../a v1.0.0\n", "replacement directory cannot have a version"},
+		{"unversioned module", "module m\nreplace a => example.com/b\n", "replacement module requires a version"},
+		{"bad retract range", "module m\nretract [v1.0.0 v1.2.0]\n", "usage: retract"},
+		{"unterminated string", "module \"m\n", "unterminated string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod := parseGoMod(tt.content)
+			if len(mod.Errors) == 0 {
+				t.Fatalf("expected an error containing %q", tt.want)
+			}
+			if !strings.Contains(mod.Errors[0].Message, tt.want) {
+				t.Errorf("error = %q, want it to contain %q", mod.Errors[0].Message, tt.want)
+			}
+		})
+	}
+
+	// The rest of the file is still parsed
+	mod := parseGoMod("module m\nfrobnicate x\nrequire a v1.0.0\n")
+	if mod.Module != "m" || len(mod.Require) != 1 || mod.Errors[0].Line != 2 {
+		t.Errorf("got %+v", mod)
+	}
+}
@@ -0,0 +1,448 @@
+package resources
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TestInventory lists the test functions of the packages in a tree.
+type TestInventory struct {
+	Packages       []*TestPackage  `json:"packages"`
+	TestFiles      []string        `json:"test_files"`
+	BenchmarkFiles []string        `json:"benchmark_files"`
+	Totals         TestTotals      `json:"totals"`
+	Errors         []TestFileError `json:"errors,omitempty"`
+}
+
+// TestTotals counts the test functions of every package.
+type TestTotals struct {
+	Tests      int `json:"tests"`
+	Benchmarks int `json:"benchmarks"`
+	Fuzz       int `json:"fuzz"`
+	Examples   int `json:"examples"`
+}
+
+// TestPackage holds the test functions of one directory, from both the
+// package and its external _test package.
+type TestPackage struct {
+	Dir         string     `json:"dir"`                   // slash-separated, relative to the scanned directory
+	ImportPath  string     `json:"import_path,omitempty"` // when the directory has a go.mod above it
+	Name        string     `json:"name"`
+	Files       []string   `json:"files"`
+	HasTestMain bool       `json:"has_test_main"`
+	Tests       []TestFunc `json:"tests,omitempty"`
+	Benchmarks  []TestFunc `json:"benchmarks,omitempty"`
+	Fuzz        []TestFunc `json:"fuzz,omitempty"`
+	Examples    []TestFunc `json:"examples,omitempty"`
+}
+
+// TestFunc is a test, benchmark, fuzz or example function.
+type TestFunc struct {
+	Name      string    `json:"name"`
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	Run       string    `json:"run"`                        // -run (or -bench, -fuzz) pattern matching only this function
+	Subtests  []Subtest `json:"subtests,omitempty"`         // subtests found in t.Run calls
+	Dynamic   bool      `json:"dynamic_subtests,omitempty"` // some t.Run names are computed at run time
+	HasOutput bool      `json:"has_output,omitempty"`       // example with an Output comment, run by go test
+}
+
+// Subtest is a subtest found in a t.Run or b.Run call.
+type Subtest struct {
+	Name string `json:"name"` // full name, e.g. TestAdd/positive_numbers
+	Run  string `json:"run"`  // -run pattern matching only this subtest, e.g. ^TestAdd$/^positive_numbers$
+}
+
+// TestFileError is a test file that could not be parsed.
+type TestFileError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// discoverTestFiles parses the _test.go files under dir and lists their
+// test, benchmark, fuzz and example functions by package
+func discoverTestFiles(dir string) *TestInventory {
+	inventory := &TestInventory{Packages: []*TestPackage{}, TestFiles: []string{}, BenchmarkFiles: []string{}}
+	packages := make(map[string]*TestPackage)
+	modules := make(map[string]string)
+
+	_ = walkGoFiles(dir, func(filePath, rel string) {
+		if !strings.HasSuffix(filePath, "_test.go") {
+			return
+		}
+		inventory.TestFiles = append(inventory.TestFiles, rel)
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			inventory.Errors = append(inventory.Errors, TestFileError{File: rel, Error: err.Error()})
+			return
+		}
+
+		pkgDir := path.Dir(rel)
+		pkg := packages[pkgDir]
+		if pkg == nil {
+			pkg = &TestPackage{Dir: pkgDir, ImportPath: importPathFor(dir, filepath.Dir(filePath), modules)}
+			packages[pkgDir] = pkg
+		}
+		if name := strings.TrimSuffix(file.Name.Name, "_test"); pkg.Name == "" || file.Name.Name == name {
+			pkg.Name = name
+		}
+		pkg.Files = append(pkg.Files, rel)
+
+		testingPkg := testingImportName(file)
+		hasBenchmark := false
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil {
+				continue
+			}
+			name := fn.Name.Name
+			tf := TestFunc{Name: name, File: rel, Line: fset.Position(fn.Pos()).Line, Run: runPattern(name)}
+			switch {
+			case name == "TestMain" && isTestingParam(fn, testingPkg, "M"):
+				pkg.HasTestMain = true
+			case isTestName(name, "Test") && isTestingParam(fn, testingPkg, "T"):
+				tf.Subtests, tf.Dynamic = findSubtests(name, fn.Body)
+				pkg.Tests = append(pkg.Tests, tf)
+			case isTestName(name, "Benchmark") && isTestingParam(fn, testingPkg, "B"):
+				tf.Subtests, tf.Dynamic = findSubtests(name, fn.Body)
+				pkg.Benchmarks = append(pkg.Benchmarks, tf)
+				hasBenchmark = true
+			case isTestName(name, "Fuzz") && isTestingParam(fn, testingPkg, "F"):
+				pkg.Fuzz = append(pkg.Fuzz, tf)
+			case isTestName(name, "Example") && fn.Type.Params.NumFields() == 0 && fn.Type.Results.NumFields() == 0:
+				tf.HasOutput = hasOutputComment(file, fn.Body)
+				pkg.Examples = append(pkg.Examples, tf)
+			}
+		}
+		if hasBenchmark {
+			inventory.BenchmarkFiles = append(inventory.BenchmarkFiles, rel)
+		}
+	})
+
+	for _, pkg := range packages {
+		inventory.Packages = append(inventory.Packages, pkg)
+		inventory.Totals.Tests += len(pkg.Tests)
+		inventory.Totals.Benchmarks += len(pkg.Benchmarks)
+		inventory.Totals.Fuzz += len(pkg.Fuzz)
+		inventory.Totals.Examples += len(pkg.Examples)
+	}
+	sort.Slice(inventory.Packages, func(i, j int) bool {
+		return inventory.Packages[i].Dir < inventory.Packages[j].Dir
+	})
+	return inventory
+}
+
+// isTestName reports whether name is prefix followed by nothing or by a
+// character that is not a lower-case letter, as go test requires
+func isTestName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// testingImportName returns the name the file imports the testing package
+// as: "testing" unless renamed, "." for a dot import, or "" if the file does
+// not import it.
+func testingImportName(file *ast.File) string {
+	for _, imp := range file.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != "testing" {
+			continue
+		}
+		if imp.Name == nil {
+			return "testing"
+		}
+		if imp.Name.Name != "_" {
+			return imp.Name.Name
+		}
+	}
+	return ""
+}
+
+// isTestingParam reports whether fn takes a single *testing.<typeName>
+// parameter and returns nothing, where testingPkg is the name the file imports
+// the testing package as
+func isTestingParam(fn *ast.FuncDecl, testingPkg, typeName string) bool {
+	params := fn.Type.Params.List
+	if testingPkg == "" || len(params) != 1 || len(params[0].Names) > 1 || fn.Type.Results.NumFields() != 0 {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	switch x := star.X.(type) {
+	case *ast.Ident:
+		return testingPkg == "." && x.Name == typeName
+	case *ast.SelectorExpr:
+		pkg, ok := x.X.(*ast.Ident)
+		return ok && pkg.Name == testingPkg && x.Sel.Name == typeName
+	}
+	return false
+}
+
+// hasOutputComment reports whether an example body has an Output comment
+func hasOutputComment(file *ast.File, body *ast.BlockStmt) bool {
+	for _, group := range file.Comments {
+		if group.Pos() < body.Lbrace || group.End() > body.Rbrace {
+			continue
+		}
+		text := strings.TrimSpace(group.Text())
+		if strings.HasPrefix(text, "Output:") || strings.HasPrefix(text, "Unordered output:") {
+			return true
+		}
+	}
+	return false
+}
+
+// subtestScope tracks the string values that t.Run names can be resolved
+// from: literal tables assigned to variables and the loop variables ranging
+// over them
+type subtestScope struct {
+	tables map[string]*ast.CompositeLit // variable name -> table literal
+	rows   map[string][]ast.Expr        // loop value variable -> table rows
+	keys   map[string][]string          // loop key variable -> map keys
+}
+
+// findSubtests returns the full names of the subtests started with t.Run
+// (or b.Run) in body. Names are taken from string literals and from fields
+// of table-driven test cases; dynamic reports names that could not be
+// resolved.
+func findSubtests(parent string, body *ast.BlockStmt) ([]Subtest, bool) {
+	scope := &subtestScope{
+		tables: make(map[string]*ast.CompositeLit),
+		rows:   make(map[string][]ast.Expr),
+		keys:   make(map[string][]string),
+	}
+	names, dynamic := scope.find(parent, body)
+	var subtests []Subtest
+	for _, name := range names {
+		subtests = append(subtests, Subtest{Name: name, Run: runPattern(name)})
+	}
+	return subtests, dynamic
+}
+
+// runPattern returns the -run pattern matching exactly the test or subtest
+// with the given full name. go test splits the pattern at slashes and matches
+// each level separately, so every level is anchored on its own.
+func runPattern(name string) string {
+	levels := strings.Split(name, "/")
+	for i, level := range levels {
+		levels[i] = "^" + regexp.QuoteMeta(level) + "$"
+	}
+	return strings.Join(levels, "/")
+}
+
+func (s *subtestScope) find(parent string, body ast.Node) (subtests []string, dynamic bool) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && i < len(n.Rhs) {
+					if lit, ok := n.Rhs[i].(*ast.CompositeLit); ok {
+						s.tables[ident.Name] = lit
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for i, ident := range n.Names {
+				if i < len(n.Values) {
+					if lit, ok := n.Values[i].(*ast.CompositeLit); ok {
+						s.tables[ident.Name] = lit
+					}
+				}
+			}
+		case *ast.RangeStmt:
+			s.addRange(n)
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Run" || len(n.Args) != 2 {
+				return true
+			}
+			lit, ok := n.Args[1].(*ast.FuncLit)
+			if !ok {
+				return true
+			}
+			names := s.resolve(n.Args[0])
+			if names == nil {
+				dynamic = true
+				names = []string{""}
+			}
+			for _, name := range names {
+				full := parent + "/" + rewriteSubtestName(name)
+				if name != "" {
+					subtests = append(subtests, full)
+				}
+				// Subtests of subtests are named after their parent
+				nested, nestedDynamic := s.find(full, lit.Body)
+				if name != "" {
+					subtests = append(subtests, nested...)
+				}
+				dynamic = dynamic || nestedDynamic
+			}
+			return false
+		}
+		return true
+	})
+	return subtests, dynamic
+}
+
+// addRange records the rows or keys a range loop variable takes when the
+// loop ranges over a table literal
+func (s *subtestScope) addRange(rs *ast.RangeStmt) {
+	var table *ast.CompositeLit
+	switch x := rs.X.(type) {
+	case *ast.Ident:
+		table = s.tables[x.Name]
+	case *ast.CompositeLit:
+		table = x
+	}
+	if table == nil {
+		return
+	}
+
+	var rows []ast.Expr
+	var keys []string
+	for _, elt := range table.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			// Map literal: the key is the loop key, the value the row
+			if key, ok := stringLiteral(kv.Key); ok {
+				keys = append(keys, key)
+			}
+			rows = append(rows, kv.Value)
+			continue
+		}
+		rows = append(rows, elt)
+	}
+	if ident, ok := rs.Key.(*ast.Ident); ok && len(keys) == len(table.Elts) {
+		s.keys[ident.Name] = keys
+	}
+	if ident, ok := rs.Value.(*ast.Ident); ok {
+		s.rows[ident.Name] = rows
+	}
+}
+
+// resolve returns the possible values of a t.Run name argument, or nil if
+// they cannot be determined statically
+func (s *subtestScope) resolve(expr ast.Expr) []string {
+	if name, ok := stringLiteral(expr); ok {
+		return []string{name}
+	}
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return s.keys[x.Name]
+	case *ast.SelectorExpr:
+		// tt.name with tt ranging over a table of struct literals
+		ident, ok := x.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		rows, ok := s.rows[ident.Name]
+		if !ok {
+			return nil
+		}
+		var names []string
+		for _, row := range rows {
+			value, ok := structField(row, x.Sel.Name)
+			if !ok {
+				return nil
+			}
+			names = append(names, value)
+		}
+		return names
+	}
+	return nil
+}
+
+// structField returns the string literal assigned to field in a keyed
+// struct literal (or a pointer to one)
+func structField(row ast.Expr, field string) (string, bool) {
+	if unary, ok := row.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		row = unary.X
+	}
+	lit, ok := row.(*ast.CompositeLit)
+	if !ok {
+		return "", false
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == field {
+			return stringLiteral(kv.Value)
+		}
+	}
+	return "", false
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// rewriteSubtestName rewrites a subtest name the way the testing package
+// does, so that it can be used in a -run pattern
+func rewriteSubtestName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteByte('_')
+		case !strconv.IsPrint(r):
+			s := strconv.QuoteRune(r)
+			b.WriteString(s[1 : len(s)-1])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// importPathFor returns the import path of pkgDir from the nearest go.mod
+// between it and root, caching module paths by directory
+func importPathFor(root, pkgDir string, modules map[string]string) string {
+	for dir := pkgDir; ; dir = filepath.Dir(dir) {
+		modulePath, ok := modules[dir]
+		if !ok {
+			modulePath = ""
+			if content, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+				modulePath = parseGoMod(string(content)).Module
+			}
+			modules[dir] = modulePath
+		}
+		if modulePath != "" {
+			rel, err := filepath.Rel(dir, pkgDir)
+			if err != nil {
+				return ""
+			}
+			if rel == "." {
+				return modulePath
+			}
+			return modulePath + "/" + filepath.ToSlash(rel)
+		}
+		if dir == root || filepath.Dir(dir) == dir || !strings.HasPrefix(dir, root) {
+			return ""
+		}
+	}
+}
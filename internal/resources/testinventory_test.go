@@ -0,0 +1,205 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+)
+
+const inventoryTestFile = `package calc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b int
+	}{
+		{name: "positive numbers", a: 1, b: 2},
+		{name: "zero", a: 0, b: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("nested", func(t *testing.T) {})
+		})
+	}
+}
+
+func TestDivide(t *testing.T) {
+	cases := map[string]int{"by one": 1, "by two": 2}
+	for name, divisor := range cases {
+		t.Run(name, func(t *testing.T) { _ = divisor })
+	}
+	t.Run("literal", func(t *testing.T) {})
+}
+
+func TestDynamic(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {})
+	}
+}
+
+func Testlowercase(t *testing.T) {}
+
+func TestWrongSignature(x int) {}
+
+func helper(t *testing.T) {}
+
+func BenchmarkAdd(b *testing.B) {
+	b.Run("small", func(b *testing.B) {})
+}
+
+func FuzzParse(f *testing.F) {}
+`
+
+const inventoryExampleFile = `package calc_test
+
+import "fmt"
+
+func ExampleAdd() {
+	fmt.Println(3)
+	// Output: 3
+}
+
+func Example_noOutput() {
+	fmt.Println("not run")
+}
+`
+
+func TestDiscoverTestFilesInventory(t *testing.T) {
+	dir := t.TempDir()
+	writeGoFiles(t, dir, map[string]string{
+		"go.mod":                        "module example.com/calc\n\ngo 1.23\n",
+		"calc/calc.go":                  "package calc\n",
+		"calc/calc_test.go":             inventoryTestFile,
+		"calc/example_test.go":          inventoryExampleFile,
+		"util/util_test.go":             "package util\n\nimport \"testing\"\n\nfunc TestUtil(t *testing.T) {}\n",
+		"util/broken_test.go":           "package util\n\nfunc {\n",
+		"vendor/dep/dep_test.go":        "package dep\n\nimport \"testing\"\n\nfunc TestVendored(t *testing.T) {}\n",
+		"calc/testdata/fixture_test.go": "package fixture\n",
+	})
+
+	inventory := discoverTestFiles(dir)
+
+	if len(inventory.Packages) != 2 {
+		t.Fatalf("got %d packages, want 2: %+v", len(inventory.Packages), inventory.Packages)
+	}
+	if want := (TestTotals{Tests: 4, Benchmarks: 1, Fuzz: 1, Examples: 2}); inventory.Totals != want {
+		t.Errorf("totals = %+v, want %+v", inventory.Totals, want)
+	}
+	if len(inventory.Errors) != 1 || inventory.Errors[0].File != "util/broken_test.go" {
+		t.Errorf("errors = %+v", inventory.Errors)
+	}
+	if want := []string{"calc/calc_test.go"}; !reflect.DeepEqual(inventory.BenchmarkFiles, want) {
+		t.Errorf("benchmark files = %v, want %v", inventory.BenchmarkFiles, want)
+	}
+
+	calc := inventory.Packages[0]
+	if calc.Dir != "calc" || calc.Name != "calc" || calc.ImportPath != "example.com/calc/calc" {
+		t.Errorf("package = %q %q %q", calc.Dir, calc.Name, calc.ImportPath)
+	}
+	if !calc.HasTestMain {
+		t.Error("expected TestMain to be detected")
+	}
+
+	tests := make(map[string]TestFunc)
+	for _, tf := range calc.Tests {
+		tests[tf.Name] = tf
+	}
+	if len(tests) != 3 {
+		t.Errorf("tests = %+v, want TestAdd, TestDivide and TestDynamic", calc.Tests)
+	}
+	add := tests["TestAdd"]
+	if add.Line != 13 || add.File != "calc/calc_test.go" || add.Run != "^TestAdd$" {
+		t.Errorf("TestAdd = %+v", add)
+	}
+	wantAdd := []string{"TestAdd/positive_numbers", "TestAdd/positive_numbers/nested", "TestAdd/zero", "TestAdd/zero/nested"}
+	if got := subtestNames(add.Subtests); !reflect.DeepEqual(got, wantAdd) || add.Dynamic {
+		t.Errorf("TestAdd subtests = %v dynamic %v, want %v", got, add.Dynamic, wantAdd)
+	}
+	if run := add.Subtests[1].Run; run != "^TestAdd$/^positive_numbers$/^nested$" {
+		t.Errorf("TestAdd/positive_numbers/nested run = %q", run)
+	}
+	wantDivide := []string{"TestDivide/by_one", "TestDivide/by_two", "TestDivide/literal"}
+	if got := subtestNames(tests["TestDivide"].Subtests); !reflect.DeepEqual(got, wantDivide) {
+		t.Errorf("TestDivide subtests = %v, want %v", got, wantDivide)
+	}
+	if dynamic := tests["TestDynamic"]; len(dynamic.Subtests) != 0 || !dynamic.Dynamic {
+		t.Errorf("TestDynamic = %+v", dynamic)
+	}
+
+	if len(calc.Benchmarks) != 1 || !reflect.DeepEqual(calc.Benchmarks[0].Subtests, []Subtest{{Name: "BenchmarkAdd/small", Run: "^BenchmarkAdd$/^small$"}}) {
+		t.Errorf("benchmarks = %+v", calc.Benchmarks)
+	}
+	if len(calc.Fuzz) != 1 || calc.Fuzz[0].Name != "FuzzParse" {
+		t.Errorf("fuzz = %+v", calc.Fuzz)
+	}
+	if len(calc.Examples) != 2 || !calc.Examples[0].HasOutput || calc.Examples[1].HasOutput {
+		t.Errorf("examples = %+v", calc.Examples)
+	}
+
+	util := inventory.Packages[1]
+	if util.Dir != "util" || len(util.Tests) != 1 || util.HasTestMain {
+		t.Errorf("util = %+v", util)
+	}
+}
+
+func TestRewriteSubtestName(t *testing.T) {
+	tests := map[string]string{
+		"simple":         "simple",
+		"with spaces":    "with_spaces",
+		"tab\tand\nline": "tab_and_line",
+		"bell\a":         `bell\a`,
+	}
+	for name, want := range tests {
+		if got := rewriteSubtestName(name); got != want {
+			t.Errorf("rewriteSubtestName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func subtestNames(subtests []Subtest) []string {
+	names := make([]string, len(subtests))
+	for i, st := range subtests {
+		names[i] = st.Name
+	}
+	return names
+}
+
+func TestDiscoverTestFilesTestingImport(t *testing.T) {
+	dir := t.TempDir()
+	writeGoFiles(t, dir, map[string]string{
+		"renamed/renamed_test.go": "package renamed\n\nimport tst \"testing\"\n\nfunc TestRenamed(t *tst.T) {}\n\nfunc TestWrongPackage(t *testing.T) {}\n",
+		"dot/dot_test.go":         "package dot\n\nimport . \"testing\"\n\nfunc TestDot(t *T) {}\n",
+		"other/other_test.go":     "package other\n\nimport \"example.com/fake/testing\"\n\nfunc TestFake(t *testing.T) {}\n",
+		"mixed/mixed_test.go":     "package mixed\n\nimport (\n\t\"testing\"\n\t\"example.com/suite\"\n)\n\nfunc TestReal(t *testing.T) {}\n\nfunc TestSuite(t *suite.T) {}\n\nfunc TestSpecial(t *testing.T) { t.Run(\"a.b(c)\", func(t *testing.T) {}) }\n",
+	})
+
+	inventory := discoverTestFiles(dir)
+	found := make(map[string]TestFunc)
+	for _, pkg := range inventory.Packages {
+		for _, tf := range pkg.Tests {
+			found[tf.Name] = tf
+		}
+	}
+	for _, name := range []string{"TestRenamed", "TestDot", "TestReal", "TestSpecial"} {
+		if _, ok := found[name]; !ok {
+			t.Errorf("expected %s to be found", name)
+		}
+	}
+	for _, name := range []string{"TestWrongPackage", "TestFake", "TestSuite"} {
+		if _, ok := found[name]; ok {
+			t.Errorf("expected %s not to be a test", name)
+		}
+	}
+	if subtests := found["TestSpecial"].Subtests; len(subtests) != 1 || subtests[0].Run != `^TestSpecial$/^a\.b\(c\)$` {
+		t.Errorf("TestSpecial subtests = %+v", subtests)
+	}
+}
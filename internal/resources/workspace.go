@@ -0,0 +1,242 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"github.com/inja-online/golang-mcp/internal/utils"
+)
+
+var errRelativeGoWork = errors.New("invalid GOWORK: not an absolute path")
+
+// WorkFile is the parsed content of a go.work file.
+type WorkFile struct {
+	Go        string         `json:"go"`
+	Toolchain string         `json:"toolchain,omitempty"`
+	Godebug   []ModGodebug   `json:"godebug,omitempty"`
+	Use       []WorkUse      `json:"use"`
+	Replace   []ModReplace   `json:"replace,omitempty"`
+	Errors    []ModFileError `json:"errors,omitempty"` // directives that could not be parsed
+}
+
+// WorkUse is a module directory added to the workspace.
+type WorkUse struct {
+	Path string `json:"path"` // as written, relative to the go.work directory
+	Line int    `json:"line"`
+}
+
+// Workspace describes the modules the go command builds from a directory:
+// the modules of the go.work file in effect, or the single main module.
+type Workspace struct {
+	WorkingDirectory string            `json:"working_directory"`
+	HasGoMod         bool              `json:"has_go_mod"`  // go.mod in the working directory
+	HasGoWork        bool              `json:"has_go_work"` // a go.work file is in effect
+	GoWork           string            `json:"go_work,omitempty"`
+	Work             *WorkFile         `json:"work,omitempty"`
+	Modules          []WorkspaceModule `json:"modules"`
+	Error            string            `json:"error,omitempty"` // why the go command cannot build from the directory
+}
+
+// WorkspaceModule is a main module of a workspace.
+type WorkspaceModule struct {
+	Dir       string   `json:"dir"` // absolute module directory
+	Use       string   `json:"use,omitempty"`
+	Path      string   `json:"path,omitempty"` // module path
+	Go        string   `json:"go,omitempty"`
+	Toolchain string   `json:"toolchain,omitempty"`
+	Requires  int      `json:"requires"`
+	Replaces  int      `json:"replaces,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"` // workspace modules it requires
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// parseGoWork parses the content of a go.work file
+func parseGoWork(content string) *WorkFile {
+	work := &WorkFile{Use: []WorkUse{}}
+	lines, errs := lexGoMod(content)
+	work.Errors = errs
+	forEachDirective(lines, work.addDirective, work.addError)
+	return work
+}
+
+func (work *WorkFile) addError(line int, message string) {
+	work.Errors = append(work.Errors, ModFileError{Line: line, Message: message})
+}
+
+// addDirective adds a single directive with the given verb and arguments
+func (work *WorkFile) addDirective(verb string, args []string, line modLine) {
+	switch verb {
+	case "go":
+		if len(args) != 1 {
+			work.addError(line.line, "usage: go 1.23")
+			return
+		}
+		work.Go = args[0]
+	case "toolchain":
+		if len(args) != 1 {
+			work.addError(line.line, "usage: toolchain go1.23.1")
+			return
+		}
+		work.Toolchain = args[0]
+	case "godebug":
+		godebug, ok := parseGodebug(args)
+		if !ok {
+			work.addError(line.line, "usage: godebug key=value")
+			return
+		}
+		work.Godebug = append(work.Godebug, godebug)
+	case "use":
+		if len(args) != 1 {
+			work.addError(line.line, "usage: use local/dir")
+			return
+		}
+		work.Use = append(work.Use, WorkUse{Path: args[0], Line: line.line})
+	case "replace":
+		replace, problem := parseReplace(args, line)
+		if problem != "" {
+			work.addError(line.line, problem)
+			return
+		}
+		work.Replace = append(work.Replace, replace)
+	default:
+		work.addError(line.line, fmt.Sprintf("unknown directive: %s", verb))
+	}
+}
+
+// DiscoverWorkspace finds the modules the go command builds from dir. Like
+// the go command it honors GOWORK, as set in the environment go commands run
+// with, and otherwise looks for a go.work file in dir and its parents;
+// without one, the main module is the nearest go.mod.
+func DiscoverWorkspace(cfg *config.Config, dir string) *Workspace {
+	workspace := &Workspace{WorkingDirectory: dir, Modules: []WorkspaceModule{}}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		workspace.HasGoMod = true
+	}
+
+	gowork, _ := utils.LookupEnv(cfg, "GOWORK")
+	workPath, err := findGoWork(dir, gowork)
+	if err != nil {
+		workspace.Error = err.Error()
+		return workspace
+	}
+	if workPath != "" {
+		workspace.HasGoWork = true
+		workspace.GoWork = workPath
+		content, err := os.ReadFile(workPath)
+		if err != nil {
+			workspace.Work = &WorkFile{Use: []WorkUse{}, Errors: []ModFileError{{Message: err.Error()}}}
+			return workspace
+		}
+		workspace.Work = parseGoWork(string(content))
+		workDir := filepath.Dir(workPath)
+		for _, use := range workspace.Work.Use {
+			modDir := use.Path
+			if !filepath.IsAbs(modDir) {
+				modDir = filepath.Join(workDir, filepath.FromSlash(modDir))
+			}
+			module := readWorkspaceModule(modDir)
+			module.Use = use.Path
+			workspace.Modules = append(workspace.Modules, module)
+		}
+		linkWorkspaceModules(workspace.Modules)
+		return workspace
+	}
+
+	if modDir := findGoMod(dir); modDir != "" {
+		workspace.Modules = append(workspace.Modules, readWorkspaceModule(modDir))
+		linkWorkspaceModules(workspace.Modules)
+	}
+	return workspace
+}
+
+// findGoWork returns the go.work file in effect for dir given the value of
+// GOWORK, or "" if there is none or GOWORK=off. Like the go command, it
+// rejects a relative GOWORK.
+func findGoWork(dir, gowork string) (string, error) {
+	switch gowork {
+	case "off":
+		return "", nil
+	case "":
+		return findUpward(dir, "go.work"), nil
+	}
+	if !filepath.IsAbs(gowork) {
+		return "", errRelativeGoWork
+	}
+	return gowork, nil
+}
+
+// findGoMod returns the directory of the go.mod file for dir, or ""
+func findGoMod(dir string) string {
+	if path := findUpward(dir, "go.mod"); path != "" {
+		return filepath.Dir(path)
+	}
+	return ""
+}
+
+// findUpward returns the path of the first file with the given name in dir
+// or its parents
+func findUpward(dir, name string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readWorkspaceModule reads the go.mod file of a module directory
+func readWorkspaceModule(dir string) WorkspaceModule {
+	module := WorkspaceModule{Dir: dir}
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		module.Errors = append(module.Errors, err.Error())
+		return module
+	}
+	mod := parseGoMod(string(content))
+	module.Path = mod.Module
+	module.Go = mod.Go
+	module.Toolchain = mod.Toolchain
+	module.Requires = len(mod.Require)
+	module.Replaces = len(mod.Replace)
+	for _, e := range mod.Errors {
+		module.Errors = append(module.Errors, fmt.Sprintf("go.mod:%d: %s", e.Line, e.Message))
+	}
+	for _, req := range mod.Require {
+		module.DependsOn = append(module.DependsOn, req.Path)
+	}
+	return module
+}
+
+// linkWorkspaceModules keeps only requirements on other workspace modules in
+// DependsOn
+func linkWorkspaceModules(modules []WorkspaceModule) {
+	paths := make(map[string]bool)
+	for _, module := range modules {
+		if module.Path != "" {
+			paths[module.Path] = true
+		}
+	}
+	for i := range modules {
+		var deps []string
+		for _, dep := range modules[i].DependsOn {
+			if paths[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		sort.Strings(deps)
+		modules[i].DependsOn = deps
+	}
+}
@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func TestDiscoverWorkspaceModules(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	dir := t.TempDir()
+	writeGoFiles(t, dir, map[string]string{
+		"go.work":        "go 1.23\n\nuse (\n\t./api\n\t./cli\n\t./missing\n)\n",
+		"api/go.mod":     "module example.com/api\n\ngo 1.22\n\nrequire github.com/google/uuid v1.6.0\n",
+		"cli/go.mod":     "module example.com/cli\n\ngo 1.23\n\nrequire (\n\texample.com/api v0.0.0\n\tgithub.com/spf13/cobra v1.8.0\n)\n",
+		"cli/cmd/cmd.go": "package cmd\n",
+	})
+
+	// Discovery from inside a module finds the go.work file above it
+	cfg := &config.Config{WorkingDirectory: dir}
+	workspace := DiscoverWorkspace(cfg, filepath.Join(dir, "cli", "cmd"))
+	if !workspace.HasGoWork || workspace.HasGoMod || workspace.GoWork != filepath.Join(dir, "go.work") {
+		t.Errorf("has_go_work = %v, has_go_mod = %v, go_work = %q", workspace.HasGoWork, workspace.HasGoMod, workspace.GoWork)
+	}
+	if workspace.Work == nil || workspace.Work.Go != "1.23" {
+		t.Fatalf("work = %+v", workspace.Work)
+	}
+	if len(workspace.Modules) != 3 {
+		t.Fatalf("got %d modules, want 3: %+v", len(workspace.Modules), workspace.Modules)
+	}
+
+	api, cli, missing := workspace.Modules[0], workspace.Modules[1], workspace.Modules[2]
+	if api.Dir != filepath.Join(dir, "api") || api.Use != "./api" || api.Path != "example.com/api" || api.Go != "1.22" || api.Requires != 1 || len(api.DependsOn) != 0 {
+		t.Errorf("api = %+v", api)
+	}
+	if cli.Path != "example.com/cli" || cli.Requires != 2 || !reflect.DeepEqual(cli.DependsOn, []string{"example.com/api"}) {
+		t.Errorf("cli = %+v", cli)
+	}
+	if missing.Path != "" || len(missing.Errors) != 1 {
+		t.Errorf("missing = %+v", missing)
+	}
+
+	// GOWORK=off falls back to the module of the directory
+	t.Setenv("GOWORK", "off")
+	workspace = DiscoverWorkspace(cfg, filepath.Join(dir, "cli", "cmd"))
+	if workspace.HasGoWork || len(workspace.Modules) != 1 || workspace.Modules[0].Path != "example.com/cli" || len(workspace.Modules[0].DependsOn) != 0 {
+		t.Errorf("workspace with GOWORK=off = %+v", workspace)
+	}
+
+	// An absolute GOWORK applies from any directory; a relative one is
+	// rejected like the go command does
+	t.Setenv("GOWORK", filepath.Join(dir, "go.work"))
+	workspace = DiscoverWorkspace(cfg, t.TempDir())
+	if !workspace.HasGoWork || len(workspace.Modules) != 3 {
+		t.Errorf("workspace with absolute GOWORK = %+v", workspace)
+	}
+	t.Setenv("GOWORK", "go.work")
+	workspace = DiscoverWorkspace(cfg, dir)
+	if workspace.Error == "" || workspace.HasGoWork || len(workspace.Modules) != 0 {
+		t.Errorf("workspace with relative GOWORK = %+v", workspace)
+	}
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AuditMiddleware returns receiving middleware that records every tool call
+// in the audit log and tags the request context with the tool name, so that
+// commands executed by the tool are attributed to it.
+func AuditMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			ctx = utils.WithToolName(ctx, callReq.Params.Name)
+			start := time.Now()
+			result, err := next(ctx, method, req)
+
+			entry := utils.AuditEntry{
+				Time:       start,
+				Kind:       utils.AuditKindToolCall,
+				Tool:       callReq.Params.Name,
+				Arguments:  utils.RedactArguments(callReq.Params.Arguments),
+				DurationMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				entry.IsError = true
+				entry.Error = err.Error()
+			}
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult != nil {
+				entry.IsError = entry.IsError || toolResult.IsError
+				for _, content := range toolResult.Content {
+					if text, ok := content.(*mcp.TextContent); ok {
+						entry.OutputBytes += len(text.Text)
+					}
+				}
+			}
+			utils.RecordAudit(entry)
+
+			return result, err
+		}
+	}
+}
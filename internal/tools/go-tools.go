@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -15,8 +16,7 @@ import (
 func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 	count := 0
 	// go_build tool
-	resources.RegisterTool("go_build", "Build Go packages and dependencies. Supports various build flags like -race, -tags, -ldflags, etc.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_build",
 		Description: "Build Go packages and dependencies. Supports various build flags like -race, -tags, -ldflags, etc.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -27,7 +27,23 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 		LDFlags    string   `json:"ldflags,omitempty"`
 		TrimPath   bool     `json:"trimpath,omitempty"`
 		WorkingDir string   `json:"working_dir,omitempty"`
+		Async      bool     `json:"async,omitempty"`
+		Modules    bool     `json:"modules,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
+		if args.Modules && (args.Async || args.Output != "") {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Error: modules cannot be combined with async or output"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+		defaults := cfg.ToolDefaults("go_build")
+		if len(args.Tags) == 0 {
+			args.Tags = defaults.Tags
+		}
+		args.Race = args.Race || defaults.Race
+
 		goArgs := []string{"build"}
 
 		if args.Race {
@@ -45,15 +61,25 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 		if args.Output != "" {
 			goArgs = append(goArgs, "-o", args.Output)
 		}
+		if args.Package == "" && args.Modules {
+			args.Package = "./..."
+		}
 		if args.Package != "" {
 			goArgs = append(goArgs, args.Package)
 		}
 
+		if args.Modules {
+			return runInModules(ctx, cfg, "go", goArgs, args.WorkingDir)
+		}
+		if args.Async {
+			return startJob("go_build", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -66,23 +92,41 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_test tool
-	resources.RegisterTool("go_test", "Run Go tests with coverage, benchmarks, and race detection. Supports various test flags.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_test",
 		Description: "Run Go tests with coverage, benchmarks, and race detection. Supports various test flags.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
-		Package    string `json:"package,omitempty"`
-		Cover      bool   `json:"cover,omitempty"`
-		CoverPkg   string `json:"cover_pkg,omitempty"`
-		Bench      bool   `json:"bench,omitempty"`
-		Race       bool   `json:"race,omitempty"`
-		Verbose    bool   `json:"verbose,omitempty"`
-		Timeout    string `json:"timeout,omitempty"`
-		WorkingDir string `json:"working_dir,omitempty"`
+		Package    string   `json:"package,omitempty"`
+		Cover      bool     `json:"cover,omitempty"`
+		CoverPkg   string   `json:"cover_pkg,omitempty"`
+		Bench      bool     `json:"bench,omitempty"`
+		Race       bool     `json:"race,omitempty"`
+		Tags       []string `json:"tags,omitempty"`
+		Verbose    bool     `json:"verbose,omitempty"`
+		Timeout    string   `json:"timeout,omitempty"`
+		WorkingDir string   `json:"working_dir,omitempty"`
+		Async      bool     `json:"async,omitempty"`
+		Modules    bool     `json:"modules,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
+		if args.Modules && args.Async {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Error: modules cannot be combined with async"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+		defaults := cfg.ToolDefaults("go_test")
+		if len(args.Tags) == 0 {
+			args.Tags = defaults.Tags
+		}
+		args.Race = args.Race || defaults.Race
+		if args.Timeout == "" && defaults.Timeout > 0 {
+			args.Timeout = defaults.Timeout.String()
+		}
+
 		goArgs := []string{"test"}
 
 		if args.Cover {
@@ -97,21 +141,34 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 		if args.Race {
 			goArgs = append(goArgs, "-race")
 		}
+		if len(args.Tags) > 0 {
+			goArgs = append(goArgs, "-tags", strings.Join(args.Tags, ","))
+		}
 		if args.Verbose {
 			goArgs = append(goArgs, "-v")
 		}
 		if args.Timeout != "" {
 			goArgs = append(goArgs, "-timeout", args.Timeout)
 		}
+		if args.Package == "" && args.Modules {
+			args.Package = "./..."
+		}
 		if args.Package != "" {
 			goArgs = append(goArgs, args.Package)
 		}
 
+		if args.Modules {
+			return runInModules(ctx, cfg, "go", goArgs, args.WorkingDir)
+		}
+		if args.Async {
+			return startJob("go_test", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -124,27 +181,30 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_fmt tool
-	resources.RegisterTool("go_fmt", "Format Go code using 'go fmt'. Formats the specified package or files.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_fmt",
 		Description: "Format Go code using 'go fmt'. Formats the specified package or files.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		Paths      []string `json:"paths,omitempty"`
 		WorkingDir string   `json:"working_dir,omitempty"`
+		Async      bool     `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		goArgs := []string{"fmt"}
 		if len(args.Paths) > 0 {
 			goArgs = append(goArgs, args.Paths...)
 		}
 
+		if args.Async {
+			return startJob("go_fmt", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -157,11 +217,9 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_mod tool
-	resources.RegisterTool("go_mod", "Manage Go modules. Supports init, tidy, download, vendor, and get operations.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_mod",
 		Description: "Manage Go modules. Supports init, tidy, download, vendor, and get operations.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -169,6 +227,7 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 		ModulePath string   `json:"module_path,omitempty"`
 		Packages   []string `json:"packages,omitempty"`
 		WorkingDir string   `json:"working_dir,omitempty"`
+		Async      bool     `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		var goArgs []string
 
@@ -198,11 +257,15 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
+		if args.Async {
+			return startJob("go_mod", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -215,17 +278,16 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_doc tool
-	resources.RegisterTool("go_doc", "Generate documentation for Go packages using 'go doc'.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_doc",
 		Description: "Generate documentation for Go packages using 'go doc'.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		Package    string `json:"package" jsonschema:"required"`
 		All        bool   `json:"all,omitempty"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		goArgs := []string{"doc"}
 		if args.All {
@@ -233,11 +295,15 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 		}
 		goArgs = append(goArgs, args.Package)
 
+		if args.Async {
+			return startJob("go_doc", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -250,18 +316,30 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_lint tool
-	resources.RegisterTool("go_lint", "Lint Go code using golangci-lint (if available) or go vet.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_lint",
 		Description: "Lint Go code using golangci-lint (if available) or go vet.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		Package    string `json:"package,omitempty"`
 		Linter     string `json:"linter,omitempty"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
+		Modules    bool   `json:"modules,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
+		if args.Modules && args.Async {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Error: modules cannot be combined with async"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+		if args.Package == "" && args.Modules {
+			args.Package = "./..."
+		}
+
 		var command string
 		var cmdArgs []string
 
@@ -279,11 +357,18 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			}
 		}
 
+		if args.Modules {
+			return runInModules(ctx, cfg, command, cmdArgs, args.WorkingDir)
+		}
+		if args.Async {
+			return startJob("go_lint", command, cmdArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, command, cmdArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -296,11 +381,9 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_cross_compile tool
-	resources.RegisterTool("go_cross_compile", "Cross-compile Go code for different platforms and architectures.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_cross_compile",
 		Description: "Cross-compile Go code for different platforms and architectures.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -309,6 +392,7 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 		GOOS       string `json:"goos" jsonschema:"required"`
 		GOARCH     string `json:"goarch" jsonschema:"required"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		goArgs := []string{"build", "-o", args.Output}
 		if args.Package != "" {
@@ -320,11 +404,15 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			"GOARCH": args.GOARCH,
 		}
 
+		if args.Async {
+			return startJob("go_cross_compile", "go", goArgs, args.WorkingDir, envVars)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, envVars)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -337,10 +425,208 @@ func RegisterGoTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
+
+	// go_list tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_list",
+		Description: "List packages with go list -json -deps: imports, test imports and embedded files, the import graph as DOT or JSON adjacency, import cycles and violations of the package layers and rules in the configuration file.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Packages   []string `json:"packages,omitempty"`
+		Scope      string   `json:"scope,omitempty"`
+		Tags       []string `json:"tags,omitempty"`
+		Graph      string   `json:"graph,omitempty"`
+		WorkingDir string   `json:"working_dir,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if args.Graph != "" && args.Graph != "dot" && args.Graph != "json" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error: invalid graph format %q: must be dot or json", args.Graph)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+		if len(args.Tags) == 0 {
+			args.Tags = cfg.ToolDefaults("go_list").Tags
+		}
+
+		graph, err := utils.ListPackages(ctx, cfg, utils.ListOptions{
+			Patterns:   args.Packages,
+			Tags:       args.Tags,
+			Scope:      args.Scope,
+			WorkingDir: args.WorkingDir,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		output := formatPackageGraph(graph)
+		switch args.Graph {
+		case "dot":
+			output += "\nGraph (DOT):\n" + graph.DOT()
+		case "json":
+			adjacency, _ := json.MarshalIndent(map[string]map[string][]string{
+				"edges":      graph.Edges,
+				"test_edges": graph.TestEdges,
+			}, "", "  ")
+			output += "\nGraph (JSON adjacency):\n" + string(adjacency) + "\n"
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, graph, nil
+	})
 	return count
 }
 
+// formatPackageGraph summarizes the packages of a graph with their imports
+// between listed packages, the import cycles and the rule violations
+func formatPackageGraph(graph *utils.PackageGraph) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "Packages: %d (scope %s", len(graph.Packages), graph.Scope)
+	if len(graph.Modules) > 0 {
+		fmt.Fprintf(&output, ", module %s", strings.Join(graph.Modules, ", "))
+	}
+	output.WriteString(")\n")
+
+	for _, pkg := range graph.Packages {
+		fmt.Fprintf(&output, "\n%s", pkg.ImportPath)
+		if pkg.Standard {
+			output.WriteString(" (std)")
+		}
+		fmt.Fprintf(&output, "\n  imports: %d", len(pkg.Imports))
+		if edges := graph.Edges[pkg.ImportPath]; len(edges) > 0 {
+			fmt.Fprintf(&output, " (listed: %s)", strings.Join(edges, ", "))
+		}
+		output.WriteString("\n")
+		if edges := graph.TestEdges[pkg.ImportPath]; len(edges) > 0 {
+			fmt.Fprintf(&output, "  test imports: %s\n", strings.Join(edges, ", "))
+		}
+		if len(pkg.EmbedFiles) > 0 || len(pkg.TestEmbedFiles) > 0 {
+			fmt.Fprintf(&output, "  embeds: %s\n", strings.Join(append(append([]string{}, pkg.EmbedFiles...), pkg.TestEmbedFiles...), ", "))
+		}
+	}
+
+	if len(graph.Cycles) > 0 {
+		fmt.Fprintf(&output, "\nImport cycles (%d):\n", len(graph.Cycles))
+		for _, cycle := range graph.Cycles {
+			kind := ""
+			if cycle.Test {
+				kind = " (test)"
+			}
+			fmt.Fprintf(&output, "  %s%s\n", strings.Join(cycle.Path, " -> "), kind)
+		}
+	}
+	if len(graph.Violations) > 0 {
+		fmt.Fprintf(&output, "\nRule violations (%d):\n", len(graph.Violations))
+		for _, v := range graph.Violations {
+			kind := ""
+			if v.Test {
+				kind = " (test)"
+			}
+			fmt.Fprintf(&output, "  %s -> %s%s: %s\n", v.From, v.To, kind, v.Rule)
+			if v.Reason != "" {
+				fmt.Fprintf(&output, "    reason: %s\n", v.Reason)
+			}
+		}
+	}
+	if len(graph.Errors) > 0 {
+		output.WriteString("\nErrors:\n")
+		for _, e := range graph.Errors {
+			fmt.Fprintf(&output, "  %s\n", e)
+		}
+	}
+	return output.String()
+}
+
+// moduleRun is the outcome of a command run in every workspace module
+type moduleRun struct {
+	Modules   []moduleResult `json:"modules"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+}
+
+// moduleResult is the outcome of a command in one workspace module
+type moduleResult struct {
+	Module     string `json:"module"`
+	Dir        string `json:"dir"`
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout,omitempty"`
+	Stderr     string `json:"stderr,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// runInModules runs a command in the directory of every module of the
+// workspace found from workingDir, one module at a time, and aggregates the
+// results
+func runInModules(ctx context.Context, cfg *config.Config, command string, args []string, workingDir string) (*mcp.CallToolResult, any, error) {
+	dir := workingDir
+	if dir == "" {
+		dir = cfg.WorkingDirectory
+	}
+	workspace := resources.DiscoverWorkspace(cfg, dir)
+	if workspace.Error != "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %s", workspace.Error)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+	if len(workspace.Modules) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: no go.work or go.mod found for %s", dir)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	run := &moduleRun{Modules: []moduleResult{}}
+	var sections strings.Builder
+	for _, module := range workspace.Modules {
+		if ctx.Err() != nil {
+			break
+		}
+		mr := moduleResult{Module: module.Path, Dir: module.Dir}
+		if module.Path == "" {
+			mr.Module = module.Use
+			mr.Error = strings.Join(module.Errors, "; ")
+		} else if result, err := utils.ExecuteGoCommand(ctx, cfg, command, args, module.Dir, nil); err != nil {
+			mr.Error = err.Error()
+		} else {
+			mr.ExitCode = result.ExitCode
+			mr.Stdout = result.Stdout
+			mr.Stderr = result.Stderr
+			mr.DurationMS = result.Duration.Milliseconds()
+			fmt.Fprintf(&sections, "=== %s (%s)\n%s\n", mr.Module, mr.Dir, formatCommandResult(result))
+		}
+
+		if mr.Error != "" {
+			fmt.Fprintf(&sections, "=== %s (%s)\nError: %s\n\n", mr.Module, mr.Dir, mr.Error)
+		}
+		if mr.Error != "" || mr.ExitCode != 0 {
+			run.Failed++
+		} else {
+			run.Succeeded++
+		}
+		run.Modules = append(run.Modules, mr)
+	}
+
+	output := fmt.Sprintf("Modules: %d (%d succeeded, %d failed)\n\n%s", len(run.Modules), run.Succeeded, run.Failed, sections.String())
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output},
+		},
+	}, run, nil
+}
+
 // formatCommandResult formats a command result for display
 func formatCommandResult(result *utils.CommandResult) string {
 	var output strings.Builder
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"github.com/inja-online/golang-mcp/internal/resources"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectTools registers tools on a new server and connects a client to it
+func connectTools(t *testing.T, cfg *config.Config, register ...func(*mcp.Server, *config.Config) int) *mcp.ClientSession {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	for _, r := range register {
+		r(server, cfg)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "0.0.1"}, nil)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+// callToolText calls a tool and returns its text output, failing unless the
+// result's error state is wantError
+func callToolText(t *testing.T, session *mcp.ClientSession, name string, args map[string]any, wantError bool) string {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	var text strings.Builder
+	for _, content := range result.Content {
+		if tc, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	if result.IsError != wantError {
+		t.Fatalf("%s: IsError = %v, want %v: %s", name, result.IsError, wantError, text.String())
+	}
+	return text.String()
+}
+
+func TestGoToolsOffline(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/offline\n\ngo 1.21\n\nrequire example.invalid/missing v1.0.0\n",
+		"main.go": "package main\n\nimport _ \"example.invalid/missing\"\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: dir, Offline: true, OfflineModMode: "mod"}
+	session := connectTools(t, cfg, RegisterGoTools)
+
+	// The go command fails the download and the output explains why
+	for _, operation := range []string{"download", "tidy"} {
+		text := callToolText(t, session, "go_mod", map[string]any{"operation": operation}, false)
+		if !strings.Contains(text, "Exit Code: 1") || !strings.Contains(text, "offline mode is enabled") {
+			t.Errorf("go_mod %s output = %q", operation, text)
+		}
+	}
+}
+
+func TestGoToolsOutsideWorkspaceRoots(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: root, RestrictToWorkspace: true, WorkspaceRoots: []string{root}}
+	session := connectTools(t, cfg, RegisterGoTools, RegisterOptimizationTools)
+
+	outside := t.TempDir()
+	calls := map[string]map[string]any{
+		"go_build":   {"working_dir": outside},
+		"go_lint":    {"working_dir": outside},
+		"go_profile": {"working_dir": outside, "type": "cpu", "output": "cpu.prof"},
+	}
+	for name, args := range calls {
+		text := callToolText(t, session, name, args, true)
+		if !strings.Contains(text, "outside the configured workspace roots") {
+			t.Errorf("%s output = %q", name, text)
+		}
+	}
+}
+
+func TestDisabledToolsNotRegistered(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		DisableNotifications: true,
+		WorkingDirectory:     t.TempDir(),
+		Tools:                map[string]config.ToolConfig{"go_build": {Enabled: &disabled}},
+		DisabledResources:    []string{ServerLogsURITemplate},
+	}
+	session := connectTools(t, cfg, RegisterGoTools, RegisterServerResources)
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		names[tool.Name] = true
+	}
+	if names["go_build"] || !names["go_test"] {
+		t.Errorf("tools = %v, want go_test without go_build", names)
+	}
+	if !resources.IsToolDisabled("go_build") || resources.IsToolDisabled("go_test") {
+		t.Error("expected only go_build to be recorded as disabled")
+	}
+
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates.ResourceTemplates) != 0 || !resources.IsResourceDisabled(ServerLogsURITemplate) {
+		t.Errorf("resource templates = %+v, want none", templates.ResourceTemplates)
+	}
+}
+
+func TestRunInModules(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	// -mod=mod is not allowed in workspace mode
+	t.Setenv("GOFLAGS", "")
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.work":      "go 1.21\n\nuse (\n\t./good\n\t./bad\n)\n",
+		"good/go.mod":  "module example.com/good\n\ngo 1.21\n",
+		"good/good.go": "package good\n\nfunc Answer() int { return 42 }\n",
+		"bad/go.mod":   "module example.com/bad\n\ngo 1.21\n",
+		"bad/bad.go":   "package bad\n\nfunc Broken() int { return \"no\" }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: dir}
+
+	result, structured, err := runInModules(context.Background(), cfg, "go", []string{"build", "./..."}, "")
+	if err != nil || result.IsError {
+		t.Fatalf("runInModules failed: %v %+v", err, result)
+	}
+	run := structured.(*moduleRun)
+	if len(run.Modules) != 2 || run.Succeeded != 1 || run.Failed != 1 {
+		t.Fatalf("run = %+v", run)
+	}
+	if good := run.Modules[0]; good.Module != "example.com/good" || good.Dir != filepath.Join(dir, "good") || good.ExitCode != 0 {
+		t.Errorf("good = %+v", good)
+	}
+	if bad := run.Modules[1]; bad.ExitCode == 0 || !strings.Contains(bad.Stderr, "bad.go") {
+		t.Errorf("bad = %+v", bad)
+	}
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"Modules: 2 (1 succeeded, 1 failed)", "=== example.com/good (", "=== example.com/bad ("} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	empty := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	if result, _, _ := runInModules(context.Background(), empty, "go", []string{"build"}, ""); !result.IsError {
+		t.Error("expected an error without a go.work or go.mod")
+	}
+}
@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"github.com/inja-online/golang-mcp/internal/resources"
+	"github.com/inja-online/golang-mcp/internal/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var jobManager *utils.JobManager
+
+// InitJobManager initializes the background job manager (called from main)
+func InitJobManager(cfg *config.Config) {
+	jobManager = utils.NewJobManager(cfg, cfg.MaxConcurrentJobs, cfg.JobResultTTL)
+}
+
+// startJob submits a command as a background job on behalf of a tool and
+// returns the job ID instead of waiting for the command to finish.
+func startJob(tool, command string, args []string, workingDir string, envVars map[string]string) (*mcp.CallToolResult, any, error) {
+	if jobManager == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "Job manager not initialized"},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	job, err := jobManager.Submit(tool, command, args, workingDir, envVars)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error starting job: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	state := job.State()
+	output := fmt.Sprintf("Job started\nID: %s\nStatus: %s\nCommand: %s %s\n\nUse go_job_status or go_job_wait to retrieve the result.",
+		state.ID, state.Status, state.Command, strings.Join(state.Args, " "))
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output},
+		},
+	}, state, nil
+}
+
+// RegisterJobTools registers background job management tools
+func RegisterJobTools(server *mcp.Server, cfg *config.Config) int {
+	count := 0
+	// go_job_status tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_job_status",
+		Description: "Get the status of a background job started with async=true, including recent output and the result once finished.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID    string `json:"id" jsonschema:"required"`
+		Lines int    `json:"lines,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if jobManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Job manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		job, err := jobManager.GetJob(args.ID)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		lines := args.Lines
+		if lines <= 0 {
+			lines = 20
+		}
+		state := job.State()
+		recent := job.Output.GetRecent(lines)
+
+		output := formatJobState(state)
+		if state.Result == nil && len(recent) > 0 {
+			output += fmt.Sprintf("\nRecent output (%d lines):\n%s\n", len(recent), strings.Join(recent, "\n"))
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, map[string]interface{}{
+			"job":    state,
+			"output": recent,
+		}, nil
+	})
+
+	// go_job_wait tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_job_wait",
+		Description: "Wait for a background job to finish and return its result. Returns the current status if the timeout elapses first.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID      string `json:"id" jsonschema:"required"`
+		Timeout string `json:"timeout,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if jobManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Job manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		timeout := 60 * time.Second
+		if args.Timeout != "" {
+			d, err := time.ParseDuration(args.Timeout)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid timeout %q: %v", args.Timeout, err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			timeout = d
+		}
+
+		job, finished, err := jobManager.WaitJob(ctx, args.ID, timeout)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error waiting for job: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		state := job.State()
+		output := formatJobState(state)
+		if !finished {
+			output = fmt.Sprintf("Job %s still %s after %v\n\n", state.ID, state.Status, timeout) + output
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, state, nil
+	})
+
+	// go_job_cancel tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_job_cancel",
+		Description: "Cancel a queued or running background job.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID string `json:"id" jsonschema:"required"`
+	}) (*mcp.CallToolResult, any, error) {
+		if jobManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Job manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		if err := jobManager.CancelJob(args.ID); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error cancelling job: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Job %s cancellation requested", args.ID)},
+			},
+		}, nil, nil
+	})
+
+	// go_job_list tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_job_list",
+		Description: "List background jobs with their status. Finished jobs are retained for a limited time.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Status string `json:"status,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if jobManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Job manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		jobList := make([]utils.JobState, 0)
+		for _, job := range jobManager.ListJobs() {
+			state := job.State()
+			if args.Status != "" && state.Status != args.Status {
+				continue
+			}
+			jobList = append(jobList, state)
+		}
+
+		if len(jobList) == 0 {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "No jobs found"},
+				},
+			}, jobList, nil
+		}
+
+		var output strings.Builder
+		output.WriteString(fmt.Sprintf("Jobs (%d):\n\n", len(jobList)))
+		for _, state := range jobList {
+			output.WriteString(fmt.Sprintf("ID: %s\n", state.ID))
+			if state.Tool != "" {
+				output.WriteString(fmt.Sprintf("Tool: %s\n", state.Tool))
+			}
+			output.WriteString(fmt.Sprintf("Status: %s\n", state.Status))
+			output.WriteString(fmt.Sprintf("Created: %s\n", state.CreatedAt.Format(time.RFC3339)))
+			output.WriteString("\n")
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output.String()},
+			},
+		}, jobList, nil
+	})
+	return count
+}
+
+// formatJobState formats a job snapshot for display
+func formatJobState(state utils.JobState) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Job: %s\n", state.ID))
+	if state.Tool != "" {
+		output.WriteString(fmt.Sprintf("Tool: %s\n", state.Tool))
+	}
+	output.WriteString(fmt.Sprintf("Command: %s %s\n", state.Command, strings.Join(state.Args, " ")))
+	output.WriteString(fmt.Sprintf("Status: %s\n", state.Status))
+	if state.Duration != "" {
+		output.WriteString(fmt.Sprintf("Duration: %s\n", state.Duration))
+	}
+	if state.Error != "" {
+		output.WriteString(fmt.Sprintf("Error: %s\n", state.Error))
+	}
+	if state.Result != nil {
+		output.WriteString("\n")
+		output.WriteString(formatCommandResult(state.Result))
+	}
+	return output.String()
+}
@@ -19,8 +19,7 @@ func RegisterLSPTools(server *mcp.Server, cfg *config.Config) int {
 	_ = manager // TODO: wire a shared manager instance into server lifecycle if needed
 
 	// lsp_start_session
-	resources.RegisterTool("lsp_start_session", "Start an LSP session for a workspace root URI.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "lsp_start_session",
 		Description: "Start an LSP session for a workspace root URI.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -49,11 +48,9 @@ func RegisterLSPTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, map[string]string{"root_uri": args.RootURI}, nil
 	})
-	count++
 
 	// lsp_shutdown_session
-	resources.RegisterTool("lsp_shutdown_session", "Shutdown an LSP session for a workspace root URI.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "lsp_shutdown_session",
 		Description: "Shutdown an LSP session for a workspace root URI.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -74,11 +71,9 @@ func RegisterLSPTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, map[string]string{"root_uri": args.RootURI}, nil
 	})
-	count++
 
 	// lsp_request
-	resources.RegisterTool("lsp_request", "Send a request to an LSP session and wait for a result.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "lsp_request",
 		Description: "Send a request to an LSP session and wait for a result.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -103,11 +98,9 @@ func RegisterLSPTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, map[string]interface{}{"method": args.Method}, nil
 	})
-	count++
 
 	// lsp_notify
-	resources.RegisterTool("lsp_notify", "Send a notification to an LSP session.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "lsp_notify",
 		Description: "Send a notification to an LSP session.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -130,11 +123,9 @@ func RegisterLSPTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil, nil
 	})
-	count++
 
 	// lsp_subscribe_diagnostics
-	resources.RegisterTool("lsp_subscribe_diagnostics", "Subscribe to diagnostics published by an LSP session.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "lsp_subscribe_diagnostics",
 		Description: "Subscribe to diagnostics published by an LSP session.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -155,7 +146,6 @@ func RegisterLSPTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, nil, nil
 	})
-	count++
 
 	return count
 }
@@ -15,8 +15,7 @@ import (
 func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 	count := 0
 	// go_profile tool
-	resources.RegisterTool("go_profile", "Generate performance profile using pprof. Creates CPU or memory profiles for analysis.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_profile",
 		Description: "Generate performance profile using pprof. Creates CPU or memory profiles for analysis.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -25,6 +24,7 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 		Duration   string `json:"duration,omitempty"`
 		Package    string `json:"package,omitempty"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		var goArgs []string
 
@@ -46,11 +46,15 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 			goArgs = append(goArgs, args.Package)
 		}
 
+		if args.Async {
+			return startJob("go_profile", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -63,28 +67,31 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_trace tool
-	resources.RegisterTool("go_trace", "Generate execution trace for Go programs.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_trace",
 		Description: "Generate execution trace for Go programs.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		Output     string `json:"output" jsonschema:"required"`
 		Package    string `json:"package,omitempty"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		goArgs := []string{"test", "-trace", args.Output}
 		if args.Package != "" {
 			goArgs = append(goArgs, args.Package)
 		}
 
+		if args.Async {
+			return startJob("go_trace", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -97,11 +104,9 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_benchmark tool
-	resources.RegisterTool("go_benchmark", "Run benchmarks and analyze results. Supports filtering and custom benchmark settings.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_benchmark",
 		Description: "Run benchmarks and analyze results. Supports filtering and custom benchmark settings.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -110,7 +115,12 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 		Timeout    string `json:"timeout,omitempty"`
 		Package    string `json:"package,omitempty"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
+		if defaults := cfg.ToolDefaults("go_benchmark"); args.Timeout == "" && defaults.Timeout > 0 {
+			args.Timeout = defaults.Timeout.String()
+		}
+
 		goArgs := []string{"test", "-bench", "."}
 		if args.Pattern != "" {
 			goArgs = append(goArgs, "-bench", args.Pattern)
@@ -127,11 +137,15 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 			goArgs = append(goArgs, args.Package)
 		}
 
+		if args.Async {
+			return startJob("go_benchmark", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -144,27 +158,30 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_race_detect tool
-	resources.RegisterTool("go_race_detect", "Detect race conditions in Go code using the race detector.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_race_detect",
 		Description: "Detect race conditions in Go code using the race detector.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		Package    string `json:"package,omitempty"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		goArgs := []string{"test", "-race", "-v"}
 		if args.Package != "" {
 			goArgs = append(goArgs, args.Package)
 		}
 
+		if args.Async {
+			return startJob("go_race_detect", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -183,28 +200,31 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_memory_profile tool
-	resources.RegisterTool("go_memory_profile", "Generate memory profile for memory usage analysis.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_memory_profile",
 		Description: "Generate memory profile for memory usage analysis.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		Output     string `json:"output" jsonschema:"required"`
 		Package    string `json:"package,omitempty"`
 		WorkingDir string `json:"working_dir,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		goArgs := []string{"test", "-memprofile", args.Output}
 		if args.Package != "" {
 			goArgs = append(goArgs, args.Package)
 		}
 
+		if args.Async {
+			return startJob("go_memory_profile", "go", goArgs, args.WorkingDir, nil)
+		}
+
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, nil)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Error: %v\n%s", err, result.Stderr)},
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
 				},
 				IsError: true,
 			}, nil, nil
@@ -217,11 +237,9 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, result, nil
 	})
-	count++
 
 	// go_optimize_suggest tool
-	resources.RegisterTool("go_optimize_suggest", "Analyze code and provide optimization suggestions based on profiling and benchmarking.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_optimize_suggest",
 		Description: "Analyze code and provide optimization suggestions based on profiling and benchmarking.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -263,6 +281,5 @@ func RegisterOptimizationTools(server *mcp.Server, cfg *config.Config) int {
 				"suggestions": suggestions,
 			}, nil
 	})
-	count++
 	return count
 }
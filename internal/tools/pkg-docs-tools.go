@@ -28,8 +28,7 @@ func InitPackageDocsCache() {
 func RegisterPackageDocsTools(server *mcp.Server, cfg *config.Config) int {
 	count := 0
 	// go_pkg_docs tool
-	resources.RegisterTool("go_pkg_docs", "Fetch package documentation from go.dev (pkg.go.dev). Returns package overview, functions, types, and examples.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_pkg_docs",
 		Description: "Fetch package documentation from go.dev (pkg.go.dev). Returns package overview, functions, types, and examples.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -40,7 +39,7 @@ func RegisterPackageDocsTools(server *mcp.Server, cfg *config.Config) int {
 			InitPackageDocsCache()
 		}
 
-		doc, err := utils.FetchPackageDocs(ctx, args.Package, args.Version, pkgDocsCache)
+		doc, err := utils.FetchPackageDocs(ctx, cfg, args.Package, args.Version, pkgDocsCache)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
@@ -93,17 +92,15 @@ func RegisterPackageDocsTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, doc, nil
 	})
-	count++
 
 	// go_pkg_search tool
-	resources.RegisterTool("go_pkg_search", "Search for packages on go.dev. Returns a list of matching package paths.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_pkg_search",
 		Description: "Search for packages on go.dev. Returns a list of matching package paths.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		Query string `json:"query" jsonschema:"required"`
 	}) (*mcp.CallToolResult, any, error) {
-		packages, err := utils.SearchPackages(ctx, args.Query)
+		packages, err := utils.SearchPackages(ctx, cfg, args.Query)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
@@ -132,11 +129,9 @@ func RegisterPackageDocsTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, map[string]interface{}{"packages": packages}, nil
 	})
-	count++
 
 	// go_pkg_examples tool
-	resources.RegisterTool("go_pkg_examples", "Extract and return examples from package documentation.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_pkg_examples",
 		Description: "Extract and return examples from package documentation.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -147,7 +142,7 @@ func RegisterPackageDocsTools(server *mcp.Server, cfg *config.Config) int {
 			InitPackageDocsCache()
 		}
 
-		doc, err := utils.FetchPackageDocs(ctx, args.Package, args.Version, pkgDocsCache)
+		doc, err := utils.FetchPackageDocs(ctx, cfg, args.Package, args.Version, pkgDocsCache)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
@@ -181,6 +176,5 @@ func RegisterPackageDocsTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, doc.Examples, nil
 	})
-	count++
 	return count
 }
@@ -14,14 +14,14 @@ import (
 func RegisterRunTools(server *mcp.Server, cfg *config.Config) int {
 	count := 0
 	// go_run tool
-	resources.RegisterTool("go_run", "Execute a Go file directly using 'go run'. Runs the specified Go file with optional arguments and environment variables.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_run",
 		Description: "Execute a Go file directly using 'go run'. Runs the specified Go file with optional arguments and environment variables.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		File       string            `json:"file" jsonschema:"required"`
 		Args       []string          `json:"args,omitempty"`
 		WorkingDir string            `json:"working_dir,omitempty"`
+		Async      bool              `json:"async,omitempty"`
 		EnvVars    map[string]string `json:"env_vars,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		// Build go run command
@@ -31,6 +31,10 @@ func RegisterRunTools(server *mcp.Server, cfg *config.Config) int {
 			goArgs = append(goArgs, args.Args...)
 		}
 
+		if args.Async {
+			return startJob("go_run", "go", goArgs, args.WorkingDir, args.EnvVars)
+		}
+
 		// Execute command
 		result, err := utils.ExecuteGoCommand(ctx, cfg, "go", goArgs, args.WorkingDir, args.EnvVars)
 		if err != nil {
@@ -65,6 +69,5 @@ func RegisterRunTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, resultData, nil
 	})
-	count++
 	return count
 }
@@ -32,6 +32,11 @@ func TestRegisterPackageDocsTools(t *testing.T) {
 	t.Skip("Tool registration test skipped due to jsonschema validation in test environment")
 }
 
+func TestRegisterJobTools(t *testing.T) {
+	// Tool registration tests skipped due to jsonschema validation in test environment
+	t.Skip("Tool registration test skipped due to jsonschema validation in test environment")
+}
+
 func TestAllToolsRegistration(t *testing.T) {
 	// Tool registration tests skipped due to jsonschema validation in test environment
 	t.Skip("Tool registration test skipped due to jsonschema validation in test environment")
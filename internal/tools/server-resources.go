@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"github.com/inja-online/golang-mcp/internal/resources"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ServerLogsURITemplate is the URI template of the log resource of a managed server
+const ServerLogsURITemplate = "go://servers/{id}/logs"
+
+// logNotifyInterval coalesces log notifications so a chatty server sends at
+// most one per interval.
+const logNotifyInterval = 100 * time.Millisecond
+
+// serverLogsURI returns the log resource URI of the server with the given ID
+func serverLogsURI(id string) string {
+	return "go://servers/" + id + "/logs"
+}
+
+// parseServerLogsURI returns the server ID of a log resource URI
+func parseServerLogsURI(uri string) (string, bool) {
+	id, ok := strings.CutPrefix(uri, "go://servers/")
+	if !ok {
+		return "", false
+	}
+	id, ok = strings.CutSuffix(id, "/logs")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+// logNotifier sends resource updated notifications for server logs
+type logNotifier struct {
+	server  *mcp.Server
+	pending map[string]bool
+	mu      sync.Mutex
+}
+
+// notify schedules a notification for the server's log resource unless
+// one is already pending.
+func (n *logNotifier) notify(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.pending[id] {
+		return
+	}
+	n.pending[id] = true
+
+	time.AfterFunc(logNotifyInterval, func() {
+		n.mu.Lock()
+		delete(n.pending, id)
+		n.mu.Unlock()
+		_ = n.server.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: serverLogsURI(id)})
+	})
+}
+
+// SubscribeResource validates resource subscription requests. Only server
+// log resources change over time, so other URIs are rejected.
+func SubscribeResource(ctx context.Context, req *mcp.SubscribeRequest) error {
+	if _, ok := parseServerLogsURI(req.Params.URI); !ok {
+		return fmt.Errorf("subscriptions are only supported for %s", ServerLogsURITemplate)
+	}
+	return nil
+}
+
+// UnsubscribeResource handles resource unsubscription requests.
+func UnsubscribeResource(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	return nil
+}
+
+// RegisterServerResources registers the resources of managed servers and
+// hooks server logs up to resource subscriptions.
+func RegisterServerResources(server *mcp.Server, cfg *config.Config) int {
+	count := 0
+
+	if serverManager != nil {
+		notifier := &logNotifier{server: server, pending: make(map[string]bool)}
+		serverManager.SetLogObserver(notifier.notify)
+	}
+
+	// go://servers/{id}/logs resource template
+	count += resources.AddResourceTemplate(server, cfg, &mcp.ResourceTemplate{
+		URITemplate: ServerLogsURITemplate,
+		Name:        "Server Logs",
+		Description: "Timestamped stdout and stderr lines of a managed server. Subscribe to be notified as new lines arrive.",
+		MIMEType:    "application/json",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		id, ok := parseServerLogsURI(req.Params.URI)
+		if !ok || serverManager == nil {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+		serverInfo, err := serverManager.GetServer(id)
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
+		lines := serverInfo.Lines.GetAll()
+		result := map[string]interface{}{
+			"id":       serverInfo.ID,
+			"running":  serverInfo.IsRunning(),
+			"lines":    lines,
+			"count":    len(lines),
+			"last_seq": serverInfo.Lines.LastSeq(),
+		}
+
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal server logs: %w", err)
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{
+				{URI: req.Params.URI, MIMEType: "application/json", Text: string(jsonData)},
+			},
+		}, nil
+	})
+
+	return count
+}
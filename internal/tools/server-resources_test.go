@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"github.com/inja-online/golang-mcp/internal/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseServerLogsURI(t *testing.T) {
+	tests := []struct {
+		uri string
+		id  string
+		ok  bool
+	}{
+		{"go://servers/api/logs", "api", true},
+		{serverLogsURI("my-server"), "my-server", true},
+		{"go://servers//logs", "", false},
+		{"go://servers/a/b/logs", "", false},
+		{"go://servers/api", "", false},
+		{"go://audit", "", false},
+	}
+
+	for _, tt := range tests {
+		id, ok := parseServerLogsURI(tt.uri)
+		if id != tt.id || ok != tt.ok {
+			t.Errorf("parseServerLogsURI(%q) = %q, %v, want %q, %v", tt.uri, id, ok, tt.id, tt.ok)
+		}
+	}
+}
+
+func TestServerLogsSubscription(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	InitServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, &mcp.ServerOptions{
+		SubscribeHandler:   SubscribeResource,
+		UnsubscribeHandler: UnsubscribeResource,
+	})
+	RegisterServerResources(server, cfg)
+
+	updated := make(chan string, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "0.0.1"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updated <- req.Params.URI
+		},
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	uri := serverLogsURI("subscribed")
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "go://audit"}); err == nil {
+		t.Error("Expected subscription to a static resource to fail")
+	}
+
+	_, err = serverManager.StartServerWithOptions(ctx, cfg, utils.ServerOptions{
+		ID:      "subscribed",
+		Command: "sh",
+		Args:    []string{"-c", "echo hello; sleep 30"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() { _ = serverManager.StopServer("subscribed", true) }()
+
+	select {
+	case got := <-updated:
+		if got != uri {
+			t.Errorf("Expected notification for %s, got %s", uri, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a resource updated notification")
+	}
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if len(result.Contents) != 1 || !strings.Contains(result.Contents[0].Text, `"text": "hello"`) {
+		t.Errorf("Expected log line in resource, got %+v", result.Contents)
+	}
+
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: serverLogsURI("missing")}); err == nil {
+		t.Error("Expected error reading logs of unknown server")
+	}
+}
@@ -3,6 +3,9 @@ package tools
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,22 +22,61 @@ func InitServerManager() {
 	serverManager = utils.NewServerManager()
 }
 
+// PurgeExpiredServerLogs removes server log files older than the configured
+// retention and returns how many were removed.
+func PurgeExpiredServerLogs(cfg *config.Config) (int, error) {
+	if serverManager == nil || cfg.ServerLogDir == "" {
+		return 0, nil
+	}
+	removed, err := serverManager.PurgeServerLogs(cfg, "", time.Now().Add(-cfg.ServerLogRetention), false)
+	return len(removed), err
+}
+
+// RestoreServers restores the servers of an earlier run of the MCP server
+// from the configured state file and persists the registry from now on.
+func RestoreServers(cfg *config.Config) (*utils.RestoreReport, error) {
+	if serverManager == nil || cfg.ServerStateFile == "" {
+		return &utils.RestoreReport{}, nil
+	}
+	return serverManager.RestoreServers(cfg)
+}
+
 // RegisterServerTools registers server management tools
 func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 	count := 0
 	// go_server_start tool
-	resources.RegisterTool("go_server_start", "Start a long-running Go server in the background. Returns a server ID for management.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_server_start",
-		Description: "Start a long-running Go server in the background. Returns a server ID for management.",
+		Description: "Start a long-running Go server in the background. Returns a server ID for management. Either give a command, or a package to build with go build and run as a binary, which avoids the extra process and recompilation of go run. Use {{port:name}} in args, env_vars or health checks to allocate a free port.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
-		ID         string            `json:"id" jsonschema:"required"`
-		Name       string            `json:"name" jsonschema:"required"`
-		Command    string            `json:"command" jsonschema:"required"`
-		Args       []string          `json:"args" jsonschema:"required"`
-		WorkingDir string            `json:"working_dir,omitempty"`
-		EnvVars    map[string]string `json:"env_vars,omitempty"`
-		LogSize    int               `json:"log_size,omitempty"`
+		ID               string            `json:"id" jsonschema:"required"`
+		Name             string            `json:"name" jsonschema:"required"`
+		Command          string            `json:"command,omitempty"`
+		Args             []string          `json:"args,omitempty"`
+		Package          string            `json:"package,omitempty"`
+		BuildTags        []string          `json:"build_tags,omitempty"`
+		Race             bool              `json:"race,omitempty"`
+		LDFlags          string            `json:"ldflags,omitempty"`
+		WorkingDir       string            `json:"working_dir,omitempty"`
+		EnvVars          map[string]string `json:"env_vars,omitempty"`
+		LogSize          int               `json:"log_size,omitempty"`
+		HealthTCP        string            `json:"health_tcp,omitempty"`
+		HealthHTTP       string            `json:"health_http,omitempty"`
+		HealthStatus     int               `json:"health_status,omitempty"`
+		HealthLogPattern string            `json:"health_log_pattern,omitempty"`
+		HealthInterval   string            `json:"health_interval,omitempty"`
+		WaitReady        bool              `json:"wait_ready,omitempty"`
+		ReadyTimeout     string            `json:"ready_timeout,omitempty"`
+		RestartPolicy    string            `json:"restart_policy,omitempty"`
+		MaxRestarts      int               `json:"max_restarts,omitempty"`
+		RestartBackoff   string            `json:"restart_backoff,omitempty"`
+		Watch            bool              `json:"watch,omitempty"`
+		WatchDir         string            `json:"watch_dir,omitempty"`
+		WatchPackage     string            `json:"watch_package,omitempty"`
+		WatchIgnore      []string          `json:"watch_ignore,omitempty"`
+		WatchDebounce    string            `json:"watch_debounce,omitempty"`
+		StopTimeout      string            `json:"stop_timeout,omitempty"`
+		Ports            []string          `json:"ports,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		if serverManager == nil {
 			return &mcp.CallToolResult{
@@ -45,7 +87,135 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
-		serverInfo, err := serverManager.StartServer(ctx, cfg, args.ID, args.Name, args.Command, args.Args, args.WorkingDir, args.EnvVars, args.LogSize)
+		opts := utils.ServerOptions{
+			ID:            args.ID,
+			Name:          args.Name,
+			Command:       args.Command,
+			Args:          args.Args,
+			WorkingDir:    args.WorkingDir,
+			EnvVars:       args.EnvVars,
+			LogSize:       args.LogSize,
+			RestartPolicy: args.RestartPolicy,
+			MaxRestarts:   args.MaxRestarts,
+			Ports:         args.Ports,
+		}
+		switch {
+		case args.Package != "" && args.Command != "":
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Set either command or package, not both"},
+				},
+				IsError: true,
+			}, nil, nil
+		case args.Package != "":
+			opts.Build = &utils.BuildOptions{
+				Package: args.Package,
+				Tags:    args.BuildTags,
+				Race:    args.Race,
+				LDFlags: args.LDFlags,
+			}
+		case args.Command == "":
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Either command or package is required"},
+				},
+				IsError: true,
+			}, nil, nil
+		case len(args.BuildTags) > 0 || args.Race || args.LDFlags != "":
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "build_tags, race and ldflags require package"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+		if args.RestartBackoff != "" {
+			backoff, err := time.ParseDuration(args.RestartBackoff)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid restart_backoff: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			opts.RestartBackoff = backoff
+		}
+		if args.StopTimeout != "" {
+			stopTimeout, err := time.ParseDuration(args.StopTimeout)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid stop_timeout: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			opts.StopTimeout = stopTimeout
+		}
+		if args.HealthTCP != "" || args.HealthHTTP != "" || args.HealthLogPattern != "" {
+			opts.HealthCheck = &utils.HealthCheck{
+				TCPAddress:     args.HealthTCP,
+				HTTPURL:        args.HealthHTTP,
+				ExpectedStatus: args.HealthStatus,
+				LogPattern:     args.HealthLogPattern,
+			}
+			if args.HealthInterval != "" {
+				interval, err := time.ParseDuration(args.HealthInterval)
+				if err != nil {
+					return &mcp.CallToolResult{
+						Content: []mcp.Content{
+							&mcp.TextContent{Text: fmt.Sprintf("Invalid health_interval: %v", err)},
+						},
+						IsError: true,
+					}, nil, nil
+				}
+				opts.HealthCheck.Interval = interval
+			}
+		} else if args.WaitReady {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "wait_ready requires health_tcp, health_http or health_log_pattern"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		if args.Watch {
+			opts.Watch = &utils.WatchOptions{
+				Dir:     args.WatchDir,
+				Package: args.WatchPackage,
+				Ignore:  args.WatchIgnore,
+			}
+			if args.WatchDebounce != "" {
+				debounce, err := time.ParseDuration(args.WatchDebounce)
+				if err != nil {
+					return &mcp.CallToolResult{
+						Content: []mcp.Content{
+							&mcp.TextContent{Text: fmt.Sprintf("Invalid watch_debounce: %v", err)},
+						},
+						IsError: true,
+					}, nil, nil
+				}
+				opts.Watch.Debounce = debounce
+			}
+		}
+
+		readyTimeout := 30 * time.Second
+		if args.ReadyTimeout != "" {
+			d, err := time.ParseDuration(args.ReadyTimeout)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid ready_timeout: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			readyTimeout = d
+		}
+
+		serverInfo, err := serverManager.StartServerWithOptions(ctx, cfg, opts)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
@@ -55,23 +225,53 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
-		output := fmt.Sprintf("Server started successfully\nID: %s\nPID: %d\nStatus: %s\n", serverInfo.ID, serverInfo.PID, serverInfo.Status)
+		if args.WaitReady {
+			if err := serverManager.WaitReady(ctx, serverInfo.ID, readyTimeout); err != nil {
+				logs, _ := serverManager.GetServerLogs(serverInfo.ID, 20)
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Server started but is not ready: %v\nID: %s\nPID: %d\n\nRecent logs:\n%s", err, serverInfo.ID, serverInfo.ProcessState().PID, strings.Join(logs, "\n"))},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+		}
+
+		state := serverInfo.ProcessState()
+		output := fmt.Sprintf("Server started successfully\nID: %s\nPID: %d\nStatus: %s\n", serverInfo.ID, state.PID, state.Status)
+		startData := map[string]interface{}{
+			"id":     serverInfo.ID,
+			"name":   serverInfo.Name,
+			"pid":    state.PID,
+			"status": state.Status,
+		}
+		if build := serverInfo.BuildState(); build != nil && build.Binary != "" {
+			output += fmt.Sprintf("Binary: %s (built in %s)\n", build.Binary, build.Duration)
+			startData["binary"] = build.Binary
+		}
+		if ports := serverInfo.Ports(); len(ports) > 0 {
+			output += fmt.Sprintf("Ports: %s\n", formatPorts(ports))
+			startData["ports"] = ports
+		}
+		if health, _ := serverInfo.HealthState(); health != "" {
+			output += fmt.Sprintf("Health: %s\n", health)
+			startData["health"] = health
+		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: output},
 			},
-		}, serverInfo, nil
+		}, startData, nil
 	})
-	count++
 
 	// go_server_stop tool
-	resources.RegisterTool("go_server_stop", "Stop a running server. Uses graceful shutdown (SIGTERM) by default, or force kill if specified.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_server_stop",
-		Description: "Stop a running server. Uses graceful shutdown (SIGTERM) by default, or force kill if specified.",
+		Description: "Stop a running server and its child processes. Sends SIGTERM and kills the server if it is still running after the grace period, or kills it immediately if force is set. Reports whether the server exited gracefully, was killed or had already exited.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
-		ID    string `json:"id" jsonschema:"required"`
-		Force bool   `json:"force,omitempty"`
+		ID          string `json:"id" jsonschema:"required"`
+		Force       bool   `json:"force,omitempty"`
+		GracePeriod string `json:"grace_period,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		if serverManager == nil {
 			return &mcp.CallToolResult{
@@ -82,7 +282,21 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
-		err := serverManager.StopServer(args.ID, args.Force)
+		opts := utils.StopOptions{Force: args.Force}
+		if args.GracePeriod != "" {
+			grace, err := time.ParseDuration(args.GracePeriod)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid grace_period: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			opts.GracePeriod = grace
+		}
+
+		result, err := serverManager.StopServerWithOptions(args.ID, opts)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
@@ -92,18 +306,63 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
-		output := fmt.Sprintf("Server %s stopped successfully", args.ID)
+		var output string
+		switch result.Outcome {
+		case utils.StopGraceful:
+			output = fmt.Sprintf("Server %s stopped gracefully after %s", args.ID, result.Duration)
+		case utils.StopKilled:
+			output = fmt.Sprintf("Server %s was killed after %s", args.ID, result.Duration)
+		default:
+			output = fmt.Sprintf("Server %s had already exited", args.ID)
+		}
+		if result.ExitCode != nil {
+			output += fmt.Sprintf(" (exit code %d)", *result.ExitCode)
+		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: output},
 			},
-		}, nil, nil
+		}, result, nil
+	})
+
+	// go_server_restart tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_server_restart",
+		Description: "Restart a server with its original command, arguments, environment and working directory.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID string `json:"id" jsonschema:"required"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		serverInfo, err := serverManager.RestartServer(ctx, args.ID)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error restarting server: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		restarts, _ := serverInfo.RestartState()
+		pid := serverInfo.ProcessState().PID
+		output := fmt.Sprintf("Server %s restarted\nPID: %d\nRestarts: %d\n", serverInfo.ID, pid, restarts)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, map[string]interface{}{"id": serverInfo.ID, "pid": pid, "restarts": restarts}, nil
 	})
-	count++
 
 	// go_server_list tool
-	resources.RegisterTool("go_server_list", "List all running servers with their status and metadata.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_server_list",
 		Description: "List all running servers with their status and metadata.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -131,25 +390,47 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 
 		serverList := make([]map[string]interface{}, 0, len(servers))
 		for _, server := range servers {
+			state := server.ProcessState()
 			serverData := map[string]interface{}{
 				"id":         server.ID,
 				"name":       server.Name,
-				"pid":        server.PID,
-				"status":     server.Status,
-				"start_time": server.StartTime.Format(time.RFC3339),
+				"pid":        state.PID,
+				"status":     state.Status,
+				"start_time": state.StartTime.Format(time.RFC3339),
 				"command":    server.Command,
 				"args":       server.Args,
 			}
-			if server.ExitCode != nil {
-				serverData["exit_code"] = *server.ExitCode
+			if state.ExitCode != nil {
+				serverData["exit_code"] = *state.ExitCode
+			}
+			ports := server.Ports()
+			if len(ports) > 0 {
+				serverData["ports"] = ports
+			}
+			health, _ := server.HealthState()
+			if health != "" {
+				serverData["health"] = health
+			}
+			restarts, _ := server.RestartState()
+			if restarts > 0 {
+				serverData["restarts"] = restarts
 			}
 			serverList = append(serverList, serverData)
 
 			output.WriteString(fmt.Sprintf("ID: %s\n", server.ID))
 			output.WriteString(fmt.Sprintf("Name: %s\n", server.Name))
-			output.WriteString(fmt.Sprintf("PID: %d\n", server.PID))
-			output.WriteString(fmt.Sprintf("Status: %s\n", server.Status))
-			output.WriteString(fmt.Sprintf("Started: %s\n", server.StartTime.Format(time.RFC3339)))
+			output.WriteString(fmt.Sprintf("PID: %d\n", state.PID))
+			output.WriteString(fmt.Sprintf("Status: %s\n", state.Status))
+			if health != "" {
+				output.WriteString(fmt.Sprintf("Health: %s\n", health))
+			}
+			if restarts > 0 {
+				output.WriteString(fmt.Sprintf("Restarts: %d\n", restarts))
+			}
+			if len(ports) > 0 {
+				output.WriteString(fmt.Sprintf("Ports: %s\n", formatPorts(ports)))
+			}
+			output.WriteString(fmt.Sprintf("Started: %s\n", state.StartTime.Format(time.RFC3339)))
 			output.WriteString("\n")
 		}
 
@@ -159,16 +440,22 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, serverList, nil
 	})
-	count++
 
 	// go_server_logs tool
-	resources.RegisterTool("go_server_logs", "Get logs from a running server. Returns recent logs or all logs if count is 0.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_server_logs",
-		Description: "Get logs from a running server. Returns recent logs or all logs if count is 0.",
+		Description: "Get logs from a server. Returns recent logs or all logs if count is 0, optionally filtered by stream, time, text, level or JSON fields. Pass the returned cursor as since_cursor to fetch only new lines.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
-		ID    string `json:"id" jsonschema:"required"`
-		Count int    `json:"count,omitempty"`
+		ID          string            `json:"id" jsonschema:"required"`
+		Count       int               `json:"count,omitempty"`
+		Stream      string            `json:"stream,omitempty"`
+		Since       string            `json:"since,omitempty"`
+		Until       string            `json:"until,omitempty"`
+		Pattern     string            `json:"pattern,omitempty"`
+		Contains    string            `json:"contains,omitempty"`
+		Level       string            `json:"level,omitempty"`
+		Fields      map[string]string `json:"fields,omitempty"`
+		SinceCursor int64             `json:"since_cursor,omitempty"`
 	}) (*mcp.CallToolResult, any, error) {
 		if serverManager == nil {
 			return &mcp.CallToolResult{
@@ -179,7 +466,47 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
-		logs, err := serverManager.GetServerLogs(args.ID, args.Count)
+		filter := utils.LogFilter{
+			Stream:   args.Stream,
+			Pattern:  args.Pattern,
+			Contains: args.Contains,
+			Level:    args.Level,
+			Fields:   args.Fields,
+			AfterSeq: args.SinceCursor,
+			Limit:    args.Count,
+		}
+		if args.Since != "" {
+			t, err := parseLogTime(args.Since)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid since: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			filter.Since = t
+		}
+		if args.Until != "" {
+			t, err := parseLogTime(args.Until)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid until: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			filter.Until = t
+		}
+
+		result, err := serverManager.QueryServerLogs(args.ID, filter)
+		if err != nil && cfg.ServerLogDir != "" {
+			// Servers that are gone may still have logs on disk
+			if _, getErr := serverManager.GetServer(args.ID); getErr != nil {
+				result, err = utils.QueryServerLogFiles(cfg, args.ID, filter)
+			}
+		}
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
@@ -189,20 +516,104 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
-		output := fmt.Sprintf("Logs for server %s (%d lines):\n\n%s", args.ID, len(logs), strings.Join(logs, "\n"))
+		var output strings.Builder
+		output.WriteString(fmt.Sprintf("Logs for server %s (%d lines", args.ID, len(result.Records)))
+		if result.Source == utils.LogSourceDisk {
+			output.WriteString(" from disk")
+		}
+		if result.Truncated {
+			output.WriteString(fmt.Sprintf(" of %d matching", result.Matched))
+		}
+		output.WriteString("):\n\n")
+		for _, record := range result.Records {
+			output.WriteString(record.String())
+			output.WriteString("\n")
+		}
+		if result.Dropped > 0 {
+			output.WriteString(fmt.Sprintf("\n%d lines after the cursor were dropped from the log buffer\n", result.Dropped))
+		}
+		output.WriteString(fmt.Sprintf("\nNext cursor: %d\n", result.NextCursor))
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: output.String()},
 			},
-		}, logs, nil
+		}, result, nil
+	})
+
+	// go_server_logs_cleanup tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_server_logs_cleanup",
+		Description: "Delete on-disk server log files older than a given age (default: the configured retention). Logs of running servers are kept.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID        string `json:"id,omitempty"`
+		OlderThan string `json:"older_than,omitempty"`
+		DryRun    bool   `json:"dry_run,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+		if cfg.ServerLogDir == "" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server log files are disabled. Set SERVER_LOG_DIR to enable them."},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		olderThan := cfg.ServerLogRetention
+		if args.OlderThan != "" {
+			d, err := time.ParseDuration(args.OlderThan)
+			if err != nil || d < 0 {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid older_than: %s", args.OlderThan)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			olderThan = d
+		}
+
+		removed, err := serverManager.PurgeServerLogs(cfg, args.ID, time.Now().Add(-olderThan), args.DryRun)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error cleaning up server logs: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		var freed int64
+		var output strings.Builder
+		verb := "Removed"
+		if args.DryRun {
+			verb = "Would remove"
+		}
+		for _, file := range removed {
+			freed += file.Size
+		}
+		output.WriteString(fmt.Sprintf("%s %d log files older than %s (%d bytes)\n", verb, len(removed), olderThan, freed))
+		for _, file := range removed {
+			output.WriteString(fmt.Sprintf("  %s (%s, %d bytes)\n", file.Path, file.ModTime.Format(time.RFC3339), file.Size))
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output.String()},
+			},
+		}, map[string]interface{}{"removed": removed, "bytes": freed, "dry_run": args.DryRun}, nil
 	})
-	count++
 
 	// go_server_status tool
-	resources.RegisterTool("go_server_status", "Get detailed status of a server including PID, uptime, and logs.", nil)
-	mcp.AddTool(server, &mcp.Tool{
+	count += resources.AddTool(server, cfg, &mcp.Tool{
 		Name:        "go_server_status",
-		Description: "Get detailed status of a server including PID, uptime, and logs.",
+		Description: "Get detailed status of a server including PID, uptime, health, restarts and resource usage (CPU, memory, file descriptors, threads, listening ports).",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
 		ID string `json:"id" jsonschema:"required"`
 	}) (*mcp.CallToolResult, any, error) {
@@ -225,30 +636,116 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			}, nil, nil
 		}
 
-		uptime := time.Since(serverInfo.StartTime)
+		state := serverInfo.ProcessState()
+		uptime := time.Since(state.StartTime)
 		output := "Server Status:\n"
 		output += fmt.Sprintf("ID: %s\n", serverInfo.ID)
 		output += fmt.Sprintf("Name: %s\n", serverInfo.Name)
-		output += fmt.Sprintf("PID: %d\n", serverInfo.PID)
-		output += fmt.Sprintf("Status: %s\n", serverInfo.Status)
+		output += fmt.Sprintf("PID: %d\n", state.PID)
+		output += fmt.Sprintf("Status: %s\n", state.Status)
 		output += fmt.Sprintf("Uptime: %v\n", uptime)
 		output += fmt.Sprintf("Command: %s %v\n", serverInfo.Command, serverInfo.Args)
-		if serverInfo.ExitCode != nil {
-			output += fmt.Sprintf("Exit Code: %d\n", *serverInfo.ExitCode)
+		ports := serverInfo.Ports()
+		if len(ports) > 0 {
+			output += fmt.Sprintf("Ports: %s\n", formatPorts(ports))
+		}
+		if state.ExitCode != nil {
+			output += fmt.Sprintf("Exit Code: %d\n", *state.ExitCode)
+		}
+		health, healthErr := serverInfo.HealthState()
+		if health != "" {
+			output += fmt.Sprintf("Health: %s\n", health)
+			if healthErr != "" {
+				output += fmt.Sprintf("Last Probe Error: %s\n", healthErr)
+			}
+		}
+		restarts, exitHistory := serverInfo.RestartState()
+		output += fmt.Sprintf("Restart Policy: %s (restarts: %d)\n", serverInfo.RestartPolicy, restarts)
+		if len(exitHistory) > 0 {
+			output += "Recent Exits:\n"
+			for _, exit := range exitHistory {
+				code := "n/a"
+				if exit.ExitCode != nil {
+					code = fmt.Sprintf("%d", *exit.ExitCode)
+				}
+				output += fmt.Sprintf("  %s exit code %s after %s", exit.Time.Format(time.RFC3339), code, exit.Uptime)
+				if exit.Error != "" {
+					output += fmt.Sprintf(" (%s)", exit.Error)
+				}
+				output += "\n"
+			}
+		}
+
+		usage := serverInfo.ResourceUsage()
+		if usage != nil && usage.Latest != nil {
+			latest := usage.Latest
+			output += fmt.Sprintf("CPU: %.1f%% (avg %.1f%%)\n", latest.CPUPercent, usage.AvgCPUPercent)
+			output += fmt.Sprintf("Memory (RSS): %s (peak %s)\n", formatBytes(latest.RSSBytes), formatBytes(usage.PeakRSSBytes))
+			output += fmt.Sprintf("Processes: %d, Threads: %d, Open FDs: %d\n", latest.Processes, latest.Threads, latest.OpenFDs)
+			if len(latest.ListenPorts) > 0 {
+				output += fmt.Sprintf("Listening Ports: %v\n", latest.ListenPorts)
+			}
+			if len(usage.Samples) > 1 {
+				first := usage.Samples[0]
+				output += fmt.Sprintf("Memory Trend: %s -> %s over %s\n", formatBytes(first.RSSBytes), formatBytes(latest.RSSBytes), latest.Time.Sub(first.Time).Round(time.Second))
+			}
+			if usage.MemoryGrowing {
+				output += "Warning: memory usage has been growing steadily\n"
+			}
+		} else if usage != nil && usage.Error != "" {
+			output += fmt.Sprintf("Resource Metrics: %s\n", usage.Error)
+		}
+
+		lastStop := serverInfo.LastStop()
+		if lastStop != nil {
+			output += fmt.Sprintf("Last Stop: %s at %s after %s\n", lastStop.Outcome, lastStop.Time.Format(time.RFC3339), lastStop.Duration)
+		}
+		build := serverInfo.BuildState()
+		if build != nil {
+			result := "succeeded"
+			if !build.Success {
+				result = "failed"
+			}
+			output += fmt.Sprintf("Last Build: %s at %s (%s)\n", result, build.Time.Format(time.RFC3339), build.Duration)
+			if build.Command != "" {
+				output += fmt.Sprintf("Build Command: %s\n", build.Command)
+			}
+			if build.Output != "" {
+				output += fmt.Sprintf("Build Output:\n%s\n", build.Output)
+			}
 		}
 
 		statusData := map[string]interface{}{
 			"id":         serverInfo.ID,
 			"name":       serverInfo.Name,
-			"pid":        serverInfo.PID,
-			"status":     serverInfo.Status,
+			"pid":        state.PID,
+			"status":     state.Status,
 			"uptime":     uptime.String(),
-			"start_time": serverInfo.StartTime.Format(time.RFC3339),
+			"start_time": state.StartTime.Format(time.RFC3339),
 			"command":    serverInfo.Command,
 			"args":       serverInfo.Args,
 		}
-		if serverInfo.ExitCode != nil {
-			statusData["exit_code"] = *serverInfo.ExitCode
+		if state.ExitCode != nil {
+			statusData["exit_code"] = *state.ExitCode
+		}
+		if len(ports) > 0 {
+			statusData["ports"] = ports
+		}
+		if health != "" {
+			statusData["health"] = health
+			statusData["health_error"] = healthErr
+		}
+		statusData["restart_policy"] = serverInfo.RestartPolicy
+		statusData["restarts"] = restarts
+		statusData["exit_history"] = exitHistory
+		if build != nil {
+			statusData["last_build"] = build
+		}
+		if lastStop != nil {
+			statusData["last_stop"] = lastStop
+		}
+		if usage != nil {
+			statusData["resources"] = usage
 		}
 
 		return &mcp.CallToolResult{
@@ -257,6 +754,452 @@ func RegisterServerTools(server *mcp.Server, cfg *config.Config) int {
 			},
 		}, statusData, nil
 	})
-	count++
+
+	// go_server_http tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_server_http",
+		Description: "Send an HTTP request to a managed server over loopback, using its allocated port unless a port is given. Returns the status, headers, timing, a truncated body and the server log lines written during the request.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID      string            `json:"id" jsonschema:"required"`
+		Method  string            `json:"method,omitempty"`
+		Path    string            `json:"path,omitempty"`
+		Port    string            `json:"port,omitempty"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    string            `json:"body,omitempty"`
+		Timeout string            `json:"timeout,omitempty"`
+		MaxBody int               `json:"max_body,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		httpReq := utils.HTTPRequest{
+			Method:  args.Method,
+			Path:    args.Path,
+			Port:    args.Port,
+			Headers: args.Headers,
+			Body:    args.Body,
+			MaxBody: args.MaxBody,
+		}
+		if args.Timeout != "" {
+			timeout, err := time.ParseDuration(args.Timeout)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid timeout: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			httpReq.Timeout = timeout
+		}
+
+		resp, err := serverManager.SendHTTP(ctx, args.ID, httpReq)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		method := strings.ToUpper(args.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+		var output strings.Builder
+		output.WriteString(fmt.Sprintf("%s %s\n", method, resp.URL))
+		output.WriteString(fmt.Sprintf("Status: %d %s (%s)\n", resp.Status, http.StatusText(resp.Status), resp.Duration))
+		if len(resp.Headers) > 0 {
+			names := make([]string, 0, len(resp.Headers))
+			for name := range resp.Headers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			output.WriteString("Headers:\n")
+			for _, name := range names {
+				for _, value := range resp.Headers[name] {
+					output.WriteString(fmt.Sprintf("  %s: %s\n", name, value))
+				}
+			}
+		}
+		if resp.Truncated {
+			output.WriteString(fmt.Sprintf("Body (%d bytes, truncated to %d):\n", resp.BodySize, len(resp.Body)))
+		} else {
+			output.WriteString(fmt.Sprintf("Body (%d bytes):\n", resp.BodySize))
+		}
+		output.WriteString(resp.Body)
+		if resp.Body != "" && !strings.HasSuffix(resp.Body, "\n") {
+			output.WriteString("\n")
+		}
+		if len(resp.Logs) > 0 {
+			output.WriteString("\nServer logs during request:\n")
+			for _, line := range resp.Logs {
+				output.WriteString(line.String() + "\n")
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output.String()},
+			},
+		}, resp, nil
+	})
+
+	// go_server_pprof tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_server_pprof",
+		Description: "Capture a CPU, heap, allocs, goroutine, block or mutex profile from a managed server that serves net/http/pprof, store it as a file and summarize it with go tool pprof. Set diff_base to a previous profile, or to \"previous\", to see the growth between two heap snapshots.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID         string `json:"id" jsonschema:"required"`
+		Profile    string `json:"profile" jsonschema:"required"`
+		Duration   string `json:"duration,omitempty"`
+		Port       string `json:"port,omitempty"`
+		PathPrefix string `json:"path_prefix,omitempty"`
+		Output     string `json:"output,omitempty"`
+		DiffBase   string `json:"diff_base,omitempty"`
+		Top        int    `json:"top,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		serverInfo, err := serverManager.GetServer(args.ID)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		opts := utils.ProfileOptions{
+			Profile:    args.Profile,
+			Port:       args.Port,
+			PathPrefix: args.PathPrefix,
+			Output:     args.Output,
+		}
+		if args.Duration != "" {
+			duration, err := time.ParseDuration(args.Duration)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid duration: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			opts.Duration = duration
+		}
+
+		artifact, err := serverManager.CaptureProfile(ctx, args.ID, opts)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error capturing profile: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		base := args.DiffBase
+		if base == "previous" {
+			previous := serverInfo.PreviousProfile(artifact.Profile, artifact.Path)
+			if previous == nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Profile saved to %s, but there is no previous %s profile of server %s to compare with", artifact.Path, artifact.Profile, args.ID)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			base = previous.Path
+		} else if base != "" && !filepath.IsAbs(base) {
+			base = filepath.Join(cfg.WorkingDirectory, base)
+		}
+
+		output := fmt.Sprintf("Captured %s profile of server %s\n", artifact.Profile, args.ID)
+		if artifact.Duration != "" {
+			output += fmt.Sprintf("Duration: %s\n", artifact.Duration)
+		}
+		output += fmt.Sprintf("Saved to: %s (%d bytes)\n", artifact.Path, artifact.Size)
+		if base != "" {
+			output += fmt.Sprintf("Compared with: %s\n", base)
+		}
+
+		summary, err := utils.SummarizeProfile(ctx, cfg, artifact.Path, base, args.Top)
+		if err != nil {
+			output += fmt.Sprintf("\nSummary unavailable: %v\n", err)
+		} else {
+			output += "\n" + summary
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, artifact, nil
+	})
+
+	// go_server_send_input tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_server_send_input",
+		Description: "Write text to the standard input of a running server, for servers that read commands from stdin. A newline is appended unless raw is set. Set close to close stdin afterwards so the server reads end of file. Fails if the server does not read the input within 10 seconds.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID    string `json:"id" jsonschema:"required"`
+		Input string `json:"input" jsonschema:"required"`
+		Raw   bool   `json:"raw,omitempty"`
+		Close bool   `json:"close,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		input := args.Input
+		if !args.Raw && !strings.HasSuffix(input, "\n") {
+			input += "\n"
+		}
+		n, err := serverManager.SendInput(ctx, args.ID, input, args.Close)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error sending input: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		output := fmt.Sprintf("Wrote %d bytes to standard input of server %s", n, args.ID)
+		if args.Close {
+			output += " and closed it"
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, nil, nil
+	})
+
+	// go_server_signal tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_server_signal",
+		Description: "Send a signal such as SIGHUP, SIGUSR1 or SIGQUIT to a running server and its process group (Unix only). After SIGQUIT, or with capture_dump, the goroutine dump the server writes to stderr is collected and summarized by state and stack. Note that SIGQUIT makes Go programs exit after the dump.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID          string `json:"id" jsonschema:"required"`
+		Signal      string `json:"signal" jsonschema:"required"`
+		ProcessOnly bool   `json:"process_only,omitempty"`
+		CaptureDump bool   `json:"capture_dump,omitempty"`
+		Wait        string `json:"wait,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		opts := utils.SignalOptions{ProcessOnly: args.ProcessOnly, CaptureDump: args.CaptureDump}
+		if args.Wait != "" {
+			wait, err := time.ParseDuration(args.Wait)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Invalid wait: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			opts.DumpWait = wait
+		}
+
+		result, err := serverManager.SignalServer(args.ID, args.Signal, opts)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error sending signal: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		output := fmt.Sprintf("Sent %s to the %s of server %s\n", result.Signal, result.Target, args.ID)
+		switch {
+		case result.Dump != nil:
+			output += "\n" + formatGoroutineSummary(result.Dump)
+		case len(result.DumpLines) > 0:
+			output += fmt.Sprintf("\nNo goroutine dump found in %d stderr lines\n", len(result.DumpLines))
+		case result.Signal == "SIGQUIT" || args.CaptureDump:
+			output += "\nNo goroutine dump was written to stderr\n"
+		}
+
+		// The raw dump can be read from the server's stderr log
+		result.DumpLines = nil
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, result, nil
+	})
+
+	// go_server_remove tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_server_remove",
+		Description: "Remove a stopped server from the registry and the state file, releasing its ports. Running servers must be stopped first, or set stop to stop them before removal.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		ID   string `json:"id" jsonschema:"required"`
+		Stop bool   `json:"stop,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		output := ""
+		if args.Stop {
+			serverInfo, err := serverManager.GetServer(args.ID)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Error removing server: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			if serverInfo.IsRunning() {
+				result, err := serverManager.StopServerWithOptions(args.ID, utils.StopOptions{})
+				if err != nil {
+					return &mcp.CallToolResult{
+						Content: []mcp.Content{
+							&mcp.TextContent{Text: fmt.Sprintf("Error stopping server: %v", err)},
+						},
+						IsError: true,
+					}, nil, nil
+				}
+				output = fmt.Sprintf("Server %s stopped (%s) after %s\n", args.ID, result.Outcome, result.Duration)
+			}
+		}
+
+		if err := serverManager.RemoveServer(args.ID); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error removing server: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		output += fmt.Sprintf("Server %s removed", args.ID)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, nil, nil
+	})
+
 	return count
 }
+
+// parseLogTime parses an RFC 3339 time, or a duration meaning that long ago
+func parseLogTime(value string) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC 3339 time or a duration such as 5m")
+	}
+	return t, nil
+}
+
+// formatPorts formats allocated ports as name=port pairs sorted by name
+func formatPorts(ports map[string]int) string {
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%d", name, ports[name])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// maxGoroutineGroups bounds the goroutine groups listed for a dump
+const maxGoroutineGroups = 10
+
+// formatGoroutineSummary formats a goroutine dump summary with the largest
+// groups of goroutines and their stacks
+func formatGoroutineSummary(summary *utils.GoroutineSummary) string {
+	states := make([]string, 0, len(summary.ByState))
+	for state := range summary.ByState {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if summary.ByState[states[i]] != summary.ByState[states[j]] {
+			return summary.ByState[states[i]] > summary.ByState[states[j]]
+		}
+		return states[i] < states[j]
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Goroutines: %d\n", summary.Total)
+	for _, state := range states {
+		fmt.Fprintf(&sb, "  %s: %d\n", state, summary.ByState[state])
+	}
+	for i, group := range summary.Groups {
+		if i == maxGoroutineGroups {
+			fmt.Fprintf(&sb, "\n... %d more groups\n", len(summary.Groups)-maxGoroutineGroups)
+			break
+		}
+		fmt.Fprintf(&sb, "\n%d goroutines: %s", group.Count, group.State)
+		if group.MaxWait > 0 {
+			fmt.Fprintf(&sb, ", up to %d minutes", group.MaxWait)
+		}
+		sb.WriteString("\n")
+		for _, frame := range group.Stack {
+			fmt.Fprintf(&sb, "  %s\n", frame)
+		}
+		if group.CreatedBy != "" {
+			fmt.Fprintf(&sb, "  created by %s\n", group.CreatedBy)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServerToolsStructuredOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	InitServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	session := connectTools(t, cfg, RegisterServerTools)
+	ctx := context.Background()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "go_server_start", Arguments: map[string]any{
+		"id":      "structured",
+		"name":    "structured",
+		"command": "sh",
+		"args":    []string{"-c", "sleep 30"},
+	}})
+	if err != nil {
+		t.Fatalf("go_server_start failed: %v", err)
+	}
+	defer func() { _ = serverManager.StopServer("structured", true) }()
+	if result.IsError {
+		t.Fatalf("go_server_start returned an error: %+v", result.Content)
+	}
+	data, ok := result.StructuredContent.(map[string]any)
+	if !ok || data["id"] != "structured" || data["status"] != "running" || data["pid"] == float64(0) {
+		t.Errorf("structured content = %#v", result.StructuredContent)
+	}
+
+	for _, name := range []string{"go_server_status", "go_server_list"} {
+		args := map[string]any{}
+		if name == "go_server_status" {
+			args["id"] = "structured"
+		}
+		callToolText(t, session, name, args, false)
+	}
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"github.com/inja-online/golang-mcp/internal/resources"
+	"github.com/inja-online/golang-mcp/internal/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RegisterStackTools registers tools that manage stacks of servers
+func RegisterStackTools(server *mcp.Server, cfg *config.Config) int {
+	count := 0
+
+	// go_stack_up tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_stack_up",
+		Description: "Start the servers of a stack file in dependency order, waiting for each to become healthy. Free ports are allocated and passed to every server as environment variables.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		File string `json:"file,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		path := args.File
+		if path == "" {
+			path = utils.StackFileName
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.WorkingDirectory, path)
+		}
+		if err := cfg.CheckWorkingDir(filepath.Dir(path)); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		def, err := utils.LoadStack(path)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error loading stack: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		status, err := serverManager.StackUp(ctx, cfg, def)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error starting stack %s: %v", def.Name, err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		output := fmt.Sprintf("Stack %s started\n\n", status.Name) + formatStackStatus(status)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, status, nil
+	})
+
+	// go_stack_down tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_stack_down",
+		Description: "Stop the servers of a stack in reverse dependency order.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Name string `json:"name" jsonschema:"required"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		status, err := serverManager.StackDown(args.Name)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Error stopping stack: %v", err)},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		output := fmt.Sprintf("Stack %s stopped\n\n", status.Name) + formatStackStatus(status)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+			},
+		}, status, nil
+	})
+
+	// go_stack_status tool
+	count += resources.AddTool(server, cfg, &mcp.Tool{
+		Name:        "go_stack_status",
+		Description: "Get the status of a stack's servers, or of every running stack if no name is given.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Name string `json:"name,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		if serverManager == nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Server manager not initialized"},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+
+		var stacks []*utils.StackStatus
+		if args.Name != "" {
+			status, err := serverManager.StackStatus(args.Name)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+					},
+					IsError: true,
+				}, nil, nil
+			}
+			stacks = append(stacks, status)
+		} else {
+			stacks = serverManager.ListStacks()
+		}
+
+		if len(stacks) == 0 {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "No stacks running"},
+				},
+			}, stacks, nil
+		}
+
+		var output strings.Builder
+		for i, status := range stacks {
+			if i > 0 {
+				output.WriteString("\n")
+			}
+			output.WriteString(formatStackStatus(status))
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output.String()},
+			},
+		}, stacks, nil
+	})
+
+	return count
+}
+
+// formatStackStatus formats a stack with its allocated ports and services
+func formatStackStatus(status *utils.StackStatus) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Stack: %s\n", status.Name))
+	output.WriteString(fmt.Sprintf("File: %s\n", status.File))
+	output.WriteString(fmt.Sprintf("Started: %s\n", status.Started.Format(time.RFC3339)))
+
+	if len(status.Ports) > 0 {
+		names := make([]string, 0, len(status.Ports))
+		for name := range status.Ports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		output.WriteString("Ports:\n")
+		for _, name := range names {
+			output.WriteString(fmt.Sprintf("  %s=%d\n", name, status.Ports[name]))
+		}
+	}
+
+	output.WriteString("Services:\n")
+	for _, svc := range status.Services {
+		output.WriteString(fmt.Sprintf("  %s (%s): %s", svc.Service, svc.ServerID, svc.Status))
+		if svc.Health != "" {
+			output.WriteString(fmt.Sprintf(", %s", svc.Health))
+		}
+		if svc.PID != 0 {
+			output.WriteString(fmt.Sprintf(", PID %d", svc.PID))
+		}
+		if len(svc.DependsOn) > 0 {
+			output.WriteString(fmt.Sprintf(", depends on %s", strings.Join(svc.DependsOn, ", ")))
+		}
+		if svc.Stopped != "" {
+			output.WriteString(fmt.Sprintf(", stopped: %s", svc.Stopped))
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// Audit entry kinds
+const (
+	AuditKindToolCall    = "tool_call"
+	AuditKindCommand     = "command"
+	AuditKindServerStart = "server_start"
+)
+
+// Permission decisions recorded in audit entries
+const (
+	PermissionAllowed     = "allowed"
+	PermissionDenied      = "denied"
+	PermissionNotRequired = "not_required"
+	PermissionRejected    = "rejected"
+)
+
+// AuditEntry is a single record in the audit log.
+type AuditEntry struct {
+	Time         time.Time         `json:"time"`
+	Kind         string            `json:"kind"`
+	Tool         string            `json:"tool,omitempty"`
+	Arguments    interface{}       `json:"arguments,omitempty"`
+	Command      string            `json:"command,omitempty"`
+	Args         []string          `json:"args,omitempty"`
+	WorkingDir   string            `json:"working_dir,omitempty"`
+	EnvOverrides map[string]string `json:"env_overrides,omitempty"`
+	Permission   string            `json:"permission,omitempty"`
+	ExitCode     *int              `json:"exit_code,omitempty"`
+	DurationMS   int64             `json:"duration_ms"`
+	StdoutBytes  int               `json:"stdout_bytes,omitempty"`
+	StderrBytes  int               `json:"stderr_bytes,omitempty"`
+	OutputBytes  int               `json:"output_bytes,omitempty"`
+	IsError      bool              `json:"is_error,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// AuditLogger appends audit entries as JSON lines to a size-rotated file.
+type AuditLogger struct {
+	path string
+	file *rotatingFile
+	mu   sync.Mutex
+}
+
+var (
+	auditLogger   *AuditLogger
+	auditLoggerMu sync.RWMutex
+)
+
+// NewAuditLogger opens an audit log at path. The file is rotated when it
+// exceeds maxSize bytes, keeping maxBackups rotated files.
+func NewAuditLogger(path string, maxSize int64, maxBackups int) (*AuditLogger, error) {
+	file, err := openRotatingFile(path, maxSize, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLogger{path: path, file: file}, nil
+}
+
+// InitAuditLogger installs the process-wide audit logger from configuration.
+// Auditing stays disabled when no audit log path is configured.
+func InitAuditLogger(cfg *config.Config) error {
+	if cfg.AuditLogPath == "" {
+		return nil
+	}
+	logger, err := NewAuditLogger(cfg.AuditLogPath, cfg.AuditLogMaxSize, cfg.AuditLogMaxBackups)
+	if err != nil {
+		return err
+	}
+	SetAuditLogger(logger)
+	return nil
+}
+
+// SetAuditLogger sets the process-wide audit logger. Passing nil disables auditing.
+func SetAuditLogger(logger *AuditLogger) {
+	auditLoggerMu.Lock()
+	defer auditLoggerMu.Unlock()
+	auditLogger = logger
+}
+
+// GetAuditLogger returns the process-wide audit logger, or nil when auditing is disabled.
+func GetAuditLogger() *AuditLogger {
+	auditLoggerMu.RLock()
+	defer auditLoggerMu.RUnlock()
+	return auditLogger
+}
+
+// RecordAudit writes an entry to the process-wide audit logger, if any.
+func RecordAudit(entry AuditEntry) {
+	logger := GetAuditLogger()
+	if logger == nil {
+		return
+	}
+	if err := logger.Record(entry); err != nil {
+		log.Printf("Failed to write audit log entry: %v", err)
+	}
+}
+
+// Path returns the path of the active audit log file.
+func (al *AuditLogger) Path() string {
+	return al.path
+}
+
+// Record appends an entry to the audit log.
+func (al *AuditLogger) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	_, err = al.file.Write(data)
+	return err
+}
+
+// Recent returns up to n of the most recent entries, oldest first. Entries
+// are read from the active file and, if needed, the most recent backup.
+func (al *AuditLogger) Recent(n int) ([]AuditEntry, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	entries, err := readAuditFile(al.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) < n {
+		previous, err := readAuditFile(backupPath(al.path, 1))
+		if err == nil {
+			entries = append(previous, entries...)
+		}
+	}
+	if n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, nil
+}
+
+// Close closes the audit log file.
+func (al *AuditLogger) Close() error {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return al.file.Close()
+}
+
+// readAuditFile reads all well-formed entries from a JSON-lines audit file
+func readAuditFile(path string) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+type toolNameKey struct{}
+
+// WithToolName returns a context carrying the name of the tool being executed,
+// so that commands run on its behalf can be attributed in the audit log.
+func WithToolName(ctx context.Context, tool string) context.Context {
+	return context.WithValue(ctx, toolNameKey{}, tool)
+}
+
+// ToolNameFromContext returns the tool name stored by WithToolName.
+func ToolNameFromContext(ctx context.Context) string {
+	tool, _ := ctx.Value(toolNameKey{}).(string)
+	return tool
+}
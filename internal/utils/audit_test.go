@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogger_RecordAndRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := NewAuditLogger(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create audit logger: %v", err)
+	}
+	defer logger.Close()
+
+	for _, tool := range []string{"go_build", "go_test", "go_vet"} {
+		if err := logger.Record(AuditEntry{Kind: AuditKindToolCall, Tool: tool}); err != nil {
+			t.Fatalf("Failed to record entry: %v", err)
+		}
+	}
+
+	entries, err := logger.Recent(2)
+	if err != nil {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Tool != "go_test" || entries[1].Tool != "go_vet" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("Expected entry time to be set")
+	}
+}
+
+func TestAuditLogger_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := NewAuditLogger(path, 200, 2)
+	if err != nil {
+		t.Fatalf("Failed to create audit logger: %v", err)
+	}
+	defer logger.Close()
+
+	for i := 0; i < 20; i++ {
+		if err := logger.Record(AuditEntry{Kind: AuditKindCommand, Command: "go"}); err != nil {
+			t.Fatalf("Failed to record entry: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(backupPath(path, 1)); err != nil {
+		t.Errorf("Expected first backup to exist: %v", err)
+	}
+	if _, err := os.Stat(backupPath(path, 2)); err != nil {
+		t.Errorf("Expected second backup to exist: %v", err)
+	}
+	if _, err := os.Stat(backupPath(path, 3)); !os.IsNotExist(err) {
+		t.Error("Expected at most 2 backups to be kept")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected active log file: %v", err)
+	}
+	if info.Size() > 200 {
+		t.Errorf("Expected active file to stay under max size, got %d bytes", info.Size())
+	}
+}
+
+func TestExecuteGoCommand_Audited(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("Go command not available")
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := NewAuditLogger(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create audit logger: %v", err)
+	}
+	SetAuditLogger(logger)
+	defer SetAuditLogger(nil)
+
+	cfg := createTestConfig(t, true)
+	ctx := WithToolName(context.Background(), "go_version")
+	if _, err := ExecuteGoCommand(ctx, cfg, "go", []string{"version"}, "", map[string]string{"API_TOKEN": "abc123"}); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	_, _ = ExecuteGoCommand(ctx, cfg, "go", []string{"version;", "rm"}, "", nil)
+
+	entries, err := logger.Recent(10)
+	if err != nil {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	ran := entries[0]
+	if ran.Tool != "go_version" || ran.Kind != AuditKindCommand {
+		t.Errorf("Unexpected entry: %+v", ran)
+	}
+	if ran.ExitCode == nil || *ran.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %v", ran.ExitCode)
+	}
+	if ran.StdoutBytes == 0 {
+		t.Error("Expected stdout size to be recorded")
+	}
+	if ran.Permission != PermissionNotRequired {
+		t.Errorf("Expected permission %q, got %q", PermissionNotRequired, ran.Permission)
+	}
+	if ran.EnvOverrides["API_TOKEN"] != RedactedValue {
+		t.Errorf("Expected env override to be redacted, got %q", ran.EnvOverrides["API_TOKEN"])
+	}
+	if !strings.HasSuffix(ran.Command, "go") {
+		t.Errorf("Expected resolved go command, got %q", ran.Command)
+	}
+
+	if entries[1].Permission != PermissionRejected {
+		t.Errorf("Expected rejected command to be recorded, got %+v", entries[1])
+	}
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// essentialEnvVars are inherited even under an allowlist so child processes
+// can still locate binaries, home and temp directories.
+var essentialEnvVars = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "TZ", "LANG", "LC_*",
+	"TMPDIR", "TMP", "TEMP", "XDG_CACHE_HOME", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "SSH_AUTH_SOCK",
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// goEnvVars are the variables reported by go env and the ones read by the Go
+// runtime, race detector and coverage tooling of the programs it builds.
+var goEnvVars = map[string]bool{
+	"AR": true, "CC": true, "CXX": true, "FC": true, "GCCGO": true, "PKG_CONFIG": true,
+	"CGO_CFLAGS": true, "CGO_CPPFLAGS": true, "CGO_CXXFLAGS": true, "CGO_ENABLED": true,
+	"CGO_FFLAGS": true, "CGO_LDFLAGS": true,
+	"GO111MODULE": true, "GO386": true, "GOAMD64": true, "GOARCH": true, "GOARM": true,
+	"GOARM64": true, "GOAUTH": true, "GOBIN": true, "GOCACHE": true, "GOCACHEPROG": true,
+	"GODEBUG": true, "GOENV": true, "GOEXE": true, "GOEXPERIMENT": true, "GOFIPS140": true,
+	"GOFLAGS": true, "GOGCCFLAGS": true, "GOHOSTARCH": true, "GOHOSTOS": true,
+	"GOINSECURE": true, "GOMIPS": true, "GOMIPS64": true, "GOMOD": true, "GOMODCACHE": true,
+	"GONOPROXY": true, "GONOSUMDB": true, "GOOS": true, "GOPATH": true, "GOPPC64": true,
+	"GOPRIVATE": true, "GOPROXY": true, "GORISCV64": true, "GOROOT": true, "GOSUMDB": true,
+	"GOTELEMETRY": true, "GOTELEMETRYDIR": true, "GOTMPDIR": true, "GOTOOLCHAIN": true,
+	"GOTOOLDIR": true, "GOVCS": true, "GOVERSION": true, "GOWASM": true, "GOWORK": true,
+	"GOGC": true, "GOMAXPROCS": true, "GOMEMLIMIT": true, "GOTRACEBACK": true, "GORACE": true,
+	"GOCOVERDIR": true,
+}
+
+// blockedEnvVars may never be set by callers because they change how the
+// dynamic loader behaves in the child process.
+var blockedEnvVars = []string{"LD_PRELOAD", "LD_LIBRARY_PATH", "LD_AUDIT", "DYLD_*"}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// BuildEnv builds the environment for a child process: the inherited
+// environment filtered by the configured policy, followed by the Go variables
+// from the configuration and the caller-supplied variables.
+func BuildEnv(cfg *config.Config, envVars map[string]string) ([]string, error) {
+	if err := ValidateEnvVars(cfg, envVars); err != nil {
+		return nil, err
+	}
+
+	env := FilterEnv(cfg, os.Environ())
+	for k, v := range cfg.GetGoEnv() {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	for k, v := range envVars {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	return env, nil
+}
+
+// LookupEnv returns the value of a variable in the environment BuildEnv
+// gives a go command without overrides, and whether it is set.
+func LookupEnv(cfg *config.Config, key string) (string, bool) {
+	env, _ := BuildEnv(cfg, nil) // without overrides there is nothing to validate
+	value, found := "", false
+	// Like os/exec, the last entry for a key wins
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			value, found = v, true
+		}
+	}
+	return value, found
+}
+
+// FilterEnv returns the KEY=value entries of env that the configured policy
+// allows a child process to inherit.
+func FilterEnv(cfg *config.Config, env []string) []string {
+	result := make([]string, 0, len(env))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if key != "" && inheritEnvVar(cfg, key) {
+			result = append(result, kv)
+		}
+	}
+	return result
+}
+
+// inheritEnvVar decides whether an inherited variable is passed on.
+// The denylist wins over everything else, then the allowlist. Other
+// secret-looking names are dropped, even Go or essential ones; remaining Go
+// toolchain and essential variables always pass; with an allowlist nothing
+// else is kept.
+func inheritEnvVar(cfg *config.Config, key string) bool {
+	if matchEnvPattern(cfg.EnvDenylist, key) {
+		return false
+	}
+	if matchEnvPattern(cfg.EnvAllowlist, key) {
+		return true
+	}
+	if IsSecretKey(key) {
+		return false
+	}
+	if isGoEnvVar(cfg, key) || matchEnvPattern(essentialEnvVars, key) {
+		return true
+	}
+	return len(cfg.EnvAllowlist) == 0
+}
+
+// ValidateEnvVars checks caller-supplied environment variables.
+func ValidateEnvVars(cfg *config.Config, envVars map[string]string) error {
+	for k, v := range envVars {
+		if !envKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid environment variable name: %q", k)
+		}
+		if strings.ContainsRune(v, 0) {
+			return fmt.Errorf("environment variable %s contains a NUL byte", k)
+		}
+		if matchEnvPattern(blockedEnvVars, k) {
+			return fmt.Errorf("environment variable %s may not be set", k)
+		}
+		if matchEnvPattern(cfg.EnvDenylist, k) {
+			return fmt.Errorf("environment variable %s is denied by policy", k)
+		}
+	}
+	return nil
+}
+
+// isGoEnvVar reports whether key configures the Go toolchain or the Go
+// runtime.
+func isGoEnvVar(cfg *config.Config, key string) bool {
+	key = strings.ToUpper(key)
+	if goEnvVars[key] {
+		return true
+	}
+	_, ok := cfg.GetGoEnv()[key]
+	return ok
+}
+
+// matchEnvPattern reports whether key matches any of the glob patterns.
+// Matching is case-insensitive.
+func matchEnvPattern(patterns []string, key string) bool {
+	key = strings.ToUpper(key)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(pattern), key); ok {
+			return true
+		}
+	}
+	return false
+}
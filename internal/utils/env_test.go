@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func envKeys(env []string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		keys[key] = true
+	}
+	return keys
+}
+
+func TestFilterEnv(t *testing.T) {
+	env := []string{"PATH=/bin", "HOME=/root", "GOFLAGS=-mod=mod", "GITHUB_TOKEN=x", "AWS_SECRET_ACCESS_KEY=y", "EDITOR=vi", "NPM_CONFIG=z"}
+
+	keys := envKeys(FilterEnv(&config.Config{}, env))
+	for _, key := range []string{"PATH", "HOME", "GOFLAGS", "EDITOR", "NPM_CONFIG"} {
+		if !keys[key] {
+			t.Errorf("Expected %s to be inherited by default", key)
+		}
+	}
+	for _, key := range []string{"GITHUB_TOKEN", "AWS_SECRET_ACCESS_KEY"} {
+		if keys[key] {
+			t.Errorf("Expected %s to be dropped by default", key)
+		}
+	}
+
+	keys = envKeys(FilterEnv(&config.Config{EnvAllowlist: []string{"GITHUB_TOKEN"}, EnvDenylist: []string{"NPM_*"}}, env))
+	if !keys["GITHUB_TOKEN"] || !keys["PATH"] || !keys["GOFLAGS"] {
+		t.Errorf("Expected allowlisted, essential and Go variables, got %v", keys)
+	}
+	if keys["EDITOR"] || keys["NPM_CONFIG"] {
+		t.Errorf("Expected unlisted and denylisted variables to be dropped, got %v", keys)
+	}
+}
+
+func TestFilterEnvGoPrefixedSecrets(t *testing.T) {
+	env := []string{"GOOGLE_API_KEY=k", "GOOGLE_APPLICATION_CREDENTIALS=/creds.json", "GOOGLE_CLOUD_PROJECT=p", "GOPRIVATE=example.com", "GOMAXPROCS=2"}
+
+	keys := envKeys(FilterEnv(&config.Config{}, env))
+	if keys["GOOGLE_API_KEY"] || keys["GOOGLE_APPLICATION_CREDENTIALS"] {
+		t.Errorf("Expected GOOGLE_ secrets to be dropped, got %v", keys)
+	}
+	if !keys["GOOGLE_CLOUD_PROJECT"] || !keys["GOPRIVATE"] || !keys["GOMAXPROCS"] {
+		t.Errorf("Expected other variables to be inherited, got %v", keys)
+	}
+
+	// GOOGLE_ is not a Go variable, so an allowlist drops it
+	keys = envKeys(FilterEnv(&config.Config{EnvAllowlist: []string{"EDITOR"}}, env))
+	if keys["GOOGLE_API_KEY"] || keys["GOOGLE_APPLICATION_CREDENTIALS"] || keys["GOOGLE_CLOUD_PROJECT"] {
+		t.Errorf("Expected GOOGLE_ variables to be dropped under an allowlist, got %v", keys)
+	}
+	if !keys["GOPRIVATE"] || !keys["GOMAXPROCS"] {
+		t.Errorf("Expected Go variables under an allowlist, got %v", keys)
+	}
+
+	keys = envKeys(FilterEnv(&config.Config{EnvAllowlist: []string{"GOOGLE_API_KEY"}}, env))
+	if !keys["GOOGLE_API_KEY"] || keys["GOOGLE_APPLICATION_CREDENTIALS"] {
+		t.Errorf("Expected only the allowlisted secret, got %v", keys)
+	}
+}
+
+func TestFilterEnvAuthVariables(t *testing.T) {
+	env := []string{"SSH_AUTH_SOCK=/tmp/agent.sock", "GOAUTH=git", "NPM_AUTH=x", "EDITOR=vi"}
+
+	for _, cfg := range []*config.Config{{}, {EnvAllowlist: []string{"EDITOR"}}} {
+		keys := envKeys(FilterEnv(cfg, env))
+		if !keys["SSH_AUTH_SOCK"] || !keys["GOAUTH"] {
+			t.Errorf("Expected SSH_AUTH_SOCK and GOAUTH to be inherited with allowlist %v, got %v", cfg.EnvAllowlist, keys)
+		}
+		if keys["NPM_AUTH"] {
+			t.Errorf("Expected NPM_AUTH to be dropped with allowlist %v", cfg.EnvAllowlist)
+		}
+	}
+}
+
+func TestValidateEnvVars(t *testing.T) {
+	cfg := &config.Config{EnvDenylist: []string{"HTTP_PROXY"}}
+
+	valid := map[string]string{"CGO_ENABLED": "0", "APP_PORT": "8080"}
+	if err := ValidateEnvVars(cfg, valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	invalid := []map[string]string{
+		{"1BAD": "x"},
+		{"A=B": "x"},
+		{"LD_PRELOAD": "/tmp/x.so"},
+		{"DYLD_INSERT_LIBRARIES": "/tmp/x.dylib"},
+		{"http_proxy": "http://proxy"},
+		{"OK": "a\x00b"},
+	}
+	for _, envVars := range invalid {
+		if err := ValidateEnvVars(cfg, envVars); err == nil {
+			t.Errorf("Expected error for %v", envVars)
+		}
+	}
+}
+
+func TestBuildEnv(t *testing.T) {
+	t.Setenv("MCP_TEST_API_TOKEN", "should-not-leak")
+	cfg := &config.Config{GoPath: "/tmp/gopath"}
+
+	env, err := BuildEnv(cfg, map[string]string{"APP_MODE": "test"})
+	if err != nil {
+		t.Fatalf("BuildEnv failed: %v", err)
+	}
+	keys := envKeys(env)
+	if keys["MCP_TEST_API_TOKEN"] {
+		t.Error("Expected secret environment variable to be filtered")
+	}
+	if !keys["GOPATH"] || !keys["APP_MODE"] {
+		t.Errorf("Expected GOPATH and APP_MODE in environment, got %v", keys)
+	}
+
+	if _, err := BuildEnv(cfg, map[string]string{"LD_PRELOAD": "x"}); err == nil {
+		t.Error("Expected error for blocked variable")
+	}
+}
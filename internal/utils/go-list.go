@@ -0,0 +1,533 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// Package scopes select the packages that become nodes of a PackageGraph
+const (
+	PackageScopeModule = "module" // packages of the main module(s)
+	PackageScopeDeps   = "deps"   // and their non-standard dependencies
+	PackageScopeAll    = "all"    // and the standard library
+)
+
+// ListOptions configures ListPackages.
+type ListOptions struct {
+	Patterns   []string // package patterns, default ./...
+	Tags       []string
+	Scope      string // default PackageScopeModule
+	WorkingDir string
+}
+
+// GoPackage is a package reported by go list.
+type GoPackage struct {
+	ImportPath     string   `json:"import_path"`
+	Name           string   `json:"name"`
+	Dir            string   `json:"dir"`
+	Module         string   `json:"module,omitempty"`
+	Standard       bool     `json:"standard,omitempty"`
+	DepOnly        bool     `json:"dep_only,omitempty"` // only listed as a dependency of the patterns
+	Imports        []string `json:"imports,omitempty"`
+	TestImports    []string `json:"test_imports,omitempty"`
+	XTestImports   []string `json:"xtest_imports,omitempty"` // imports of the external _test package
+	EmbedPatterns  []string `json:"embed_patterns,omitempty"`
+	EmbedFiles     []string `json:"embed_files,omitempty"`
+	TestEmbedFiles []string `json:"test_embed_files,omitempty"` // embedded by test files of both test packages
+	Error          string   `json:"error,omitempty"`
+
+	main bool
+}
+
+// PackageGraph is the import graph of the listed packages, with the import
+// cycles and rule violations found in the main module.
+type PackageGraph struct {
+	Modules    []string            `json:"modules,omitempty"` // main modules
+	Scope      string              `json:"scope"`
+	Packages   []*GoPackage        `json:"packages"`
+	Edges      map[string][]string `json:"edges"`                // imports between listed packages
+	TestEdges  map[string][]string `json:"test_edges,omitempty"` // test imports between listed packages
+	Cycles     []ImportCycle       `json:"cycles,omitempty"`
+	Violations []RuleViolation     `json:"violations,omitempty"`
+	Errors     []string            `json:"errors,omitempty"`
+}
+
+// ImportCycle is a cycle of imports. Test cycles only exist when the test
+// files of a package are compiled.
+type ImportCycle struct {
+	Path []string `json:"path"` // first package repeated at the end
+	Test bool     `json:"test,omitempty"`
+}
+
+// RuleViolation is an import that breaks a configured layer or rule.
+type RuleViolation struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Rule   string `json:"rule"`
+	Reason string `json:"reason,omitempty"`
+	Test   bool   `json:"test,omitempty"` // imported by a test file
+}
+
+// listPackage is the subset of the go list -json output that is used
+type listPackage struct {
+	ImportPath      string
+	Name            string
+	Dir             string
+	Standard        bool
+	DepOnly         bool
+	Module          *listModule
+	Imports         []string
+	TestImports     []string
+	XTestImports    []string
+	EmbedPatterns   []string
+	EmbedFiles      []string
+	TestEmbedFiles  []string
+	XTestEmbedFiles []string
+	Error           *listError
+	DepsErrors      []*listError
+}
+
+type listModule struct {
+	Path string
+	Main bool
+}
+
+type listError struct {
+	Err string
+}
+
+// ListPackages runs go list -json -deps and returns the import graph of the
+// packages in the requested scope, checked for import cycles and for the
+// package layers and rules of the configuration.
+func ListPackages(ctx context.Context, cfg *config.Config, opts ListOptions) (*PackageGraph, error) {
+	switch opts.Scope {
+	case "":
+		opts.Scope = PackageScopeModule
+	case PackageScopeModule, PackageScopeDeps, PackageScopeAll:
+	default:
+		return nil, fmt.Errorf("invalid scope %q: must be %s, %s or %s", opts.Scope, PackageScopeModule, PackageScopeDeps, PackageScopeAll)
+	}
+	if len(opts.Patterns) == 0 {
+		opts.Patterns = []string{"./..."}
+	}
+
+	args := []string{"list", "-e", "-json", "-deps"}
+	if len(opts.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(opts.Tags, ","))
+	}
+	args = append(args, opts.Patterns...)
+
+	result, err := ExecuteGoCommand(ctx, cfg, "go", args, opts.WorkingDir, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("go list failed: %s", strings.TrimSpace(result.Stderr))
+	}
+
+	packages, err := decodeListOutput(result.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	return buildPackageGraph(packages, opts.Scope, cfg.PackageLayers, cfg.PackageRules), nil
+}
+
+// decodeListOutput decodes the stream of JSON objects printed by go list
+func decodeListOutput(output string) ([]*GoPackage, error) {
+	var packages []*GoPackage
+	decoder := json.NewDecoder(strings.NewReader(output))
+	for {
+		var lp listPackage
+		if err := decoder.Decode(&lp); errors.Is(err, io.EOF) {
+			return packages, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+
+		pkg := &GoPackage{
+			ImportPath:     lp.ImportPath,
+			Name:           lp.Name,
+			Dir:            lp.Dir,
+			Standard:       lp.Standard,
+			DepOnly:        lp.DepOnly,
+			Imports:        lp.Imports,
+			TestImports:    lp.TestImports,
+			XTestImports:   lp.XTestImports,
+			EmbedPatterns:  lp.EmbedPatterns,
+			EmbedFiles:     lp.EmbedFiles,
+			TestEmbedFiles: append(lp.TestEmbedFiles, lp.XTestEmbedFiles...),
+		}
+		if lp.Module != nil {
+			pkg.Module = lp.Module.Path
+			pkg.main = lp.Module.Main
+		} else {
+			// GOPATH mode: the packages matched by the patterns
+			pkg.main = !lp.Standard && !lp.DepOnly
+		}
+		var errs []string
+		if lp.Error != nil {
+			errs = append(errs, lp.Error.Err)
+		}
+		for _, depErr := range lp.DepsErrors {
+			errs = append(errs, depErr.Err)
+		}
+		pkg.Error = strings.Join(errs, "; ")
+		packages = append(packages, pkg)
+	}
+}
+
+// buildPackageGraph keeps the packages of the scope as nodes and checks the
+// imports of the main module packages
+func buildPackageGraph(all []*GoPackage, scope string, layers []config.PackageLayer, rules []config.PackageRule) *PackageGraph {
+	graph := &PackageGraph{
+		Scope:     scope,
+		Packages:  []*GoPackage{},
+		Edges:     make(map[string][]string),
+		TestEdges: make(map[string][]string),
+	}
+
+	index := make(map[string]*GoPackage)
+	modules := make(map[string]bool)
+	for _, pkg := range all {
+		inScope := pkg.main ||
+			(scope == PackageScopeDeps && !pkg.Standard) ||
+			scope == PackageScopeAll
+		if !inScope {
+			continue
+		}
+		index[pkg.ImportPath] = pkg
+		graph.Packages = append(graph.Packages, pkg)
+		if pkg.main && pkg.Module != "" && !modules[pkg.Module] {
+			modules[pkg.Module] = true
+			graph.Modules = append(graph.Modules, pkg.Module)
+		}
+		if pkg.Error != "" {
+			graph.Errors = append(graph.Errors, fmt.Sprintf("%s: %s", pkg.ImportPath, pkg.Error))
+		}
+	}
+	sort.Slice(graph.Packages, func(i, j int) bool {
+		return graph.Packages[i].ImportPath < graph.Packages[j].ImportPath
+	})
+	sort.Strings(graph.Modules)
+
+	for _, pkg := range graph.Packages {
+		for _, imp := range pkg.Imports {
+			if index[imp] != nil {
+				graph.Edges[pkg.ImportPath] = append(graph.Edges[pkg.ImportPath], imp)
+			}
+		}
+		for _, imp := range append(append([]string{}, pkg.TestImports...), pkg.XTestImports...) {
+			if index[imp] != nil && imp != pkg.ImportPath && !containsString(graph.TestEdges[pkg.ImportPath], imp) && !containsString(graph.Edges[pkg.ImportPath], imp) {
+				graph.TestEdges[pkg.ImportPath] = append(graph.TestEdges[pkg.ImportPath], imp)
+			}
+		}
+	}
+	if len(graph.TestEdges) == 0 {
+		graph.TestEdges = nil
+	}
+
+	graph.Cycles = findImportCycles(graph.Packages, index)
+	graph.Violations = checkPackageRules(graph.Packages, index, layers, rules)
+	return graph
+}
+
+// findImportCycles returns the import cycles between main module packages,
+// first those of the packages themselves, then those only closed by the
+// imports of in-package test files
+func findImportCycles(packages []*GoPackage, index map[string]*GoPackage) []ImportCycle {
+	imports := make(map[string][]string)
+	withTests := make(map[string][]string)
+	var nodes []string
+	for _, pkg := range packages {
+		if !pkg.main {
+			continue
+		}
+		nodes = append(nodes, pkg.ImportPath)
+		for _, imp := range pkg.Imports {
+			if dep := index[imp]; dep != nil && dep.main {
+				imports[pkg.ImportPath] = append(imports[pkg.ImportPath], imp)
+				withTests[pkg.ImportPath] = append(withTests[pkg.ImportPath], imp)
+			}
+		}
+		for _, imp := range pkg.TestImports {
+			if dep := index[imp]; dep != nil && dep.main && imp != pkg.ImportPath {
+				withTests[pkg.ImportPath] = append(withTests[pkg.ImportPath], imp)
+			}
+		}
+	}
+
+	var cycles []ImportCycle
+	seen := make(map[string]bool)
+	for _, scc := range stronglyConnected(nodes, imports) {
+		seen[strings.Join(scc, " ")] = true
+		cycles = append(cycles, ImportCycle{Path: cyclePath(scc, imports)})
+	}
+	for _, scc := range stronglyConnected(nodes, withTests) {
+		if !seen[strings.Join(scc, " ")] {
+			cycles = append(cycles, ImportCycle{Path: cyclePath(scc, withTests), Test: true})
+		}
+	}
+	return cycles
+}
+
+// stronglyConnected returns the strongly connected components of the graph
+// that contain a cycle, each sorted, using Tarjan's algorithm
+func stronglyConnected(nodes []string, edges map[string][]string) [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node], low[node] = next, next
+		next++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		selfLoop := false
+		for _, dep := range edges[node] {
+			if dep == node {
+				selfLoop = true
+			}
+			if _, ok := index[dep]; !ok {
+				visit(dep)
+				low[node] = min(low[node], low[dep])
+			} else if onStack[dep] {
+				low[node] = min(low[node], index[dep])
+			}
+		}
+
+		if low[node] == index[node] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
+				}
+			}
+			if len(component) > 1 || selfLoop {
+				sort.Strings(component)
+				components = append(components, component)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		if _, ok := index[node]; !ok {
+			visit(node)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+	return components
+}
+
+// cyclePath returns a shortest cycle through the first package of a strongly
+// connected component, staying inside the component
+func cyclePath(scc []string, edges map[string][]string) []string {
+	start := scc[0]
+	inSCC := make(map[string]bool)
+	for _, node := range scc {
+		inSCC[node] = true
+	}
+
+	previous := map[string]string{}
+	queue := []string{start}
+	visited := map[string]bool{}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dep := range edges[node] {
+			if !inSCC[dep] {
+				continue
+			}
+			if dep == start {
+				path := []string{start}
+				for n := node; n != start; n = previous[n] {
+					path = append(path, n)
+				}
+				// path is reversed after the start
+				for i, j := 1, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return append(path, start)
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				previous[dep] = node
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return append(append([]string{}, scc...), start)
+}
+
+// checkPackageRules returns the imports of main module packages that break
+// the configured layers or rules
+func checkPackageRules(packages []*GoPackage, index map[string]*GoPackage, layers []config.PackageLayer, rules []config.PackageRule) []RuleViolation {
+	if len(layers) == 0 && len(rules) == 0 {
+		return nil
+	}
+
+	// moduleOf returns the module to resolve relative patterns against
+	moduleOf := func(importPath, fallback string) string {
+		if pkg := index[importPath]; pkg != nil && pkg.Module != "" {
+			return pkg.Module
+		}
+		return fallback
+	}
+	layerOf := func(importPath, module string) int {
+		for i, layer := range layers {
+			for _, pattern := range layer.Packages {
+				if matchPackagePattern(pattern, importPath, module) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	var violations []RuleViolation
+	check := func(pkg *GoPackage, imp string, test bool) {
+		impModule := moduleOf(imp, pkg.Module)
+		from, to := layerOf(pkg.ImportPath, pkg.Module), layerOf(imp, impModule)
+		if from >= 0 && to >= 0 && from > to {
+			violations = append(violations, RuleViolation{
+				From: pkg.ImportPath,
+				To:   imp,
+				Rule: fmt.Sprintf("layer %s must not import layer %s", layers[from].Name, layers[to].Name),
+				Test: test,
+			})
+		}
+		for _, rule := range rules {
+			if !matchPackagePattern(rule.From, pkg.ImportPath, pkg.Module) {
+				continue
+			}
+			for _, deny := range rule.Deny {
+				if matchPackagePattern(deny, imp, impModule) {
+					violations = append(violations, RuleViolation{
+						From:   pkg.ImportPath,
+						To:     imp,
+						Rule:   fmt.Sprintf("%s must not import %s", rule.From, deny),
+						Reason: rule.Reason,
+						Test:   test,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		if !pkg.main {
+			continue
+		}
+		for _, imp := range pkg.Imports {
+			check(pkg, imp, false)
+		}
+		for _, imp := range append(append([]string{}, pkg.TestImports...), pkg.XTestImports...) {
+			if imp != pkg.ImportPath && !containsString(pkg.Imports, imp) {
+				check(pkg, imp, true)
+			}
+		}
+	}
+	return violations
+}
+
+// matchPackagePattern reports whether importPath matches pattern. Patterns
+// are import paths, optionally ending in /... to match a whole tree, and may
+// be relative to the package's module (e.g. internal/... or ".").
+func matchPackagePattern(pattern, importPath, module string) bool {
+	candidates := []string{importPath}
+	if module != "" {
+		if importPath == module {
+			candidates = append(candidates, ".")
+		} else if rel, ok := strings.CutPrefix(importPath, module+"/"); ok {
+			candidates = append(candidates, rel)
+		}
+	}
+	switch pattern {
+	case "...":
+		return true
+	case "./...":
+		// Every package of the module
+		return len(candidates) > 1
+	case ".":
+	default:
+		pattern = strings.TrimPrefix(pattern, "./")
+	}
+
+	for _, candidate := range candidates {
+		switch {
+		case strings.HasSuffix(pattern, "/..."):
+			prefix := strings.TrimSuffix(pattern, "/...")
+			if candidate == prefix || strings.HasPrefix(candidate, prefix+"/") {
+				return true
+			}
+		case candidate == pattern:
+			return true
+		}
+	}
+	return false
+}
+
+// DOT renders the graph in Graphviz DOT format. Test imports are dashed and
+// imports breaking a rule are red.
+func (g *PackageGraph) DOT() string {
+	violating := make(map[[2]string]bool)
+	for _, v := range g.Violations {
+		violating[[2]string{v.From, v.To}] = true
+	}
+	attrs := func(from, to string, test bool) string {
+		var list []string
+		if test {
+			list = append(list, "style=dashed")
+		}
+		if violating[[2]string{from, to}] {
+			list = append(list, "color=red")
+		}
+		if len(list) == 0 {
+			return ""
+		}
+		return " [" + strings.Join(list, ", ") + "]"
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph packages {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(&b, "\t%q;\n", pkg.ImportPath)
+	}
+	for _, pkg := range g.Packages {
+		for _, imp := range g.Edges[pkg.ImportPath] {
+			fmt.Fprintf(&b, "\t%q -> %q%s;\n", pkg.ImportPath, imp, attrs(pkg.ImportPath, imp, false))
+		}
+		for _, imp := range g.TestEdges[pkg.ImportPath] {
+			fmt.Fprintf(&b, "\t%q -> %q%s;\n", pkg.ImportPath, imp, attrs(pkg.ImportPath, imp, true))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
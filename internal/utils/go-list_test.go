@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// writeLayeredModule creates a module whose utils package imports the tools
+// package from its tests, and two packages importing each other
+func writeLayeredModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                          "module example.com/layered\n\ngo 1.21\n",
+		"cmd/app/main.go":                 "package main\n\nimport _ \"example.com/layered/internal/tools\"\n\nfunc main() {}\n",
+		"internal/tools/tools.go":         "package tools\n\nimport (\n\t_ \"embed\"\n\n\t\"example.com/layered/internal/utils\"\n)\n\n//go:embed schema.json\nvar schema string\n\nvar _ = utils.Name\n",
+		"internal/tools/schema.json":      "{}\n",
+		"internal/utils/utils.go":         "package utils\n\nimport \"strings\"\n\nvar Name = strings.ToUpper(\"utils\")\n",
+		"internal/utils/utils_test.go":    "package utils\n\nimport (\n\t\"testing\"\n\n\t_ \"example.com/layered/internal/tools\"\n)\n\nfunc TestName(t *testing.T) {}\n",
+		"internal/utils/external_test.go": "package utils_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/layered/internal/utils\"\n)\n\nfunc TestExternal(t *testing.T) { _ = utils.Name }\n",
+		"cyc/a/a.go":                      "package a\n\nimport _ \"example.com/layered/cyc/b\"\n",
+		"cyc/b/b.go":                      "package b\n\nimport _ \"example.com/layered/cyc/a\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestListPackages(t *testing.T) {
+	dir := writeLayeredModule(t)
+	cfg := &config.Config{
+		DisableNotifications: true,
+		WorkingDirectory:     dir,
+		PackageLayers: []config.PackageLayer{
+			{Name: "cmd", Packages: []string{"cmd/..."}},
+			{Name: "tools", Packages: []string{"internal/tools"}},
+			{Name: "utils", Packages: []string{"example.com/layered/internal/utils/..."}},
+		},
+		PackageRules: []config.PackageRule{
+			{From: "internal/...", Deny: []string{"cmd/...", "internal/tools"}, Reason: "no upward imports"},
+		},
+	}
+
+	graph, err := ListPackages(context.Background(), cfg, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListPackages failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(graph.Modules, []string{"example.com/layered"}) || graph.Scope != PackageScopeModule {
+		t.Errorf("modules %v scope %q", graph.Modules, graph.Scope)
+	}
+	var paths []string
+	packages := make(map[string]*GoPackage)
+	for _, pkg := range graph.Packages {
+		paths = append(paths, pkg.ImportPath)
+		packages[pkg.ImportPath] = pkg
+	}
+	wantPaths := []string{
+		"example.com/layered/cmd/app",
+		"example.com/layered/cyc/a",
+		"example.com/layered/cyc/b",
+		"example.com/layered/internal/tools",
+		"example.com/layered/internal/utils",
+	}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Fatalf("packages = %v, want %v", paths, wantPaths)
+	}
+
+	tools := packages["example.com/layered/internal/tools"]
+	if !reflect.DeepEqual(tools.EmbedFiles, []string{"schema.json"}) || !reflect.DeepEqual(tools.EmbedPatterns, []string{"schema.json"}) {
+		t.Errorf("embed = %v %v", tools.EmbedPatterns, tools.EmbedFiles)
+	}
+	if !reflect.DeepEqual(graph.Edges[tools.ImportPath], []string{"example.com/layered/internal/utils"}) {
+		t.Errorf("tools edges = %v", graph.Edges[tools.ImportPath])
+	}
+	utilsPkg := packages["example.com/layered/internal/utils"]
+	if !reflect.DeepEqual(utilsPkg.TestImports, []string{"example.com/layered/internal/tools", "testing"}) {
+		t.Errorf("utils test imports = %v", utilsPkg.TestImports)
+	}
+	if !reflect.DeepEqual(graph.TestEdges[utilsPkg.ImportPath], []string{"example.com/layered/internal/tools"}) {
+		t.Errorf("utils test edges = %v", graph.TestEdges[utilsPkg.ImportPath])
+	}
+	// Standard library packages are not nodes in the module scope
+	if _, ok := packages["strings"]; ok {
+		t.Error("standard library package listed in module scope")
+	}
+
+	wantCycles := []ImportCycle{
+		{Path: []string{"example.com/layered/cyc/a", "example.com/layered/cyc/b", "example.com/layered/cyc/a"}},
+		{Path: []string{"example.com/layered/internal/tools", "example.com/layered/internal/utils", "example.com/layered/internal/tools"}, Test: true},
+	}
+	if !reflect.DeepEqual(graph.Cycles, wantCycles) {
+		t.Errorf("cycles = %+v, want %+v", graph.Cycles, wantCycles)
+	}
+	if len(graph.Errors) == 0 || !strings.Contains(strings.Join(graph.Errors, "\n"), "import cycle") {
+		t.Errorf("expected go list to report the import cycle, got %v", graph.Errors)
+	}
+
+	wantViolations := []RuleViolation{
+		{From: utilsPkg.ImportPath, To: tools.ImportPath, Rule: "layer utils must not import layer tools", Test: true},
+		{From: utilsPkg.ImportPath, To: tools.ImportPath, Rule: "internal/... must not import internal/tools", Reason: "no upward imports", Test: true},
+	}
+	if !reflect.DeepEqual(graph.Violations, wantViolations) {
+		t.Errorf("violations = %+v, want %+v", graph.Violations, wantViolations)
+	}
+
+	dot := graph.DOT()
+	for _, want := range []string{
+		"digraph packages {",
+		`"example.com/layered/cmd/app" -> "example.com/layered/internal/tools";`,
+		`"example.com/layered/internal/utils" -> "example.com/layered/internal/tools" [style=dashed, color=red];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot)
+		}
+	}
+
+	all, err := ListPackages(context.Background(), cfg, ListOptions{Patterns: []string{"./cmd/..."}, Scope: PackageScopeAll})
+	if err != nil {
+		t.Fatalf("ListPackages with scope all failed: %v", err)
+	}
+	found := false
+	for _, pkg := range all.Packages {
+		if pkg.ImportPath == "strings" && pkg.Standard {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected the standard library in scope all")
+	}
+
+	if _, err := ListPackages(context.Background(), cfg, ListOptions{Scope: "everything"}); err == nil {
+		t.Error("expected an error for an invalid scope")
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	tests := []struct {
+		pattern, importPath string
+		want                bool
+	}{
+		{"internal/...", "example.com/m/internal/utils", true},
+		{"internal/...", "example.com/m/internal", true},
+		{"internal/...", "example.com/m/internalx", false},
+		{"internal/utils", "example.com/m/internal/utils", true},
+		{"internal/utils", "example.com/m/internal/utils/sub", false},
+		{"./cmd/...", "example.com/m/cmd/app", true},
+		{".", "example.com/m", true},
+		{"./...", "example.com/m/internal", true},
+		{"./...", "example.com/other", false},
+		{"...", "example.com/other", true},
+		{"github.com/lib/...", "github.com/lib/pq", true},
+		{"net/http", "net/http", true},
+	}
+	for _, tt := range tests {
+		if got := matchPackagePattern(tt.pattern, tt.importPath, "example.com/m"); got != tt.want {
+			t.Errorf("matchPackagePattern(%q, %q) = %v, want %v", tt.pattern, tt.importPath, got, tt.want)
+		}
+	}
+}
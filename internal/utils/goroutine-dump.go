@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goroutineHeader matches the first line of a goroutine in a dump, e.g.
+// "goroutine 7 [chan receive, 5 minutes, locked to thread]:"
+var goroutineHeader = regexp.MustCompile(`^goroutine (\d+)(?: gp=\S+ m=\S+(?: mp=\S+)?)? \[([^\]]*)\]:$`)
+
+// GoroutineSummary summarizes a goroutine dump such as the one a Go program
+// writes to stderr on SIGQUIT.
+type GoroutineSummary struct {
+	Total   int              `json:"total"`
+	ByState map[string]int   `json:"by_state"`
+	Groups  []GoroutineGroup `json:"groups"` // goroutines with identical stacks, largest first
+}
+
+// GoroutineGroup is a set of goroutines in the same state with the same stack.
+type GoroutineGroup struct {
+	Count     int      `json:"count"`
+	State     string   `json:"state"`
+	MaxWait   int      `json:"max_wait_minutes,omitempty"` // longest time blocked, as reported by the runtime
+	Stack     []string `json:"stack"`                      // functions, innermost first
+	CreatedBy string   `json:"created_by,omitempty"`
+	IDs       []int    `json:"ids"`
+}
+
+// ParseGoroutineDump parses the goroutines in lines of a goroutine dump.
+// Lines that are not part of a goroutine, such as the signal and register
+// lines before the first goroutine, are ignored. It returns nil if the
+// lines contain no goroutines.
+func ParseGoroutineDump(lines []string) *GoroutineSummary {
+	type goroutine struct {
+		id        int
+		state     string
+		wait      int
+		stack     []string
+		createdBy string
+	}
+
+	var goroutines []*goroutine
+	var current *goroutine
+	for _, line := range lines {
+		if m := goroutineHeader.FindStringSubmatch(line); m != nil {
+			id, _ := strconv.Atoi(m[1])
+			current = &goroutine{id: id}
+			current.state, current.wait = parseGoroutineState(m[2])
+			goroutines = append(goroutines, current)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch {
+		case line == "":
+			// A blank line ends the goroutine
+			current = nil
+		case strings.HasPrefix(line, "\t"), strings.HasPrefix(line, "..."):
+			// File and line of the previous frame, or elided frames
+		case strings.HasPrefix(line, "created by "):
+			createdBy := strings.TrimPrefix(line, "created by ")
+			if idx := strings.Index(createdBy, " in goroutine "); idx >= 0 {
+				createdBy = createdBy[:idx]
+			}
+			current.createdBy = createdBy
+		default:
+			current.stack = append(current.stack, frameFunction(line))
+		}
+	}
+	if len(goroutines) == 0 {
+		return nil
+	}
+
+	summary := &GoroutineSummary{ByState: make(map[string]int)}
+	groups := make(map[string]*GoroutineGroup)
+	for _, g := range goroutines {
+		summary.Total++
+		summary.ByState[g.state]++
+
+		key := g.state + "\x00" + strings.Join(g.stack, "\x00") + "\x00" + g.createdBy
+		group, ok := groups[key]
+		if !ok {
+			group = &GoroutineGroup{State: g.state, Stack: g.stack, CreatedBy: g.createdBy}
+			groups[key] = group
+		}
+		group.Count++
+		group.IDs = append(group.IDs, g.id)
+		if g.wait > group.MaxWait {
+			group.MaxWait = g.wait
+		}
+	}
+	for _, group := range groups {
+		summary.Groups = append(summary.Groups, *group)
+	}
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		a, b := summary.Groups[i], summary.Groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.IDs[0] < b.IDs[0]
+	})
+	return summary
+}
+
+// parseGoroutineState splits "chan receive, 5 minutes, locked to thread"
+// into the state and the minutes waited
+func parseGoroutineState(s string) (string, int) {
+	parts := strings.Split(s, ", ")
+	state := parts[0]
+	wait := 0
+	for _, part := range parts[1:] {
+		if minutes, ok := strings.CutSuffix(part, " minutes"); ok {
+			wait, _ = strconv.Atoi(minutes)
+		}
+	}
+	return state, wait
+}
+
+// frameFunction returns the function of a stack frame line such as
+// "main.handler(0xc000010000, 0x1)" or "net/http.(*conn).serve(...)"
+func frameFunction(line string) string {
+	line = strings.TrimSpace(line)
+	// Arguments start at the last opening parenthesis that is not part of
+	// a method receiver such as "(*conn)"
+	if idx := strings.LastIndex(line, "("); idx > 0 && strings.HasSuffix(line, ")") && line[idx-1] != '.' {
+		return line[:idx]
+	}
+	return line
+}
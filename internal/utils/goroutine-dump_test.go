@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleGoroutineDump = `SIGQUIT: quit
+PC=0x46e1c1 m=0 sigcode=0
+
+goroutine 1 gp=0xc000002380 m=nil [IO wait, 3 minutes]:
+internal/poll.runtime_pollWait(0x7f, 0x72)
+	/usr/local/go/src/runtime/netpoll.go:351 +0x85
+net.(*netFD).accept(0xc0000b0000)
+	/usr/local/go/src/net/fd_unix.go:172 +0x29
+main.main()
+	/app/main.go:20 +0x4b
+
+goroutine 7 [chan receive, 12 minutes]:
+main.worker(0xc000020060)
+	/app/main.go:31 +0x2d
+created by main.main in goroutine 1
+	/app/main.go:15 +0x65
+
+goroutine 8 [chan receive, 5 minutes]:
+main.worker(0xc000020060)
+	/app/main.go:31 +0x2d
+created by main.main in goroutine 1
+	/app/main.go:15 +0x65
+
+goroutine 9 [running]:
+	goroutine running on other thread; stack unavailable
+created by main.main in goroutine 1
+	/app/main.go:16 +0x80
+
+rax    0xfffffffffffffffc
+rbx    0x0
+`
+
+func TestParseGoroutineDump(t *testing.T) {
+	summary := ParseGoroutineDump(strings.Split(sampleGoroutineDump, "\n"))
+	if summary == nil {
+		t.Fatal("Expected a summary")
+	}
+	if summary.Total != 4 {
+		t.Errorf("Expected 4 goroutines, got %d", summary.Total)
+	}
+	if summary.ByState["chan receive"] != 2 || summary.ByState["IO wait"] != 1 || summary.ByState["running"] != 1 {
+		t.Errorf("Unexpected counts by state: %v", summary.ByState)
+	}
+	if len(summary.Groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %+v", summary.Groups)
+	}
+
+	workers := summary.Groups[0]
+	if workers.Count != 2 || workers.State != "chan receive" || workers.MaxWait != 12 {
+		t.Errorf("Expected the two workers grouped first, got %+v", workers)
+	}
+	if len(workers.Stack) != 1 || workers.Stack[0] != "main.worker" || workers.CreatedBy != "main.main" {
+		t.Errorf("Unexpected worker stack: %+v", workers)
+	}
+	accept := summary.Groups[1]
+	want := []string{"internal/poll.runtime_pollWait", "net.(*netFD).accept", "main.main"}
+	if strings.Join(accept.Stack, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected stack %v, got %v", want, accept.Stack)
+	}
+}
+
+func TestParseGoroutineDump_NoGoroutines(t *testing.T) {
+	if summary := ParseGoroutineDump([]string{"panic: boom", ""}); summary != nil {
+		t.Errorf("Expected nil summary, got %+v", summary)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -25,20 +26,54 @@ type CommandResult struct {
 
 // ExecuteGoCommand executes a Go command with proper environment setup
 func ExecuteGoCommand(ctx context.Context, cfg *config.Config, command string, args []string, workingDir string, envVars map[string]string) (*CommandResult, error) {
+	return ExecuteGoCommandWithOutput(ctx, cfg, command, args, workingDir, envVars, nil, nil)
+}
+
+// ExecuteGoCommandWithOutput executes a Go command like ExecuteGoCommand and
+// additionally streams stdout and stderr to the given writers while it runs.
+// Either writer may be nil.
+func ExecuteGoCommandWithOutput(ctx context.Context, cfg *config.Config, command string, args []string, workingDir string, envVars map[string]string, stdoutSink, stderrSink io.Writer) (*CommandResult, error) {
+	// Record the execution in the audit log, whatever the outcome
+	entry := AuditEntry{
+		Kind:         AuditKindCommand,
+		Tool:         ToolNameFromContext(ctx),
+		Command:      command,
+		Args:         RedactArgs(args),
+		WorkingDir:   workingDir,
+		EnvOverrides: RedactEnv(envVars),
+		Permission:   PermissionNotRequired,
+	}
+	defer func() {
+		RecordAudit(entry)
+	}()
+
 	// Validate command to prevent injection
 	if err := ValidateCommand(command, args); err != nil {
+		entry.Permission = PermissionRejected
+		entry.Error = err.Error()
 		return nil, fmt.Errorf("command validation failed: %w", err)
 	}
 
+	// Keep commands inside the configured workspace roots
+	if err := cfg.CheckWorkingDir(workingDir); err != nil {
+		entry.Permission = PermissionRejected
+		entry.Error = err.Error()
+		return nil, err
+	}
+
 	// Request permission unless disabled
 	if !cfg.DisableNotifications {
 		if err := RequestPermission(command, args); err != nil {
+			entry.Permission = PermissionDenied
+			entry.Error = err.Error()
 			return nil, fmt.Errorf("permission denied: %w", err)
 		}
+		entry.Permission = PermissionAllowed
 	}
 
 	// Create command
 	cmd := exec.CommandContext(ctx, command, args...)
+	entry.Command = cmd.Path
 
 	// Set working directory
 	if workingDir != "" {
@@ -46,19 +81,14 @@ func ExecuteGoCommand(ctx context.Context, cfg *config.Config, command string, a
 	} else {
 		cmd.Dir = cfg.WorkingDirectory
 	}
+	entry.WorkingDir = cmd.Dir
 
-	// Set up environment
-	env := os.Environ()
-
-	// Add Go environment variables
-	goEnv := cfg.GetGoEnv()
-	for k, v := range goEnv {
-		env = append(env, fmt.Sprintf("%s=%s", k, v))
-	}
-
-	// Add custom environment variables
-	for k, v := range envVars {
-		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	// Set up environment according to the env policy
+	env, err := BuildEnv(cfg, envVars)
+	if err != nil {
+		entry.Permission = PermissionRejected
+		entry.Error = err.Error()
+		return nil, fmt.Errorf("environment validation failed: %w", err)
 	}
 	cmd.Env = env
 
@@ -66,11 +96,20 @@ func ExecuteGoCommand(ctx context.Context, cfg *config.Config, command string, a
 	var stdout, stderr strings.Builder
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	if stdoutSink != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, stdoutSink)
+	}
+	if stderrSink != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, stderrSink)
+	}
 
 	// Execute command
 	start := time.Now()
-	err := cmd.Run()
+	err = cmd.Run()
 	duration := time.Since(start)
+	entry.DurationMS = duration.Milliseconds()
+	entry.StdoutBytes = stdout.Len()
+	entry.StderrBytes = stderr.Len()
 
 	// Get exit code
 	exitCode := 0
@@ -79,16 +118,26 @@ func ExecuteGoCommand(ctx context.Context, cfg *config.Config, command string, a
 			exitCode = exitError.ExitCode()
 		} else {
 			// Command failed to start or was interrupted
+			entry.Error = err.Error()
 			return nil, fmt.Errorf("command execution failed: %w", err)
 		}
 	}
+	entry.ExitCode = &exitCode
 
-	return &CommandResult{
+	result := &CommandResult{
 		Stdout:   stdout.String(),
 		Stderr:   stderr.String(),
 		ExitCode: exitCode,
 		Duration: duration,
-	}, nil
+	}
+	if !cfg.DisableRedaction {
+		result.Stdout = RedactSecrets(result.Stdout)
+		result.Stderr = RedactSecrets(result.Stderr)
+	}
+	if hint := offlineHint(cfg, result.Stderr); hint != "" && exitCode != 0 {
+		result.Stderr += hint + "\n"
+	}
+	return result, nil
 }
 
 // ValidateCommand validates command and arguments to prevent injection attacks
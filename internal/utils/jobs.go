@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// Job status values
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+const (
+	defaultJobWorkers   = 4
+	defaultJobQueueSize = 100
+	defaultJobLogSize   = 1000
+)
+
+// Job is a command executed in the background by a JobManager.
+type Job struct {
+	ID         string
+	Tool       string
+	Command    string
+	Args       []string
+	WorkingDir string
+	EnvVars    map[string]string
+	Output     *RingBuffer
+	status     string
+	result     *CommandResult
+	err        string
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	ctx        context.Context
+	cancel     context.CancelFunc
+	done       chan struct{}
+	mu         sync.RWMutex
+}
+
+// JobState is a point-in-time snapshot of a job.
+type JobState struct {
+	ID         string         `json:"id"`
+	Tool       string         `json:"tool,omitempty"`
+	Command    string         `json:"command"`
+	Args       []string       `json:"args"`
+	WorkingDir string         `json:"working_dir,omitempty"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	Result     *CommandResult `json:"result,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Duration   string         `json:"duration,omitempty"`
+}
+
+// State returns a snapshot of the job.
+func (j *Job) State() JobState {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	state := JobState{
+		ID:         j.ID,
+		Tool:       j.Tool,
+		Command:    j.Command,
+		Args:       j.Args,
+		WorkingDir: j.WorkingDir,
+		Status:     j.status,
+		Error:      j.err,
+		Result:     j.result,
+		CreatedAt:  j.createdAt,
+	}
+	if !j.startedAt.IsZero() {
+		started := j.startedAt
+		state.StartedAt = &started
+		end := time.Now()
+		if !j.finishedAt.IsZero() {
+			end = j.finishedAt
+		}
+		state.Duration = end.Sub(j.startedAt).String()
+	}
+	if !j.finishedAt.IsZero() {
+		finished := j.finishedAt
+		state.FinishedAt = &finished
+	}
+	return state
+}
+
+// Done returns a channel that is closed when the job finishes.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Finished reports whether the job has reached a terminal state.
+func (j *Job) Finished() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (j *Job) finish(status string, result *CommandResult, errMsg string) {
+	j.mu.Lock()
+	j.status = status
+	j.result = result
+	j.err = errMsg
+	j.finishedAt = time.Now()
+	j.mu.Unlock()
+	close(j.done)
+}
+
+// JobManager runs commands in the background on a bounded pool of workers
+// and retains finished jobs for a limited time.
+type JobManager struct {
+	cfg    *config.Config
+	jobs   sync.Map
+	queue  chan *Job
+	ttl    time.Duration
+	nextID atomic.Uint64
+}
+
+// NewJobManager creates a job manager with the given number of workers.
+// Finished jobs are discarded once they are older than ttl.
+func NewJobManager(cfg *config.Config, workers int, ttl time.Duration) *JobManager {
+	if workers <= 0 {
+		workers = defaultJobWorkers
+	}
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	jm := &JobManager{
+		cfg:   cfg,
+		queue: make(chan *Job, defaultJobQueueSize),
+		ttl:   ttl,
+	}
+
+	for i := 0; i < workers; i++ {
+		go jm.worker()
+	}
+
+	// Start cleanup goroutine
+	go jm.cleanup()
+
+	return jm
+}
+
+// Submit queues a command for background execution and returns its job.
+func (jm *JobManager) Submit(tool, command string, args []string, workingDir string, envVars map[string]string) (*Job, error) {
+	if err := ValidateCommand(command, args); err != nil {
+		return nil, fmt.Errorf("command validation failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(WithToolName(context.Background(), tool))
+	job := &Job{
+		ID:         fmt.Sprintf("job-%d", jm.nextID.Add(1)),
+		Tool:       tool,
+		Command:    command,
+		Args:       args,
+		WorkingDir: workingDir,
+		EnvVars:    envVars,
+		Output:     NewRingBuffer(defaultJobLogSize),
+		status:     JobQueued,
+		createdAt:  time.Now(),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	jm.jobs.Store(job.ID, job)
+	select {
+	case jm.queue <- job:
+	default:
+		jm.jobs.Delete(job.ID)
+		cancel()
+		return nil, fmt.Errorf("job queue is full (%d jobs pending)", cap(jm.queue))
+	}
+
+	return job, nil
+}
+
+// GetJob returns a job by ID
+func (jm *JobManager) GetJob(id string) (*Job, error) {
+	value, ok := jm.jobs.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	return value.(*Job), nil
+}
+
+// ListJobs returns all retained jobs ordered by creation time
+func (jm *JobManager) ListJobs() []*Job {
+	var jobs []*Job
+	jm.jobs.Range(func(key, value interface{}) bool {
+		jobs = append(jobs, value.(*Job))
+		return true
+	})
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].createdAt.Before(jobs[k].createdAt)
+	})
+	return jobs
+}
+
+// WaitJob blocks until the job finishes, the timeout elapses or ctx is done.
+// It reports whether the job finished.
+func (jm *JobManager) WaitJob(ctx context.Context, id string, timeout time.Duration) (*Job, bool, error) {
+	job, err := jm.GetJob(id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case <-job.done:
+		return job, true, nil
+	case <-timer:
+		return job, false, nil
+	case <-ctx.Done():
+		return job, false, ctx.Err()
+	}
+}
+
+// CancelJob cancels a queued or running job.
+func (jm *JobManager) CancelJob(id string) error {
+	job, err := jm.GetJob(id)
+	if err != nil {
+		return err
+	}
+	if job.Finished() {
+		return fmt.Errorf("job already finished: %s", id)
+	}
+	job.cancel()
+
+	// A queued job is finished now rather than when a worker reaches it,
+	// which may take as long as the jobs ahead of it
+	job.mu.Lock()
+	queued := job.status == JobQueued
+	if queued {
+		job.status = JobCancelled
+	}
+	job.mu.Unlock()
+	if queued {
+		job.finish(JobCancelled, nil, "cancelled before start")
+	}
+	return nil
+}
+
+// worker executes queued jobs one at a time
+func (jm *JobManager) worker() {
+	for job := range jm.queue {
+		jm.run(job)
+	}
+}
+
+func (jm *JobManager) run(job *Job) {
+	job.mu.Lock()
+	if job.status != JobQueued {
+		// Cancelled while queued
+		job.mu.Unlock()
+		return
+	}
+	job.status = JobRunning
+	job.startedAt = time.Now()
+	job.mu.Unlock()
+
+	stdout := newLineWriter(job.Output)
+	stderr := newLineWriter(job.Output)
+	stdout.redact = !jm.cfg.DisableRedaction
+	stderr.redact = !jm.cfg.DisableRedaction
+	result, err := ExecuteGoCommandWithOutput(job.ctx, jm.cfg, job.Command, job.Args, job.WorkingDir, job.EnvVars, stdout, stderr)
+	stdout.Flush()
+	stderr.Flush()
+	cancelled := errors.Is(job.ctx.Err(), context.Canceled)
+	job.cancel()
+
+	switch {
+	case cancelled:
+		job.finish(JobCancelled, result, "cancelled")
+	case err != nil:
+		job.finish(JobFailed, nil, err.Error())
+	case result.ExitCode != 0:
+		job.finish(JobFailed, result, fmt.Sprintf("exit code %d", result.ExitCode))
+	default:
+		job.finish(JobSucceeded, result, "")
+	}
+}
+
+// cleanup periodically removes finished jobs older than the TTL
+func (jm *JobManager) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		jm.removeExpired(time.Now())
+	}
+}
+
+func (jm *JobManager) removeExpired(now time.Time) {
+	jm.jobs.Range(func(key, value interface{}) bool {
+		job := value.(*Job)
+		job.mu.RLock()
+		finishedAt := job.finishedAt
+		job.mu.RUnlock()
+		if !finishedAt.IsZero() && now.Sub(finishedAt) > jm.ttl {
+			jm.jobs.Delete(key)
+		}
+		return true
+	})
+}
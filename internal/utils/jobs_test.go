@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestLineWriter(t *testing.T) {
+	rb := NewRingBuffer(10)
+	lw := newLineWriter(rb)
+
+	_, _ = lw.Write([]byte("first li"))
+	if got := rb.GetAll(); len(got) != 0 {
+		t.Fatalf("Expected partial line to be held back, got %v", got)
+	}
+
+	_, _ = lw.Write([]byte("ne\nsecond line\r\nthird"))
+	got := rb.GetAll()
+	if len(got) != 2 || got[0] != "first line" || got[1] != "second line" {
+		t.Fatalf("Unexpected lines: %v", got)
+	}
+
+	lw.Flush()
+	got = rb.GetAll()
+	if len(got) != 3 || got[2] != "third" {
+		t.Errorf("Expected flushed partial line, got %v", got)
+	}
+}
+
+func TestJobManager_SubmitAndWait(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("Go command not available")
+	}
+
+	cfg := createTestConfig(t, true)
+	jm := NewJobManager(cfg, 2, time.Hour)
+
+	job, err := jm.Submit("go_version", "go", []string{"version"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	_, finished, err := jm.WaitJob(testContext(t), job.ID, 10*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to wait for job: %v", err)
+	}
+	if !finished {
+		t.Fatal("Expected job to finish")
+	}
+
+	state := job.State()
+	if state.Status != JobSucceeded {
+		t.Errorf("Expected status %q, got %q (error: %s)", JobSucceeded, state.Status, state.Error)
+	}
+	if state.Result == nil || state.Result.ExitCode != 0 {
+		t.Errorf("Expected successful result, got %+v", state.Result)
+	}
+	if len(job.Output.GetAll()) == 0 {
+		t.Error("Expected job output to be captured")
+	}
+}
+
+func TestJobManager_Cancel(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep command not available")
+	}
+
+	cfg := createTestConfig(t, true)
+	jm := NewJobManager(cfg, 1, time.Hour)
+
+	job, err := jm.Submit("", "sleep", []string{"5"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if err := jm.CancelJob(job.ID); err != nil {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+
+	_, finished, _ := jm.WaitJob(context.Background(), job.ID, 5*time.Second)
+	if !finished {
+		t.Fatal("Expected cancelled job to finish")
+	}
+	if status := job.State().Status; status != JobCancelled {
+		t.Errorf("Expected status %q, got %q", JobCancelled, status)
+	}
+
+	if err := jm.CancelJob(job.ID); err == nil {
+		t.Error("Expected error when cancelling a finished job")
+	}
+}
+
+func TestJobManager_CancelQueued(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep command not available")
+	}
+
+	cfg := createTestConfig(t, true)
+	jm := NewJobManager(cfg, 1, time.Hour)
+
+	running, err := jm.Submit("", "sleep", []string{"5"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	defer jm.CancelJob(running.ID)
+	queued, err := jm.Submit("", "sleep", []string{"5"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	if err := jm.CancelJob(queued.ID); err != nil {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+	// The job ahead of it is still running, so the cancelled job must not
+	// wait for a worker
+	_, finished, _ := jm.WaitJob(context.Background(), queued.ID, time.Second)
+	if !finished {
+		t.Fatal("Expected the queued job to finish when cancelled")
+	}
+	if state := queued.State(); state.Status != JobCancelled || state.StartedAt != nil {
+		t.Errorf("Expected a cancelled job that never started, got %+v", state)
+	}
+	if running.Finished() {
+		t.Error("Expected the running job to be unaffected")
+	}
+}
+
+func TestJobManager_RemoveExpired(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("Go command not available")
+	}
+
+	cfg := createTestConfig(t, true)
+	jm := NewJobManager(cfg, 1, time.Minute)
+
+	job, err := jm.Submit("", "go", []string{"version"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	_, _, _ = jm.WaitJob(testContext(t), job.ID, 10*time.Second)
+
+	jm.removeExpired(time.Now())
+	if _, err := jm.GetJob(job.ID); err != nil {
+		t.Error("Expected recently finished job to be retained")
+	}
+
+	jm.removeExpired(time.Now().Add(2 * time.Minute))
+	if _, err := jm.GetJob(job.ID); err == nil {
+		t.Error("Expected expired job to be removed")
+	}
+
+	if _, err := jm.GetJob("nonexistent"); err == nil {
+		t.Error("Expected error for unknown job")
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// ErrOffline is returned when an operation needs network access while
+// offline mode is enabled.
+var ErrOffline = errors.New("offline mode is enabled")
+
+// offlineNote is appended to the output of go commands that failed because
+// offline mode turned off the module proxy
+const offlineNote = "offline mode is enabled: modules that are not in the module cache or vendor directory cannot be downloaded"
+
+// offlineHint returns offlineNote if offline mode is enabled and stderr of
+// a go command shows that it needed to download a module. Which commands
+// need the network depends on the module cache, so the go command decides
+// under GOPROXY=off rather than the command line being matched beforehand.
+func offlineHint(cfg *config.Config, stderr string) string {
+	if cfg.Offline && strings.Contains(stderr, "disabled by GOPROXY=off") {
+		return offlineNote
+	}
+	return ""
+}
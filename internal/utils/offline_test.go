@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func TestOfflineGoCommands(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/offline\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{Offline: true, OfflineModMode: "mod", DisableNotifications: true, WorkingDirectory: dir}
+	ctx := context.Background()
+
+	// Commands that only need the module itself still run
+	result, err := ExecuteGoCommand(ctx, cfg, "go", []string{"build", "./..."}, "", nil)
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("Expected go build to work offline: %v %+v", err, result)
+	}
+
+	// The go command itself refuses to download a module
+	result, err = ExecuteGoCommand(ctx, cfg, "go", []string{"get", "example.invalid/missing@latest"}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode == 0 || !strings.Contains(result.Stderr, offlineNote) {
+		t.Errorf("Expected go get to fail with the offline note, got %+v", result)
+	}
+
+	if hint := offlineHint(&config.Config{}, result.Stderr); hint != "" {
+		t.Errorf("Expected no hint when offline mode is disabled, got %q", hint)
+	}
+}
+
+func TestOfflinePackageDocs(t *testing.T) {
+	cfg := &config.Config{Offline: true, DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	cache := NewCache(time.Hour, false, "")
+	ctx := context.Background()
+
+	if _, err := SearchPackages(ctx, cfg, "http"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline from SearchPackages, got %v", err)
+	}
+
+	if _, err := FetchPackageDocs(ctx, cfg, "example.invalid/missing", "", cache); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline for missing package, got %v", err)
+	}
+}
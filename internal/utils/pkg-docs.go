@@ -73,8 +73,9 @@ type ExampleDoc struct {
 	Description string `json:"description,omitempty"`
 }
 
-// FetchPackageDocs fetches package documentation from go.dev
-func FetchPackageDocs(ctx context.Context, pkgPath, version string, cache *Cache) (*PackageDoc, error) {
+// FetchPackageDocs fetches package documentation from go.dev. In offline
+// mode only the cache and the local go doc command are used.
+func FetchPackageDocs(ctx context.Context, cfg *config.Config, pkgPath, version string, cache *Cache) (*PackageDoc, error) {
 	// Check cache first
 	cacheKey := GetCacheKey(pkgPath, version)
 	if cached, found := cache.Get(cacheKey); found {
@@ -83,6 +84,14 @@ func FetchPackageDocs(ctx context.Context, pkgPath, version string, cache *Cache
 		}
 	}
 
+	if cfg.Offline {
+		doc, err := fetchFromLocal(ctx, cfg, pkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: documentation for %s is not available locally: %v", ErrOffline, pkgPath, err)
+		}
+		return doc, nil
+	}
+
 	// Try to fetch from pkg.go.dev API
 	doc, err := fetchFromAPI(ctx, pkgPath, version)
 	if err == nil {
@@ -100,7 +109,7 @@ func FetchPackageDocs(ctx context.Context, pkgPath, version string, cache *Cache
 	}
 
 	// Last resort: use local go doc
-	return fetchFromLocal(ctx, pkgPath)
+	return fetchFromLocal(ctx, cfg, pkgPath)
 }
 
 // fetchFromAPI fetches documentation from pkg.go.dev API
@@ -179,9 +188,7 @@ func fetchFromHTML(ctx context.Context, pkgPath, version string) (*PackageDoc, e
 }
 
 // fetchFromLocal fetches documentation using local go doc command
-func fetchFromLocal(ctx context.Context, pkgPath string) (*PackageDoc, error) {
-	cfg := config.Load()
-
+func fetchFromLocal(ctx context.Context, cfg *config.Config, pkgPath string) (*PackageDoc, error) {
 	// Use ExecuteGoCommand to run go doc
 	result, err := ExecuteGoCommand(ctx, cfg, "go", []string{"doc", "-all", pkgPath}, "", nil)
 	if err != nil {
@@ -236,10 +243,13 @@ func extractOverview(html string) string {
 // It returns a list of package paths that match the search query.
 // The function makes an HTTP request to pkg.go.dev search endpoint and parses
 // the HTML response to extract package paths.
-func SearchPackages(ctx context.Context, query string) ([]string, error) {
+func SearchPackages(ctx context.Context, cfg *config.Config, query string) ([]string, error) {
 	if query == "" {
 		return nil, fmt.Errorf("search query cannot be empty")
 	}
+	if cfg.Offline {
+		return nil, fmt.Errorf("%w: searching pkg.go.dev requires network access", ErrOffline)
+	}
 
 	log.Printf("Searching packages for query: %s", query)
 
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
 )
 
 func TestFetchPackageDocs_WithCache(t *testing.T) {
@@ -27,7 +29,7 @@ func TestFetchPackageDocs_WithCache(t *testing.T) {
 	_ = cache.Set(cacheKey, testDoc)
 
 	// Fetch should return cached value
-	doc, err := FetchPackageDocs(ctx, "test/package", "", cache)
+	doc, err := FetchPackageDocs(ctx, &config.Config{}, "test/package", "", cache)
 	if err != nil {
 		t.Fatalf("Failed to fetch from cache: %v", err)
 	}
@@ -64,7 +66,7 @@ func TestFetchPackageDocs_FromAPI(t *testing.T) {
 	}
 
 	// Test local fallback
-	doc, err := FetchPackageDocs(ctx, "fmt", "", cache)
+	doc, err := FetchPackageDocs(ctx, &config.Config{}, "fmt", "", cache)
 	if err != nil {
 		// This might fail if fmt package is not available, which is okay
 		t.Logf("Local doc fetch failed (expected in some environments): %v", err)
@@ -79,7 +81,7 @@ func TestFetchPackageDocs_FromAPI(t *testing.T) {
 func TestSearchPackages(t *testing.T) {
 	t.Run("empty query", func(t *testing.T) {
 		ctx := context.Background()
-		_, err := SearchPackages(ctx, "")
+		_, err := SearchPackages(ctx, &config.Config{}, "")
 		if err == nil {
 			t.Error("Expected error for empty query")
 		}
@@ -93,7 +95,7 @@ func TestSearchPackages(t *testing.T) {
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
 
-		packages, err := SearchPackages(ctx, "http")
+		packages, err := SearchPackages(ctx, &config.Config{}, "http")
 		if err != nil {
 			if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "connection") {
 				t.Skipf("Network test skipped due to network issue: %v", err)
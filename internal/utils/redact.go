@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces secret values in audit records and logs.
+const RedactedValue = "[REDACTED]"
+
+// secretKeyPattern matches names of arguments, flags and environment
+// variables that usually carry credentials. "auth" only counts as a whole
+// trailing word, e.g. NPM_AUTH or --auth, or as part of authorization.
+var secretKeyPattern = regexp.MustCompile(`(?i)(token|secret|passw(or)?d|passwd|api[_-]?key|access[_-]?key|private[_-]?key|credential|authorization|(^|[_-])auth$)`)
+
+// nonSecretKeys look like secrets but name a socket or a helper command
+// rather than holding a credential, and are needed to fetch private modules.
+var nonSecretKeys = map[string]bool{"SSH_AUTH_SOCK": true, "GOAUTH": true}
+
+// IsSecretKey reports whether name looks like it holds a secret.
+func IsSecretKey(name string) bool {
+	if nonSecretKeys[strings.ToUpper(name)] {
+		return false
+	}
+	return secretKeyPattern.MatchString(name)
+}
+
+// RedactArguments decodes raw JSON tool arguments and replaces the values of
+// secret-looking keys, env var maps and flags with RedactedValue.
+func RedactArguments(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	return redactValue(value)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			if IsSecretKey(k) {
+				result[k] = RedactedValue
+				continue
+			}
+			result[k] = redactValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redactValue(item)
+			if i > 0 {
+				if prev, ok := v[i-1].(string); ok && isSecretFlag(prev) {
+					result[i] = RedactedValue
+				}
+			}
+		}
+		return result
+	case string:
+		return RedactFlag(v)
+	default:
+		return v
+	}
+}
+
+// RedactArgs returns a copy of command-line arguments with the values of
+// secret-looking flags (e.g. --token=abc) replaced.
+func RedactArgs(args []string) []string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		result[i] = RedactFlag(arg)
+		if i > 0 && isSecretFlag(args[i-1]) {
+			result[i] = RedactedValue
+		}
+	}
+	return result
+}
+
+// isSecretFlag reports whether arg is a "--password" style flag whose value
+// follows as the next argument.
+func isSecretFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") && IsSecretKey(arg)
+}
+
+// RedactFlag redacts the value of a single KEY=value or --flag=value string
+// when the key looks secret.
+func RedactFlag(s string) string {
+	idx := strings.Index(s, "=")
+	if idx <= 0 {
+		return s
+	}
+	if IsSecretKey(s[:idx]) {
+		return s[:idx+1] + RedactedValue
+	}
+	return s
+}
+
+// RedactEnv returns a copy of env with secret-looking values replaced.
+func RedactEnv(env map[string]string) map[string]string {
+	if len(env) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(env))
+	for k, v := range env {
+		if IsSecretKey(k) {
+			result[k] = RedactedValue
+		} else {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// secretValuePatterns match well-known credential formats in free text.
+var secretValuePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(-----END [A-Z ]*PRIVATE KEY-----|$)`),
+}
+
+// secretAssignmentPattern matches "password=...", "API_TOKEN: ..." and
+// "Authorization: Bearer ..." style assignments so only the value is redacted.
+var secretAssignmentPattern = regexp.MustCompile(`(?i)\b([A-Za-z0-9_.-]*(?:token|secret|passw(?:or)?d|api[_-]?key|access[_-]?key|authorization)[A-Za-z0-9_.-]*"?\s*[=:]\s*(?:bearer\s+|basic\s+)?)("[^"]*"|'[^']*'|[^\s,;&]+)`)
+
+var (
+	secretEnvValuesOnce sync.Once
+	secretEnvValues     []string
+)
+
+// RedactSecrets replaces secret-looking values in text: well-known token
+// formats, secret assignments and the literal values of secret environment
+// variables of this process.
+func RedactSecrets(text string) string {
+	if text == "" {
+		return text
+	}
+	for _, pattern := range secretValuePatterns {
+		text = pattern.ReplaceAllString(text, RedactedValue)
+	}
+	text = secretAssignmentPattern.ReplaceAllString(text, "${1}"+RedactedValue)
+	for _, value := range loadSecretEnvValues() {
+		text = strings.ReplaceAll(text, value, RedactedValue)
+	}
+	return text
+}
+
+// loadSecretEnvValues collects the values of secret-looking environment
+// variables once. Short values are skipped to avoid mangling unrelated output.
+func loadSecretEnvValues() []string {
+	secretEnvValuesOnce.Do(func() {
+		for _, kv := range os.Environ() {
+			key, value, _ := strings.Cut(kv, "=")
+			if IsSecretKey(key) && len(value) >= 8 {
+				secretEnvValues = append(secretEnvValues, value)
+			}
+		}
+	})
+	return secretEnvValues
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// BuildOptions configures building a server binary from a package before it
+// is run, instead of running a command such as "go run".
+type BuildOptions struct {
+	Package string   `json:"package"`           // package to build, default "."
+	Tags    []string `json:"tags,omitempty"`    // build tags
+	Race    bool     `json:"race,omitempty"`    // enable the race detector
+	LDFlags string   `json:"ldflags,omitempty"` // linker flags, e.g. "-X main.version=dev"
+}
+
+// validate checks the build options and fills in defaults
+func (bo *BuildOptions) validate(opts *ServerOptions) error {
+	if opts.Command != "" {
+		return errors.New("command cannot be set when the server is built from a package")
+	}
+	if bo.Package == "" {
+		bo.Package = "."
+	}
+	for _, tag := range bo.Tags {
+		if tag == "" || strings.ContainsAny(tag, ", ") {
+			return fmt.Errorf("invalid build tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// args returns the go build arguments writing the binary to output
+func (bo *BuildOptions) args(output string) []string {
+	args := []string{"build"}
+	if len(bo.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(bo.Tags, ","))
+	}
+	if bo.Race {
+		args = append(args, "-race")
+	}
+	if bo.LDFlags != "" {
+		args = append(args, "-ldflags", bo.LDFlags)
+	}
+	return append(args, "-o", output, bo.Package)
+}
+
+// binaryName returns the file name of the binary built from pkg
+func binaryName(pkg string) string {
+	name := path.Base(strings.TrimSuffix(filepath.ToSlash(pkg), "/"))
+	if name == "." || name == "/" || name == ".." {
+		name = "server"
+	}
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// serverBuildDir returns the directory the binaries of a server are built in
+func serverBuildDir(id string) string {
+	return filepath.Join(os.TempDir(), "mcp-go-builds", url.PathEscape(id))
+}
+
+// prepareBinary builds the server binary before the server is started, unless
+// a binary was just built by watch mode. It does nothing for servers run from
+// a command.
+func (sm *ServerManager) prepareBinary(ctx context.Context, serverInfo *ServerInfo) error {
+	serverInfo.logMutex.Lock()
+	build := serverInfo.opts.Build
+	fresh := serverInfo.rebuilt
+	serverInfo.rebuilt = false
+	serverInfo.logMutex.Unlock()
+
+	if build == nil || fresh {
+		return nil
+	}
+	status := sm.buildBinary(ctx, serverInfo)
+	if !status.Success {
+		return fmt.Errorf("build failed:\n%s", status.Output)
+	}
+	return nil
+}
+
+// buildBinary builds the server's package into a new directory under its
+// build directory for the host platform and makes the server run the new
+// binary from its next launch on. The result is recorded as the server's
+// last build; the previous binary is removed on success.
+func (sm *ServerManager) buildBinary(ctx context.Context, serverInfo *ServerInfo) *BuildStatus {
+	serverInfo.logMutex.RLock()
+	opts, cfg := serverInfo.opts, serverInfo.cfg
+	previous := serverInfo.opts.Command
+	serverInfo.logMutex.RUnlock()
+	bo := opts.Build
+
+	start := time.Now()
+	status := &BuildStatus{Time: start}
+	buildDir := serverBuildDir(opts.ID)
+	err := os.MkdirAll(buildDir, 0755)
+	var dir string
+	if err == nil {
+		dir, err = os.MkdirTemp(buildDir, "build-")
+	}
+	if err != nil {
+		status.Output = err.Error()
+		return sm.recordBuild(serverInfo, status)
+	}
+	binary := filepath.Join(dir, binaryName(bo.Package))
+	args := bo.args(binary)
+	status.Command = "go " + strings.Join(args, " ")
+
+	// The binary runs here, whatever GOOS and GOARCH are configured for builds
+	env := map[string]string{"GOOS": runtime.GOOS, "GOARCH": runtime.GOARCH}
+	for k, v := range opts.EnvVars {
+		if k != "GOOS" && k != "GOARCH" {
+			env[k] = v
+		}
+	}
+	result, err := ExecuteGoCommand(ctx, cfg, "go", args, opts.WorkingDir, env)
+	status.Duration = time.Since(start).Round(time.Millisecond).String()
+	switch {
+	case err != nil:
+		status.Output = err.Error()
+	case result.ExitCode != 0:
+		status.Output = strings.TrimSpace(result.Stderr)
+	default:
+		status.Success = true
+		status.Output = strings.TrimSpace(result.Stderr)
+		status.Binary = binary
+	}
+	if !status.Success {
+		os.RemoveAll(dir)
+		return sm.recordBuild(serverInfo, status)
+	}
+
+	serverInfo.logMutex.Lock()
+	serverInfo.opts.Command = binary
+	serverInfo.Command = binary
+	serverInfo.logMutex.Unlock()
+	if previous != "" && strings.HasPrefix(previous, buildDir+string(filepath.Separator)) {
+		// A running binary cannot be removed on Windows; it is left behind
+		os.RemoveAll(filepath.Dir(previous))
+	}
+	return sm.recordBuild(serverInfo, status)
+}
+
+// recordBuild stores the build as the server's last build and logs it
+func (sm *ServerManager) recordBuild(serverInfo *ServerInfo, status *BuildStatus) *BuildStatus {
+	serverInfo.logMutex.Lock()
+	serverInfo.lastBuild = status
+	serverInfo.logMutex.Unlock()
+
+	if status.Success {
+		serverInfo.addLog(StreamSystem, fmt.Sprintf("[build] built %s in %s", status.Binary, status.Duration))
+	} else {
+		serverInfo.addLog(StreamSystem, "[build] build failed")
+	}
+	if status.Output != "" {
+		for _, line := range strings.Split(status.Output, "\n") {
+			serverInfo.addLog(StreamStderr, "[build] "+line)
+		}
+	}
+	result := *status
+	return &result
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func TestBuildOptions_Args(t *testing.T) {
+	bo := &BuildOptions{Package: "./cmd/api", Tags: []string{"dev", "sqlite"}, Race: true, LDFlags: "-X main.version=dev"}
+	got := strings.Join(bo.args("/tmp/api"), " ")
+	want := "build -tags dev,sqlite -race -ldflags -X main.version=dev -o /tmp/api ./cmd/api"
+	if got != want {
+		t.Errorf("args() = %q, want %q", got, want)
+	}
+
+	if err := (&BuildOptions{}).validate(&ServerOptions{Command: "go"}); err == nil {
+		t.Error("Expected error when both a command and a package are given")
+	}
+	if err := (&BuildOptions{Tags: []string{"a,b"}}).validate(&ServerOptions{}); err == nil {
+		t.Error("Expected error for invalid build tag")
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	tests := map[string]string{
+		".":                        "server",
+		"./cmd/api":                "api",
+		"./cmd/api/":               "api",
+		"example.com/shop/cmd/web": "web",
+	}
+	for pkg, want := range tests {
+		if runtime.GOOS == "windows" {
+			want += ".exe"
+		}
+		if got := binaryName(pkg); got != want {
+			t.Errorf("binaryName(%q) = %q, want %q", pkg, got, want)
+		}
+	}
+}
+
+// writeServerModule writes a module whose main package prints its version
+// and whether it was built with the "extra" tag, then waits
+func writeServerModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/buildtest\n\ngo 1.21\n",
+		"main.go": `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+var version = "unset"
+
+var extra = false
+
+func main() {
+	fmt.Println("version", version, "extra", extra)
+	time.Sleep(time.Minute)
+}
+`,
+		"extra.go": "//go:build extra\n\npackage main\n\nfunc init() { extra = true }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestStartServer_Build(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("Go command not available")
+	}
+	dir := writeServerModule(t)
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: dir}
+	t.Cleanup(func() { os.RemoveAll(serverBuildDir("built")) })
+
+	serverInfo, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{
+		ID:    "built",
+		Build: &BuildOptions{Tags: []string{"extra"}, LDFlags: "-X main.version=1.2.3"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = sm.StopServer("built", true) })
+	if !waitForLog(serverInfo, "version 1.2.3 extra true", 10*time.Second) {
+		t.Fatalf("Expected the binary to run with its build options, got %v", serverInfo.Logs.GetAll())
+	}
+
+	build := serverInfo.BuildState()
+	if build == nil || !build.Success || !strings.HasPrefix(build.Binary, serverBuildDir("built")) {
+		t.Fatalf("Expected a successful build in the build directory, got %+v", build)
+	}
+	if serverInfo.Command != build.Binary || !strings.Contains(build.Command, "-tags extra") {
+		t.Errorf("Expected the server to run %s, got %s (%s)", build.Binary, serverInfo.Command, build.Command)
+	}
+
+	// Restarting rebuilds and removes the previous binary
+	if _, err := sm.RestartServer(context.Background(), "built"); err != nil {
+		t.Fatalf("Failed to restart server: %v", err)
+	}
+	rebuilt := serverInfo.BuildState()
+	if rebuilt.Binary == build.Binary {
+		t.Error("Expected a new binary after restart")
+	}
+	if _, err := os.Stat(build.Binary); !os.IsNotExist(err) {
+		t.Errorf("Expected the previous binary to be removed: %v", err)
+	}
+
+	if err := sm.StopServer("built", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.RemoveServer("built"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(serverBuildDir("built")); !os.IsNotExist(err) {
+		t.Errorf("Expected the build directory to be removed: %v", err)
+	}
+}
+
+func TestStartServer_BuildFails(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("Go command not available")
+	}
+	dir := writeServerModule(t)
+	if err := os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package main\n\nfunc broken() { undefined() }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: dir}
+	t.Cleanup(func() { os.RemoveAll(serverBuildDir("broken")) })
+
+	_, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{ID: "broken", Build: &BuildOptions{}})
+	if err == nil || !strings.Contains(err.Error(), "undefined: undefined") {
+		t.Errorf("Expected the compile error, got %v", err)
+	}
+	if _, err := sm.GetServer("broken"); err == nil {
+		t.Error("Expected a server that failed to build not to be registered")
+	}
+}
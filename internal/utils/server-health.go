@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Health states of a managed server
+const (
+	HealthStarting  = "starting"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+const (
+	defaultHealthInterval = time.Second
+	defaultProbeTimeout   = 2 * time.Second
+	healthFailureLimit    = 3
+)
+
+// HealthCheck configures readiness and liveness probing of a managed server.
+// Every configured probe must pass for the server to be healthy.
+type HealthCheck struct {
+	TCPAddress     string        `json:"tcp_address,omitempty"`     // host:port that must accept connections
+	HTTPURL        string        `json:"http_url,omitempty"`        // URL that must answer a GET
+	ExpectedStatus int           `json:"expected_status,omitempty"` // expected HTTP status, default 200
+	LogPattern     string        `json:"log_pattern,omitempty"`     // regex a log line must match once
+	Interval       time.Duration `json:"interval,omitempty"`        // time between probes
+	Timeout        time.Duration `json:"timeout,omitempty"`         // timeout of a single probe
+
+	logRegexp *regexp.Regexp
+}
+
+// validate checks the health check and fills in defaults
+func (hc *HealthCheck) validate() error {
+	if hc.TCPAddress == "" && hc.HTTPURL == "" && hc.LogPattern == "" {
+		return fmt.Errorf("health check requires a TCP address, HTTP URL or log pattern")
+	}
+	if hc.LogPattern != "" {
+		re, err := regexp.Compile(hc.LogPattern)
+		if err != nil {
+			return fmt.Errorf("invalid log pattern: %w", err)
+		}
+		hc.logRegexp = re
+	}
+	if hc.ExpectedStatus == 0 {
+		hc.ExpectedStatus = http.StatusOK
+	}
+	if hc.Interval <= 0 {
+		hc.Interval = defaultHealthInterval
+	}
+	if hc.Timeout <= 0 {
+		hc.Timeout = defaultProbeTimeout
+	}
+	return nil
+}
+
+// HealthState returns the current health state of the server and the error
+// of the last failed probe. The state is empty when no health check is configured.
+func (s *ServerInfo) HealthState() (string, string) {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	return s.health, s.healthErr
+}
+
+// monitorHealth probes the server while it runs until it is stopped. Each run
+// starts in the starting state, becomes healthy on the first successful probe
+// and unhealthy after several consecutive failures.
+func (sm *ServerManager) monitorHealth(ctx context.Context, serverInfo *ServerInfo, hc *HealthCheck) {
+	ticker := time.NewTicker(hc.Interval)
+	defer ticker.Stop()
+
+	var logs logProbe
+	failures := 0
+	var startTime time.Time
+	for {
+		serverInfo.logMutex.RLock()
+		running := serverInfo.Status == "running"
+		if !serverInfo.StartTime.Equal(startTime) {
+			// The server was restarted; count failures and look for the log
+			// pattern afresh, in output of the new process only
+			startTime = serverInfo.StartTime
+			failures = 0
+			logs = logProbe{afterSeq: serverInfo.runSeq}
+		}
+		serverInfo.logMutex.RUnlock()
+
+		if running {
+			err := probeServer(ctx, serverInfo, hc, &logs)
+
+			serverInfo.logMutex.Lock()
+			// Ignore the result if the process was restarted while probing
+			if serverInfo.StartTime.Equal(startTime) {
+				if err == nil {
+					failures = 0
+					serverInfo.health = HealthHealthy
+					serverInfo.healthErr = ""
+				} else {
+					failures++
+					serverInfo.healthErr = err.Error()
+					if serverInfo.health == HealthHealthy && failures >= healthFailureLimit {
+						serverInfo.health = HealthUnhealthy
+					}
+				}
+			}
+			serverInfo.logMutex.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// logProbe tracks the log pattern probe of one run of a server
+type logProbe struct {
+	afterSeq int64 // lines up to this sequence number have been searched
+	matched  bool
+}
+
+// probeServer runs every configured probe once
+func probeServer(ctx context.Context, serverInfo *ServerInfo, hc *HealthCheck, logs *logProbe) error {
+	if hc.logRegexp != nil && !logs.matched {
+		for _, line := range serverInfo.Lines.GetAll() {
+			if line.Seq <= logs.afterSeq {
+				continue
+			}
+			logs.afterSeq = line.Seq
+			if hc.logRegexp.MatchString(line.Text) {
+				logs.matched = true
+				break
+			}
+		}
+		if !logs.matched {
+			return fmt.Errorf("no log line matches %q yet", hc.LogPattern)
+		}
+	}
+
+	if hc.TCPAddress != "" {
+		conn, err := net.DialTimeout("tcp", tcpProbeAddress(hc.TCPAddress), hc.Timeout)
+		if err != nil {
+			return fmt.Errorf("TCP probe failed: %w", err)
+		}
+		conn.Close()
+	}
+
+	if hc.HTTPURL != "" {
+		ctx, cancel := context.WithTimeout(ctx, hc.Timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.HTTPURL, nil)
+		if err != nil {
+			return fmt.Errorf("HTTP probe failed: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("HTTP probe failed: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != hc.ExpectedStatus {
+			return fmt.Errorf("HTTP probe returned status %d, expected %d", resp.StatusCode, hc.ExpectedStatus)
+		}
+	}
+
+	return nil
+}
+
+// tcpProbeAddress probes loopback when the address has no host, e.g. ":8080"
+func tcpProbeAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err == nil && host == "" {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return address
+}
+
+// WaitReady blocks until the server is healthy, exits, or the timeout expires.
+func (sm *ServerManager) WaitReady(ctx context.Context, id string, timeout time.Duration) error {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		health, healthErr := serverInfo.HealthState()
+		if health == "" {
+			return fmt.Errorf("server %s has no health check", id)
+		}
+		if health == HealthHealthy {
+			return nil
+		}
+		serverInfo.logMutex.RLock()
+		status := serverInfo.Status
+		serverInfo.logMutex.RUnlock()
+		if status != "running" && status != "restarting" {
+			return fmt.Errorf("server %s exited before becoming ready", id)
+		}
+
+		select {
+		case <-ctx.Done():
+			if healthErr != "" {
+				return fmt.Errorf("server %s not ready after %v: %s", id, timeout, healthErr)
+			}
+			return fmt.Errorf("server %s not ready after %v", id, timeout)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func startHealthServer(t *testing.T, sm *ServerManager, id string, hc *HealthCheck, command string, args ...string) *ServerInfo {
+	t.Helper()
+	if _, err := exec.LookPath(command); err != nil {
+		t.Skipf("%s not available", command)
+	}
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	serverInfo, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{
+		ID:          id,
+		Name:        id,
+		Command:     command,
+		Args:        args,
+		HealthCheck: hc,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = sm.StopServer(id, true) })
+	return serverInfo
+}
+
+func TestHealthCheck_LogPattern(t *testing.T) {
+	sm := NewServerManager()
+	hc := &HealthCheck{LogPattern: `listening on :\d+`, Interval: 50 * time.Millisecond}
+	serverInfo := startHealthServer(t, sm, "health-log", hc, "sh", "-c", "sleep 0.2; echo listening on :8080; sleep 5")
+
+	if health, _ := serverInfo.HealthState(); health != HealthStarting {
+		t.Errorf("Expected initial health %q, got %q", HealthStarting, health)
+	}
+	if err := sm.WaitReady(context.Background(), "health-log", 5*time.Second); err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+	if health, _ := serverInfo.HealthState(); health != HealthHealthy {
+		t.Errorf("Expected health %q, got %q", HealthHealthy, health)
+	}
+}
+
+func TestHealthCheck_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen: %v", err)
+	}
+	defer listener.Close()
+
+	sm := NewServerManager()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	hc := &HealthCheck{TCPAddress: ":" + port, Interval: 50 * time.Millisecond}
+	startHealthServer(t, sm, "health-tcp", hc, "sleep", "5")
+
+	if err := sm.WaitReady(context.Background(), "health-tcp", 5*time.Second); err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+}
+
+func TestHealthCheck_HTTPNotReady(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer httpServer.Close()
+
+	sm := NewServerManager()
+	hc := &HealthCheck{HTTPURL: httpServer.URL, Interval: 50 * time.Millisecond}
+	serverInfo := startHealthServer(t, sm, "health-http", hc, "sleep", "5")
+
+	err := sm.WaitReady(context.Background(), "health-http", 300*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected not ready error mentioning status 503, got %v", err)
+	}
+	if health, _ := serverInfo.HealthState(); health != HealthStarting {
+		t.Errorf("Expected health %q, got %q", HealthStarting, health)
+	}
+}
+
+func TestHealthCheck_ExitBeforeReady(t *testing.T) {
+	sm := NewServerManager()
+	hc := &HealthCheck{LogPattern: "never", Interval: 50 * time.Millisecond}
+	startHealthServer(t, sm, "health-exit", hc, "true")
+
+	err := sm.WaitReady(context.Background(), "health-exit", 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "exited") {
+		t.Errorf("Expected exited error, got %v", err)
+	}
+}
+
+func TestHealthCheck_LogPatternAfterRestart(t *testing.T) {
+	sm := NewServerManager()
+	marker := filepath.Join(t.TempDir(), "started")
+	hc := &HealthCheck{LogPattern: "^ready$", Interval: 50 * time.Millisecond}
+	// Only the first run logs the pattern
+	serverInfo := startHealthServer(t, sm, "health-restart", hc, "sh", "-c",
+		`if [ -e "$0" ]; then echo again; else touch "$0"; echo ready; fi; sleep 5`, marker)
+	if err := sm.WaitReady(context.Background(), "health-restart", 5*time.Second); err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+
+	if _, err := sm.RestartServer(context.Background(), "health-restart"); err != nil {
+		t.Fatalf("RestartServer failed: %v", err)
+	}
+	if !waitForLog(serverInfo, "again", 5*time.Second) {
+		t.Fatal("Expected the restarted server to log")
+	}
+	time.Sleep(4 * hc.Interval)
+	if health, _ := serverInfo.HealthState(); health != HealthStarting {
+		t.Errorf("Expected health %q after restart, got %q", HealthStarting, health)
+	}
+}
+
+func TestHealthCheck_Validate(t *testing.T) {
+	if err := (&HealthCheck{}).validate(); err == nil {
+		t.Error("Expected error for health check without probes")
+	}
+	if err := (&HealthCheck{LogPattern: "("}).validate(); err == nil {
+		t.Error("Expected error for invalid log pattern")
+	}
+
+	hc := &HealthCheck{HTTPURL: "http://127.0.0.1:8080/healthz"}
+	if err := hc.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if hc.ExpectedStatus != http.StatusOK || hc.Interval <= 0 || hc.Timeout <= 0 {
+		t.Errorf("Expected defaults to be filled in, got %+v", hc)
+	}
+}
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout = 10 * time.Second
+	defaultHTTPMaxBody = 64 * 1024
+
+	// httpLogSettle is how long to wait after the response for log lines the
+	// server writes while finishing the request
+	httpLogSettle = 100 * time.Millisecond
+)
+
+// loopbackClient sends requests to managed servers. Redirects are returned
+// instead of followed, so a server cannot send a request on to another host.
+var loopbackClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// HTTPRequest is a request to send to a managed server over loopback.
+type HTTPRequest struct {
+	Method  string
+	Path    string // path and query, e.g. "/api/items?limit=5"
+	Port    string // allocated port name or port number; found from the server if empty
+	Headers map[string]string
+	Body    string
+	Timeout time.Duration
+	MaxBody int // maximum number of body bytes to return
+}
+
+// HTTPResponse is the response of a managed server to an HTTP request.
+type HTTPResponse struct {
+	URL       string              `json:"url"`
+	Status    int                 `json:"status"`
+	Headers   map[string][]string `json:"headers"`
+	Body      string              `json:"body"`
+	BodySize  int64               `json:"body_size"`
+	Truncated bool                `json:"truncated"`
+	Duration  string              `json:"duration"`
+	Logs      []LogLine           `json:"logs,omitempty"` // lines the server logged during the request
+}
+
+// SendHTTP sends an HTTP request to a managed server on 127.0.0.1. Redirects
+// are returned rather than followed, so requests never leave loopback.
+func (sm *ServerManager) SendHTTP(ctx context.Context, id string, req HTTPRequest) (*HTTPResponse, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+	port, err := serverInfo.httpPort(req.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	path := req.Path
+	if path == "" {
+		path = "/"
+	}
+	ref, err := url.Parse(path)
+	if err != nil || ref.Scheme != "" || ref.Host != "" || !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path must be an absolute path such as /healthz, got %q", path)
+	}
+	target := "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)) + path
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	maxBody := req.MaxBody
+	if maxBody <= 0 {
+		maxBody = defaultHTTPMaxBody
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, method, target, strings.NewReader(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	for key, value := range req.Headers {
+		if strings.EqualFold(key, "Host") {
+			httpReq.Host = value
+			continue
+		}
+		httpReq.Header.Set(key, value)
+	}
+
+	afterSeq := serverInfo.Lines.LastSeq()
+	start := time.Now()
+	resp, err := loopbackClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBody)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	size := int64(len(body))
+	// Count the rest of the body without keeping it
+	rest, _ := io.Copy(io.Discard, resp.Body)
+	size += rest
+	duration := time.Since(start)
+
+	result := &HTTPResponse{
+		URL:      target,
+		Status:   resp.StatusCode,
+		Headers:  resp.Header,
+		BodySize: size,
+		Duration: duration.Round(time.Microsecond).String(),
+	}
+	if len(body) > maxBody {
+		body = body[:maxBody]
+		result.Truncated = true
+	}
+	result.Body = string(body)
+
+	time.Sleep(httpLogSettle)
+	for _, line := range serverInfo.Lines.GetAll() {
+		if line.Seq > afterSeq {
+			result.Logs = append(result.Logs, line)
+		}
+	}
+	return result, nil
+}
+
+// httpPort returns the port to send requests to: the named or numbered port
+// if given, otherwise the allocated "http" port or only allocated port, the
+// port of the health check, or the only port the server listens on. A
+// numbered port must be one of these ports of the server.
+func (s *ServerInfo) httpPort(name string) (int, error) {
+	ports := s.Ports()
+	if name != "" {
+		if port, ok := ports[name]; ok {
+			return port, nil
+		}
+		port, err := strconv.Atoi(name)
+		if err != nil {
+			return 0, fmt.Errorf("server %s has no port named %s", s.ID, name)
+		}
+		allowed := s.knownPorts()
+		for _, p := range allowed {
+			if p == port {
+				return port, nil
+			}
+		}
+		if len(allowed) == 0 {
+			return 0, fmt.Errorf("port %d is not a port of server %s, which has no known ports", port, s.ID)
+		}
+		list := make([]string, len(allowed))
+		for i, p := range allowed {
+			list[i] = strconv.Itoa(p)
+		}
+		return 0, fmt.Errorf("port %d is not a port of server %s; allowed ports: %s", port, s.ID, strings.Join(list, ", "))
+	}
+
+	if port, ok := ports["http"]; ok {
+		return port, nil
+	}
+	if len(ports) == 1 {
+		for _, port := range ports {
+			return port, nil
+		}
+	}
+	if len(ports) > 1 {
+		names := make([]string, 0, len(ports))
+		for name := range ports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return 0, fmt.Errorf("server %s has several ports (%s); choose one", s.ID, strings.Join(names, ", "))
+	}
+
+	if port := s.healthCheckPort(); port != 0 {
+		return port, nil
+	}
+	if listening := s.listenPorts(); len(listening) == 1 {
+		return listening[0], nil
+	}
+	return 0, fmt.Errorf("cannot tell which port server %s listens on; specify a port", s.ID)
+}
+
+// knownPorts returns the allocated ports of the server, the port of its
+// health check and the ports it listens on, sorted and without duplicates.
+func (s *ServerInfo) knownPorts() []int {
+	seen := make(map[int]bool)
+	for _, port := range s.Ports() {
+		seen[port] = true
+	}
+	if port := s.healthCheckPort(); port != 0 {
+		seen[port] = true
+	}
+	for _, port := range s.listenPorts() {
+		seen[port] = true
+	}
+	ports := make([]int, 0, len(seen))
+	for port := range seen {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// healthCheckPort returns the loopback port probed by the health check, or 0
+func (s *ServerInfo) healthCheckPort() int {
+	hc := s.HealthCheck
+	if hc == nil {
+		return 0
+	}
+	if hc.HTTPURL != "" {
+		if u, err := url.Parse(hc.HTTPURL); err == nil && isLoopbackHost(u.Hostname()) {
+			if port, err := strconv.Atoi(u.Port()); err == nil {
+				return port
+			}
+		}
+	}
+	if hc.TCPAddress != "" {
+		if host, portStr, err := net.SplitHostPort(hc.TCPAddress); err == nil && (host == "" || isLoopbackHost(host)) {
+			if port, err := strconv.Atoi(portStr); err == nil {
+				return port
+			}
+		}
+	}
+	return 0
+}
+
+// listenPorts returns the ports the server was last seen listening on
+func (s *ServerInfo) listenPorts() []int {
+	if usage := s.ResourceUsage(); usage != nil && usage.Latest != nil {
+		return usage.Latest.ListenPorts
+	}
+	return nil
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback address
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
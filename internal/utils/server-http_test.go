@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newHTTPServer registers a managed server backed by handler, with its port
+// allocated under the name "http"
+func newHTTPServer(t *testing.T, sm *ServerManager, id string, handler func(*ServerInfo, http.ResponseWriter, *http.Request)) *ServerInfo {
+	t.Helper()
+	serverInfo := &ServerInfo{
+		ID:         id,
+		Logs:       NewRingBuffer(100),
+		StdoutLogs: NewRingBuffer(100),
+		StderrLogs: NewRingBuffer(100),
+		Lines:      NewLogBuffer(100),
+		Metadata:   make(map[string]interface{}),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(serverInfo, w, r)
+	}))
+	t.Cleanup(ts.Close)
+	serverInfo.Metadata["ports"] = map[string]int{"http": ts.Listener.Addr().(*net.TCPAddr).Port}
+	sm.servers.Store(id, serverInfo)
+	return serverInfo
+}
+
+func TestSendHTTP(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := newHTTPServer(t, sm, "api", func(s *ServerInfo, w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.addLog(StreamStdout, r.Method+" "+r.URL.String()+" "+string(body))
+		w.Header().Set("X-Token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, strings.Repeat("x", 100))
+	})
+	serverInfo.addLog(StreamStdout, "before the request")
+
+	resp, err := sm.SendHTTP(context.Background(), "api", HTTPRequest{
+		Method:  "post",
+		Path:    "/items?limit=5",
+		Headers: map[string]string{"Authorization": "Bearer abc"},
+		Body:    `{"name":"a"}`,
+		MaxBody: 10,
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Status != http.StatusCreated || resp.Headers["X-Token"][0] != "Bearer abc" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if resp.Body != "xxxxxxxxxx" || !resp.Truncated || resp.BodySize != 100 {
+		t.Errorf("Expected body truncated to 10 of 100 bytes, got %q (%d bytes)", resp.Body, resp.BodySize)
+	}
+	if len(resp.Logs) != 1 || resp.Logs[0].Text != `POST /items?limit=5 {"name":"a"}` {
+		t.Errorf("Expected the request's log line only, got %+v", resp.Logs)
+	}
+	if !strings.HasPrefix(resp.URL, "http://127.0.0.1:") {
+		t.Errorf("Expected a loopback URL, got %s", resp.URL)
+	}
+}
+
+func TestSendHTTP_Redirect(t *testing.T) {
+	sm := NewServerManager()
+	newHTTPServer(t, sm, "redirect", func(s *ServerInfo, w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com/", http.StatusFound)
+	})
+
+	resp, err := sm.SendHTTP(context.Background(), "redirect", HTTPRequest{})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Status != http.StatusFound {
+		t.Errorf("Expected the redirect to be returned, got %d", resp.Status)
+	}
+}
+
+func TestSendHTTP_InvalidPath(t *testing.T) {
+	sm := NewServerManager()
+	newHTTPServer(t, sm, "api", func(s *ServerInfo, w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"http://example.com/", "//example.com/x", "items"} {
+		if _, err := sm.SendHTTP(context.Background(), "api", HTTPRequest{Path: path}); err == nil {
+			t.Errorf("Expected error for path %q", path)
+		}
+	}
+	if _, err := sm.SendHTTP(context.Background(), "unknown", HTTPRequest{}); err == nil {
+		t.Error("Expected error for unknown server")
+	}
+}
+
+func TestHTTPPort_AllowedPortsInError(t *testing.T) {
+	server := &ServerInfo{ID: "api", Metadata: map[string]interface{}{"ports": map[string]int{"http": 8080}}, HealthCheck: &HealthCheck{TCPAddress: ":7071"}}
+	_, err := server.httpPort("22")
+	if err == nil || !strings.Contains(err.Error(), "allowed ports: 7071, 8080") {
+		t.Errorf("Expected an error naming the allowed ports, got %v", err)
+	}
+}
+
+func TestHTTPPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  *ServerInfo
+		port    string
+		want    int
+		wantErr bool
+	}{
+		{"named", &ServerInfo{Metadata: map[string]interface{}{"ports": map[string]int{"admin": 9000, "http": 8080}}}, "admin", 9000, false},
+		{"allocated number", &ServerInfo{Metadata: map[string]interface{}{"ports": map[string]int{"http": 8080}}}, "8080", 8080, false},
+		{"health number", &ServerInfo{HealthCheck: &HealthCheck{TCPAddress: "127.0.0.1:7072"}}, "7072", 7072, false},
+		{"listening number", &ServerInfo{samples: []ResourceSample{{ListenPorts: []int{6060, 6061}}}}, "6061", 6061, false},
+		{"other number", &ServerInfo{Metadata: map[string]interface{}{"ports": map[string]int{"http": 8080}}}, "22", 0, true},
+		{"number without ports", &ServerInfo{}, "8081", 0, true},
+		{"unknown name", &ServerInfo{}, "admin", 0, true},
+		{"http preferred", &ServerInfo{Metadata: map[string]interface{}{"ports": map[string]int{"admin": 9000, "http": 8080}}}, "", 8080, false},
+		{"only port", &ServerInfo{Metadata: map[string]interface{}{"ports": map[string]int{"api": 9000}}}, "", 9000, false},
+		{"ambiguous", &ServerInfo{Metadata: map[string]interface{}{"ports": map[string]int{"a": 1, "b": 2}}}, "", 0, true},
+		{"health URL", &ServerInfo{HealthCheck: &HealthCheck{HTTPURL: "http://localhost:7070/healthz"}}, "", 7070, false},
+		{"health TCP", &ServerInfo{HealthCheck: &HealthCheck{TCPAddress: ":7071"}}, "", 7071, false},
+		{"remote health URL", &ServerInfo{HealthCheck: &HealthCheck{HTTPURL: "http://example.com:80/"}}, "", 0, true},
+		{"listening port", &ServerInfo{samples: []ResourceSample{{ListenPorts: []int{6060}}}}, "", 6060, false},
+		{"nothing known", &ServerInfo{}, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.server.httpPort(tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("httpPort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("httpPort() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDumpWait bounds how long to wait for a goroutine dump after
+	// signalling a server
+	defaultDumpWait = 5 * time.Second
+	// dumpQuietPeriod is how long stderr must stay quiet for a dump to be
+	// considered complete
+	dumpQuietPeriod = 300 * time.Millisecond
+	// defaultInputTimeout bounds how long to wait for a server to read the
+	// input written to it
+	defaultInputTimeout = 10 * time.Second
+)
+
+var errSignalsUnsupported = errors.New("sending signals is not supported on this platform")
+
+// SendInput writes text to the standard input of a running server. If
+// closeInput is set, standard input is closed afterwards so the server
+// reads end of file. The write fails if the server does not read it before
+// ctx is done or the default timeout expires; it then completes in the
+// background once the server reads its input or exits.
+func (sm *ServerManager) SendInput(ctx context.Context, id, text string, closeInput bool) (int, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return 0, err
+	}
+
+	// The write happens outside the lock as it blocks while the server does
+	// not read its input
+	serverInfo.logMutex.RLock()
+	status := serverInfo.Status
+	stdin := serverInfo.stdin
+	serverInfo.logMutex.RUnlock()
+	if status != "running" {
+		return 0, fmt.Errorf("server is not running: %s", id)
+	}
+	if stdin == nil {
+		return 0, fmt.Errorf("standard input of server %s is closed", id)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultInputTimeout)
+	defer cancel()
+	type writeResult struct {
+		n   int
+		err error
+	}
+	written := make(chan writeResult, 1)
+	go func() {
+		n, err := io.WriteString(stdin, text)
+		written <- writeResult{n, err}
+	}()
+	var n int
+	select {
+	case result := <-written:
+		if result.err != nil {
+			return result.n, fmt.Errorf("failed to write to server %s: %w", id, result.err)
+		}
+		n = result.n
+	case <-ctx.Done():
+		return 0, fmt.Errorf("server %s is not reading its standard input: %w", id, ctx.Err())
+	}
+	if closeInput {
+		serverInfo.logMutex.Lock()
+		if serverInfo.stdin == stdin {
+			serverInfo.stdin = nil
+		}
+		serverInfo.logMutex.Unlock()
+		if err := stdin.Close(); err != nil {
+			return n, fmt.Errorf("failed to close standard input: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// SignalOptions configures sending a signal to a server.
+type SignalOptions struct {
+	ProcessOnly bool          // signal only the server process, not its process group
+	CaptureDump bool          // wait for a goroutine dump on stderr, always done for SIGQUIT
+	DumpWait    time.Duration // how long to wait for the dump, default 5s
+}
+
+// SignalResult reports the signal sent to a server and any goroutine dump
+// the server wrote in response.
+type SignalResult struct {
+	Signal    string            `json:"signal"`
+	Target    string            `json:"target"` // "process group" or "process"
+	Dump      *GoroutineSummary `json:"dump,omitempty"`
+	DumpLines []string          `json:"dump_lines,omitempty"`
+}
+
+// SignalServer sends a signal such as SIGHUP, SIGUSR1 or SIGQUIT to a running
+// server and its process group. After SIGQUIT, which makes Go programs print
+// the stacks of all goroutines and exit, the dump is read from stderr and
+// summarized.
+func (sm *ServerManager) SignalServer(id, name string, opts SignalOptions) (*SignalResult, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+	sig, sigName, err := parseSignal(name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverInfo.logMutex.RLock()
+	status := serverInfo.Status
+	pid := serverInfo.PID
+	serverInfo.logMutex.RUnlock()
+	if status != "running" {
+		return nil, fmt.Errorf("server is not running: %s", id)
+	}
+
+	result := &SignalResult{Signal: sigName, Target: "process group"}
+	if opts.ProcessOnly {
+		result.Target = "process"
+	}
+	afterSeq := serverInfo.Lines.LastSeq()
+	if err := sendSignal(pid, sig, !opts.ProcessOnly); err != nil {
+		if errors.Is(err, errProcessGone) {
+			return nil, fmt.Errorf("server is not running: %s", id)
+		}
+		return nil, fmt.Errorf("failed to send %s: %w", sigName, err)
+	}
+	serverInfo.addLog(StreamSystem, fmt.Sprintf("[signal] sent %s to %s", sigName, result.Target))
+
+	if sigName == "SIGQUIT" || opts.CaptureDump {
+		wait := opts.DumpWait
+		if wait <= 0 {
+			wait = defaultDumpWait
+		}
+		result.DumpLines = waitForStderr(serverInfo, afterSeq, wait)
+		result.Dump = ParseGoroutineDump(result.DumpLines)
+	}
+	return result, nil
+}
+
+// waitForStderr collects the stderr lines logged after afterSeq until stderr
+// has been quiet for a while after the first line, or the timeout expires.
+func waitForStderr(serverInfo *ServerInfo, afterSeq int64, timeout time.Duration) []string {
+	deadline := time.Now().Add(timeout)
+	lastSeq := afterSeq
+	lastChange := time.Now()
+	for time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+		if seq := serverInfo.Lines.LastSeq(); seq != lastSeq {
+			lastSeq = seq
+			lastChange = time.Now()
+			continue
+		}
+		if time.Since(lastChange) < dumpQuietPeriod {
+			continue
+		}
+		if lines := stderrSince(serverInfo, afterSeq); len(lines) > 0 {
+			return lines
+		}
+	}
+	return stderrSince(serverInfo, afterSeq)
+}
+
+// stderrSince returns the text of the stderr lines logged after afterSeq
+func stderrSince(serverInfo *ServerInfo, afterSeq int64) []string {
+	var lines []string
+	for _, line := range serverInfo.Lines.GetAll() {
+		if line.Seq > afterSeq && line.Stream == StreamStderr {
+			lines = append(lines, line.Text)
+		}
+	}
+	return lines
+}
+
+// normalizeSignalName returns the upper-case name of a signal with the SIG
+// prefix, e.g. "SIGHUP" for "hup"
+func normalizeSignalName(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	return name
+}
@@ -0,0 +1,135 @@
+//go:build unix
+
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendInput(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "input",
+		Command: "sh",
+		Args:    []string{"-c", `while read line; do echo "got $line"; done; echo eof; sleep 30`},
+	})
+
+	if _, err := sm.SendInput(context.Background(), "input", "hello\n", false); err != nil {
+		t.Fatalf("Failed to send input: %v", err)
+	}
+	if !waitForLog(serverInfo, "got hello", 5*time.Second) {
+		t.Error("Expected the server to read the input")
+	}
+
+	if _, err := sm.SendInput(context.Background(), "input", "bye\n", true); err != nil {
+		t.Fatalf("Failed to send input: %v", err)
+	}
+	if !waitForLog(serverInfo, "eof", 5*time.Second) {
+		t.Error("Expected the server to read end of file")
+	}
+	if _, err := sm.SendInput(context.Background(), "input", "more\n", false); err == nil {
+		t.Error("Expected error after standard input was closed")
+	}
+}
+
+func TestSendInput_NotRead(t *testing.T) {
+	sm := NewServerManager()
+	startRestartServer(t, sm, ServerOptions{
+		ID:      "ignores-input",
+		Command: "sleep",
+		Args:    []string{"30"},
+	})
+
+	// More than fits in the pipe buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := sm.SendInput(ctx, "ignores-input", strings.Repeat("x", 1<<20), false)
+	if err == nil || !strings.Contains(err.Error(), "not reading its standard input") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected SendInput to return after the timeout, took %s", elapsed)
+	}
+}
+
+func TestSignalServer(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "signal",
+		Command: "sh",
+		Args:    []string{"-c", `trap "echo reload" HUP; echo ready; while :; do sleep 0.1; done`},
+	})
+	if !waitForLog(serverInfo, "ready", 5*time.Second) {
+		t.Fatal("Expected server to start")
+	}
+
+	result, err := sm.SignalServer("signal", "hup", SignalOptions{ProcessOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to send signal: %v", err)
+	}
+	if result.Signal != "SIGHUP" || result.Target != "process" || result.Dump != nil {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !waitForLog(serverInfo, "reload", 5*time.Second) {
+		t.Error("Expected the server to handle SIGHUP")
+	}
+	if !serverInfo.IsRunning() {
+		t.Error("Expected server to keep running")
+	}
+
+	if _, err := sm.SignalServer("signal", "SIGBOGUS", SignalOptions{}); err == nil {
+		t.Error("Expected error for unknown signal")
+	}
+}
+
+func TestSignalServer_GoroutineDump(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("Go command not available")
+	}
+	dir := t.TempDir()
+	program := `package main
+
+import "time"
+
+func main() {
+	for i := 0; i < 3; i++ {
+		go func() { select {} }()
+	}
+	println("ready")
+	time.Sleep(time.Minute)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(program), 0644); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "dumper")
+	build := exec.Command("go", "build", "-o", binary, "main.go")
+	build.Dir = dir
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build test program: %v\n%s", err, out)
+	}
+
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{ID: "dump", Command: binary})
+	if !waitForLog(serverInfo, "ready", 5*time.Second) {
+		t.Fatal("Expected server to start")
+	}
+
+	result, err := sm.SignalServer("dump", "QUIT", SignalOptions{})
+	if err != nil {
+		t.Fatalf("Failed to send signal: %v", err)
+	}
+	if result.Dump == nil {
+		t.Fatalf("Expected a goroutine dump, got lines %q", result.DumpLines)
+	}
+	if result.Dump.ByState["select (no cases)"] != 3 {
+		t.Errorf("Expected 3 blocked goroutines, got %v", result.Dump.ByState)
+	}
+}
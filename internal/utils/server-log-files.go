@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// serverLogPath returns the active log file of a server. The ID is escaped
+// so that it cannot name a path outside the log directory.
+func serverLogPath(dir, id string) string {
+	return filepath.Join(dir, url.PathEscape(id)+".log")
+}
+
+// openServerLog opens the log file of a server for appending and returns the
+// sequence number of the last line already in it, so numbering continues
+// across runs of the MCP server.
+func openServerLog(cfg *config.Config, id string) (*rotatingFile, int64, error) {
+	path := serverLogPath(cfg.ServerLogDir, id)
+	var lastSeq int64
+	if lines, err := readServerLogFiles(path, cfg.ServerLogMaxBackups); err == nil && len(lines) > 0 {
+		lastSeq = lines[len(lines)-1].Seq
+	}
+	rf, err := openRotatingFile(path, cfg.ServerLogMaxSize, cfg.ServerLogMaxBackups)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rf, lastSeq, nil
+}
+
+// writeLogLine appends a line to the server's log file as JSON
+func (s *ServerInfo) writeLogLine(line LogLine) {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	_, _ = s.logFile.Write(append(data, '\n'))
+}
+
+// readServerLogFiles reads the lines of a server's log file and its
+// backups, oldest first.
+func readServerLogFiles(path string, maxBackups int) ([]LogLine, error) {
+	var lines []LogLine
+	found := false
+	for i := maxBackups; i >= 0; i-- {
+		name := path
+		if i > 0 {
+			name = backupPath(path, i)
+		}
+		fileLines, err := readServerLogFile(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		lines = append(lines, fileLines...)
+	}
+	if !found {
+		return nil, fs.ErrNotExist
+	}
+	return lines, nil
+}
+
+// readServerLogFile reads all well-formed lines from a JSON-lines log file
+func readServerLogFile(path string) ([]LogLine, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []LogLine
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var line LogLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err == nil {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// QueryServerLogFiles queries the on-disk logs of a server, which remain
+// after the server exits and across restarts of the MCP server.
+func QueryServerLogFiles(cfg *config.Config, id string, filter LogFilter) (*LogQueryResult, error) {
+	if cfg.ServerLogDir == "" {
+		return nil, fmt.Errorf("server log files are disabled")
+	}
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	lines, err := readServerLogFiles(serverLogPath(cfg.ServerLogDir, id), cfg.ServerLogMaxBackups)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no log files for server: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server logs: %w", err)
+	}
+	result := queryLogLines(lines, filter)
+	result.Source = LogSourceDisk
+	return result, nil
+}
+
+// ServerLogFile describes a log file in the server log directory.
+type ServerLogFile struct {
+	ServerID string    `json:"server_id"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+}
+
+// ListServerLogFiles returns the log files in the server log directory,
+// including rotated backups.
+func ListServerLogFiles(cfg *config.Config) ([]ServerLogFile, error) {
+	if cfg.ServerLogDir == "" {
+		return nil, fmt.Errorf("server log files are disabled")
+	}
+	entries, err := os.ReadDir(cfg.ServerLogDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []ServerLogFile
+	for _, entry := range entries {
+		id, ok := serverIDFromLogFile(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, ServerLogFile{
+			ServerID: id,
+			Path:     filepath.Join(cfg.ServerLogDir, entry.Name()),
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// serverIDFromLogFile returns the server ID of a log file name such as
+// "api.log" or "api.log.2"
+func serverIDFromLogFile(name string) (string, bool) {
+	base := name
+	if idx := strings.LastIndex(name, ".log."); idx >= 0 {
+		if _, err := strconv.Atoi(name[idx+len(".log."):]); err != nil {
+			return "", false
+		}
+		base = name[:idx+len(".log")]
+	}
+	escaped, ok := strings.CutSuffix(base, ".log")
+	if !ok || escaped == "" {
+		return "", false
+	}
+	id, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// PurgeServerLogs removes server log files last modified before the cutoff.
+// If id is set only that server's files are considered. Files of servers
+// that are still running or restarting are kept. With dryRun nothing is removed.
+func (sm *ServerManager) PurgeServerLogs(cfg *config.Config, id string, cutoff time.Time, dryRun bool) ([]ServerLogFile, error) {
+	files, err := ListServerLogFiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []ServerLogFile
+	for _, file := range files {
+		if id != "" && file.ServerID != id {
+			continue
+		}
+		if !file.ModTime.Before(cutoff) {
+			continue
+		}
+		serverInfo, err := sm.GetServer(file.ServerID)
+		if err == nil {
+			if status := serverInfo.ProcessState().Status; status == "running" || status == "restarting" {
+				continue
+			}
+		}
+		if !dryRun {
+			if err := os.Remove(file.Path); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", file.Path, err)
+			}
+			// A stopped server may be restarted, so give it a fresh log file
+			if serverInfo != nil && serverInfo.logFile != nil && serverInfo.logFile.path == file.Path {
+				_ = serverInfo.logFile.Reopen()
+			}
+		}
+		removed = append(removed, file)
+	}
+	return removed, nil
+}
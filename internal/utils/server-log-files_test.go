@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func newLogFileConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		DisableNotifications: true,
+		WorkingDirectory:     t.TempDir(),
+		ServerLogDir:         t.TempDir(),
+		ServerLogMaxSize:     1024 * 1024,
+		ServerLogMaxBackups:  2,
+		ServerLogRetention:   time.Hour,
+	}
+}
+
+func TestServerLogPath(t *testing.T) {
+	for _, id := range []string{"api", "../escape", "a/b", "name with spaces"} {
+		path := serverLogPath("/logs", id)
+		if filepath.Dir(path) != "/logs" {
+			t.Errorf("Log file of %q escapes the log directory: %s", id, path)
+		}
+		got, ok := serverIDFromLogFile(filepath.Base(path))
+		if !ok || got != id {
+			t.Errorf("serverIDFromLogFile(%q) = %q, %v, want %q", filepath.Base(path), got, ok, id)
+		}
+	}
+
+	if id, ok := serverIDFromLogFile("api.log.2"); !ok || id != "api" {
+		t.Errorf("Expected backup to belong to api, got %q, %v", id, ok)
+	}
+	for _, name := range []string{"notes.txt", ".log", "api.log.old"} {
+		if _, ok := serverIDFromLogFile(name); ok {
+			t.Errorf("Expected %q not to be a server log file", name)
+		}
+	}
+}
+
+func TestServerLogFiles_Persisted(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	cfg := newLogFileConfig(t)
+
+	run := func(sm *ServerManager) *ServerInfo {
+		t.Helper()
+		serverInfo, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{
+			ID:      "persisted",
+			Command: "sh",
+			Args:    []string{"-c", "echo one; echo two >&2"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to start server: %v", err)
+		}
+		if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+			t.Fatal("Expected server to exit")
+		}
+		return serverInfo
+	}
+
+	run(NewServerManager())
+
+	// The logs outlive the server manager
+	result, err := QueryServerLogFiles(cfg, "persisted", LogFilter{})
+	if err != nil {
+		t.Fatalf("Failed to query log files: %v", err)
+	}
+	if len(result.Records) != 2 || result.Source != LogSourceDisk {
+		t.Fatalf("Expected 2 lines from disk, got %+v", result)
+	}
+	for _, record := range result.Records {
+		if record.Text == "two" && record.Stream != StreamStderr {
+			t.Errorf("Expected stderr line to keep its stream, got %+v", record)
+		}
+	}
+
+	// A new run continues the numbering
+	serverInfo := run(NewServerManager())
+	if lines := serverInfo.Lines.GetAll(); len(lines) != 2 || lines[0].Seq != 3 {
+		t.Errorf("Expected numbering to continue at 3, got %+v", lines)
+	}
+	result, err = QueryServerLogFiles(cfg, "persisted", LogFilter{AfterSeq: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Records) != 2 || result.NextCursor != 4 {
+		t.Errorf("Expected the lines of the second run, got %+v", result)
+	}
+
+	if _, err := QueryServerLogFiles(cfg, "unknown", LogFilter{}); err == nil {
+		t.Error("Expected error for server without log files")
+	}
+}
+
+func TestServerLogFiles_Rotation(t *testing.T) {
+	cfg := newLogFileConfig(t)
+	cfg.ServerLogMaxSize = 200
+
+	rf, _, err := openServerLog(cfg, "rotated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+	serverInfo := &ServerInfo{
+		ID:         "rotated",
+		Logs:       NewRingBuffer(2),
+		StdoutLogs: NewRingBuffer(2),
+		StderrLogs: NewRingBuffer(2),
+		Lines:      NewLogBuffer(2),
+		logFile:    rf,
+	}
+	for i := 0; i < 6; i++ {
+		serverInfo.addLog(StreamStdout, "a line that is long enough to force rotation")
+	}
+
+	if _, err := os.Stat(backupPath(rf.path, 1)); err != nil {
+		t.Fatalf("Expected a rotated backup: %v", err)
+	}
+	lines, err := readServerLogFiles(rf.path, cfg.ServerLogMaxBackups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i].Seq != lines[i-1].Seq+1 {
+			t.Fatalf("Expected consecutive lines across backups, got %+v", lines)
+		}
+	}
+	if lines[len(lines)-1].Seq != 6 {
+		t.Errorf("Expected last line 6, got %d", lines[len(lines)-1].Seq)
+	}
+
+	// Evicted lines after the cursor are read back from disk
+	sm := NewServerManager()
+	sm.servers.Store("rotated", serverInfo)
+	result, err := sm.QueryServerLogs("rotated", LogFilter{AfterSeq: lines[0].Seq - 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Source != LogSourceDisk || len(result.Records) != len(lines) {
+		t.Errorf("Expected %d lines from disk, got %d from %s", len(lines), len(result.Records), result.Source)
+	}
+}
+
+func TestPurgeServerLogs(t *testing.T) {
+	cfg := newLogFileConfig(t)
+	old := time.Now().Add(-2 * time.Hour)
+	for _, name := range []string{"old.log", "old.log.1", "recent.log", "other.log", "notes.txt"} {
+		path := filepath.Join(cfg.ServerLogDir, name)
+		if err := os.WriteFile(path, []byte("{}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if name != "recent.log" {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	sm := NewServerManager()
+	cutoff := time.Now().Add(-cfg.ServerLogRetention)
+
+	removed, err := sm.PurgeServerLogs(cfg, "old", cutoff, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 {
+		t.Fatalf("Expected 2 files in dry run, got %+v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.ServerLogDir, "old.log")); err != nil {
+		t.Error("Expected dry run to keep files")
+	}
+
+	removed, err = sm.PurgeServerLogs(cfg, "", cutoff, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 3 {
+		t.Fatalf("Expected 3 files removed, got %+v", removed)
+	}
+	for name, exists := range map[string]bool{"old.log": false, "other.log": false, "recent.log": true, "notes.txt": true} {
+		_, err := os.Stat(filepath.Join(cfg.ServerLogDir, name))
+		if (err == nil) != exists {
+			t.Errorf("Expected %s to exist: %v", name, exists)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// logLevels orders the normalized log levels by severity
+var logLevels = map[string]int{
+	"trace": 0,
+	"debug": 1,
+	"info":  2,
+	"warn":  3,
+	"error": 4,
+	"fatal": 5,
+}
+
+// levelAliases maps level names used by common loggers to normalized levels
+var levelAliases = map[string]string{
+	"trace":    "trace",
+	"debug":    "debug",
+	"info":     "info",
+	"notice":   "info",
+	"warn":     "warn",
+	"warning":  "warn",
+	"error":    "error",
+	"err":      "error",
+	"dpanic":   "fatal",
+	"panic":    "fatal",
+	"fatal":    "fatal",
+	"critical": "fatal",
+}
+
+// logfmtLevel matches a level in logfmt output such as slog's text handler
+var logfmtLevel = regexp.MustCompile(`(?i)\b(?:level|lvl)=["]?([a-z]+)`)
+
+// textLevel matches a level near the start of a plain text line, either
+// bracketed like "[warn]" or upper case like "ERROR:", after an optional timestamp
+var textLevel = regexp.MustCompile(`^(?:\S+\s+){0,2}(?:\[(?i:(trace|debug|info|warn|warning|error|fatal|panic))\]|(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|PANIC)\b)`)
+
+// Sources of log query results
+const (
+	LogSourceMemory = "memory"
+	LogSourceDisk   = "disk"
+)
+
+// LogFilter selects log lines of a managed server. Zero values match everything.
+type LogFilter struct {
+	Stream   string            // "stdout", "stderr" or "system"
+	Since    time.Time         // lines logged at or after this time
+	Until    time.Time         // lines logged before this time
+	Pattern  string            // regular expression the line must match
+	Contains string            // substring the line must contain
+	Level    string            // minimum level, e.g. "warn"
+	Fields   map[string]string // values JSON lines must have; nested fields use dots
+	AfterSeq int64             // cursor: only lines with a greater sequence number
+	Limit    int               // maximum number of records
+
+	pattern *regexp.Regexp
+}
+
+// LogRecord is a log line with the level and fields parsed from it.
+type LogRecord struct {
+	LogLine
+	Level   string                 `json:"level,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"` // set for JSON lines
+}
+
+// LogQueryResult is the result of querying a server's logs.
+type LogQueryResult struct {
+	Records    []LogRecord `json:"records"`
+	Matched    int         `json:"matched"`     // matching lines before applying the limit
+	NextCursor int64       `json:"next_cursor"` // pass as AfterSeq to fetch only newer lines
+	Dropped    int64       `json:"dropped"`     // lines after the cursor already evicted from the buffer
+	Truncated  bool        `json:"truncated"`
+	Source     string      `json:"source"` // LogSourceMemory or LogSourceDisk
+}
+
+// validate checks the filter and normalizes its values
+func (f *LogFilter) validate() error {
+	switch f.Stream {
+	case "", StreamStdout, StreamStderr, StreamSystem:
+	default:
+		return fmt.Errorf("invalid stream %q: must be stdout, stderr or system", f.Stream)
+	}
+	if f.Level != "" {
+		level, ok := levelAliases[strings.ToLower(f.Level)]
+		if !ok {
+			return fmt.Errorf("invalid level %q: must be trace, debug, info, warn, error or fatal", f.Level)
+		}
+		f.Level = level
+	}
+	if f.Pattern != "" {
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		f.pattern = re
+	}
+	if f.AfterSeq < 0 {
+		return fmt.Errorf("invalid cursor %d", f.AfterSeq)
+	}
+	return nil
+}
+
+// match reports whether the record passes the filter
+func (f *LogFilter) match(record *LogRecord) bool {
+	if f.Stream != "" && record.Stream != f.Stream {
+		return false
+	}
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !record.Time.Before(f.Until) {
+		return false
+	}
+	if f.Contains != "" && !strings.Contains(record.Text, f.Contains) {
+		return false
+	}
+	if f.pattern != nil && !f.pattern.MatchString(record.Text) {
+		return false
+	}
+	if f.Level != "" && (record.Level == "" || logLevels[record.Level] < logLevels[f.Level]) {
+		return false
+	}
+	for key, want := range f.Fields {
+		value, ok := lookupField(record.Fields, key)
+		if !ok || fmt.Sprint(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLogLine detects JSON-formatted log lines, as written by slog, zap or
+// zerolog, and extracts the level and message of JSON and plain text lines.
+func ParseLogLine(line LogLine) LogRecord {
+	record := LogRecord{LogLine: line}
+
+	text := strings.TrimSpace(line.Text)
+	if strings.HasPrefix(text, "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &fields); err == nil {
+			record.Fields = fields
+			for _, key := range []string{"level", "lvl", "severity"} {
+				if value, ok := fields[key].(string); ok {
+					record.Level = levelAliases[strings.ToLower(value)]
+					break
+				}
+			}
+			for _, key := range []string{"msg", "message"} {
+				if value, ok := fields[key].(string); ok {
+					record.Message = value
+					break
+				}
+			}
+			return record
+		}
+	}
+
+	if m := logfmtLevel.FindStringSubmatch(text); m != nil {
+		record.Level = levelAliases[strings.ToLower(m[1])]
+	} else if m := textLevel.FindStringSubmatch(text); m != nil {
+		record.Level = levelAliases[strings.ToLower(m[1]+m[2])]
+	}
+	return record
+}
+
+// lookupField returns the value of a possibly nested field, e.g. "req.method"
+func lookupField(fields map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := fields[key]; ok {
+		return value, true
+	}
+	head, rest, found := strings.Cut(key, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := fields[head].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupField(nested, rest)
+}
+
+// QueryServerLogs returns the log lines of a server that match the filter.
+// With a cursor the oldest matches after it are returned first, so a client
+// can page forward; otherwise the most recent matches are returned.
+func (sm *ServerManager) QueryServerLogs(id string, filter LogFilter) (*LogQueryResult, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	lines := serverInfo.Lines.GetAll()
+	source := LogSourceMemory
+	// Lines after the cursor that were evicted from memory may still be on disk
+	if serverInfo.logFile != nil && filter.AfterSeq > 0 && len(lines) > 0 && lines[0].Seq > filter.AfterSeq+1 {
+		if diskLines, err := readServerLogFiles(serverInfo.logFile.path, serverInfo.logFile.maxBackups); err == nil {
+			lines, source = diskLines, LogSourceDisk
+		}
+	}
+
+	result := queryLogLines(lines, filter)
+	result.Source = source
+	return result, nil
+}
+
+// queryLogLines applies the filter to lines, which are ordered by sequence number
+func queryLogLines(lines []LogLine, filter LogFilter) *LogQueryResult {
+	result := &LogQueryResult{Records: []LogRecord{}, NextCursor: filter.AfterSeq}
+	if len(lines) > 0 {
+		result.NextCursor = lines[len(lines)-1].Seq
+		if filter.AfterSeq > 0 && lines[0].Seq > filter.AfterSeq+1 {
+			result.Dropped = lines[0].Seq - filter.AfterSeq - 1
+		}
+	}
+
+	for _, line := range lines {
+		if line.Seq <= filter.AfterSeq {
+			continue
+		}
+		record := ParseLogLine(line)
+		if filter.match(&record) {
+			result.Records = append(result.Records, record)
+		}
+	}
+
+	result.Matched = len(result.Records)
+	if filter.Limit > 0 && len(result.Records) > filter.Limit {
+		result.Truncated = true
+		if filter.AfterSeq > 0 {
+			result.Records = result.Records[:filter.Limit]
+			result.NextCursor = result.Records[filter.Limit-1].Seq
+		} else {
+			result.Records = result.Records[len(result.Records)-filter.Limit:]
+		}
+	}
+	return result
+}
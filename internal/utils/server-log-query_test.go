@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		level   string
+		message string
+		json    bool
+	}{
+		{"slog JSON", `{"time":"2024-01-01T00:00:00Z","level":"WARN","msg":"slow request","ms":1200}`, "warn", "slow request", true},
+		{"zap", `{"level":"error","ts":1704067200.5,"msg":"request failed"}`, "error", "request failed", true},
+		{"zerolog", `{"level":"debug","message":"cache miss"}`, "debug", "cache miss", true},
+		{"slog text", `time=2024-01-01T00:00:00Z level=INFO msg="server started"`, "info", "", false},
+		{"bracketed", `2024/01/01 00:00:00 [error] connection refused`, "error", "", false},
+		{"upper case", `ERROR: disk full`, "error", "", false},
+		{"plain", `Error handling is hard`, "", "", false},
+		{"invalid JSON", `{not json`, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := ParseLogLine(LogLine{Text: tt.text})
+			if record.Level != tt.level {
+				t.Errorf("Expected level %q, got %q", tt.level, record.Level)
+			}
+			if record.Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, record.Message)
+			}
+			if (record.Fields != nil) != tt.json {
+				t.Errorf("Expected JSON detection %v, got fields %v", tt.json, record.Fields)
+			}
+		})
+	}
+}
+
+// newLogServer registers a server with the given lines without starting a process
+func newLogServer(sm *ServerManager, id string, lines []LogLine) *ServerInfo {
+	serverInfo := &ServerInfo{
+		ID:         id,
+		Logs:       NewRingBuffer(100),
+		StdoutLogs: NewRingBuffer(100),
+		StderrLogs: NewRingBuffer(100),
+		Lines:      NewLogBuffer(100),
+	}
+	for _, line := range lines {
+		serverInfo.addLog(line.Stream, line.Text)
+	}
+	sm.servers.Store(id, serverInfo)
+	return serverInfo
+}
+
+func TestQueryServerLogs_Filters(t *testing.T) {
+	sm := NewServerManager()
+	newLogServer(sm, "query", []LogLine{
+		{Stream: StreamStdout, Text: `{"level":"info","msg":"request","req":{"method":"GET"},"status":200}`},
+		{Stream: StreamStdout, Text: `{"level":"error","msg":"request","req":{"method":"POST"},"status":500}`},
+		{Stream: StreamStderr, Text: "WARN: low memory"},
+		{Stream: StreamStderr, Text: "plain output"},
+	})
+
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   []int64
+	}{
+		{"all", LogFilter{}, []int64{1, 2, 3, 4}},
+		{"stream", LogFilter{Stream: StreamStderr}, []int64{3, 4}},
+		{"contains", LogFilter{Contains: "memory"}, []int64{3}},
+		{"pattern", LogFilter{Pattern: `"status":[45]\d\d`}, []int64{2}},
+		{"level", LogFilter{Level: "warning"}, []int64{2, 3}},
+		{"field", LogFilter{Fields: map[string]string{"status": "200"}}, []int64{1}},
+		{"nested field", LogFilter{Fields: map[string]string{"req.method": "POST"}}, []int64{2}},
+		{"cursor", LogFilter{AfterSeq: 2}, []int64{3, 4}},
+		{"limit", LogFilter{Limit: 1}, []int64{4}},
+		{"limit after cursor", LogFilter{AfterSeq: 1, Limit: 2}, []int64{2, 3}},
+		{"until", LogFilter{Until: time.Now().Add(-time.Hour)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sm.QueryServerLogs("query", tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got []int64
+			for _, record := range result.Records {
+				got = append(got, record.Seq)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected lines %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Expected lines %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryServerLogs_Cursor(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := newLogServer(sm, "cursor", []LogLine{
+		{Stream: StreamStdout, Text: "one"},
+		{Stream: StreamStdout, Text: "two"},
+	})
+
+	result, err := sm.QueryServerLogs("cursor", LogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NextCursor != 2 {
+		t.Fatalf("Expected cursor 2, got %d", result.NextCursor)
+	}
+
+	// No new lines
+	result, err = sm.QueryServerLogs("cursor", LogFilter{AfterSeq: result.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Records) != 0 || result.NextCursor != 2 {
+		t.Errorf("Expected no new lines and cursor 2, got %d lines and cursor %d", len(result.Records), result.NextCursor)
+	}
+
+	serverInfo.addLog(StreamStdout, "three")
+	result, err = sm.QueryServerLogs("cursor", LogFilter{AfterSeq: result.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Records) != 1 || result.Records[0].Text != "three" || result.NextCursor != 3 {
+		t.Errorf("Expected only the new line, got %+v (cursor %d)", result.Records, result.NextCursor)
+	}
+}
+
+func TestQueryServerLogs_Dropped(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := newLogServer(sm, "dropped", nil)
+	serverInfo.Lines = NewLogBuffer(2)
+	for _, text := range []string{"one", "two", "three", "four", "five"} {
+		serverInfo.addLog(StreamStdout, text)
+	}
+
+	result, err := sm.QueryServerLogs("dropped", LogFilter{AfterSeq: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Dropped != 2 {
+		t.Errorf("Expected 2 dropped lines, got %d", result.Dropped)
+	}
+	if len(result.Records) != 2 || result.Records[0].Text != "four" {
+		t.Errorf("Expected the retained lines, got %+v", result.Records)
+	}
+}
+
+func TestQueryServerLogs_InvalidFilter(t *testing.T) {
+	sm := NewServerManager()
+	newLogServer(sm, "invalid", nil)
+
+	for _, filter := range []LogFilter{
+		{Stream: "stdin"},
+		{Level: "loud"},
+		{Pattern: "("},
+		{AfterSeq: -1},
+	} {
+		if _, err := sm.QueryServerLogs("invalid", filter); err == nil {
+			t.Errorf("Expected error for filter %+v", filter)
+		}
+	}
+
+	if _, err := sm.QueryServerLogs("missing", LogFilter{}); err == nil {
+		t.Error("Expected error for unknown server")
+	}
+}
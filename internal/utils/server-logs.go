@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Log streams of a managed server
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+	StreamSystem = "system" // messages from the server manager itself, e.g. watch mode
+)
+
+// serverPipeWaitDelay bounds how long to wait for the output pipes to close
+// after the server exits, e.g. when a child process inherited them.
+const serverPipeWaitDelay = 2 * time.Second
+
+// LogLine is one line of output from a managed server.
+type LogLine struct {
+	Seq    int64     `json:"seq"` // increases by one for every line of the server
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Text   string    `json:"text"`
+}
+
+// String formats the line with its timestamp and stream tag
+func (l LogLine) String() string {
+	return fmt.Sprintf("%s [%s] %s", l.Time.Format("2006-01-02T15:04:05.000"), l.Stream, l.Text)
+}
+
+// LogBuffer is a circular buffer of log lines numbered in arrival order.
+type LogBuffer struct {
+	lines []LogLine
+	size  int
+	head  int
+	count int
+	seq   int64
+	mu    sync.RWMutex
+}
+
+// NewLogBuffer creates a new log buffer with the specified size.
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{
+		lines: make([]LogLine, size),
+		size:  size,
+	}
+}
+
+// Add timestamps and numbers a line and appends it to the buffer
+func (lb *LogBuffer) Add(stream, text string) LogLine {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	lb.seq++
+	line := LogLine{Seq: lb.seq, Time: time.Now(), Stream: stream, Text: text}
+	lb.lines[lb.head] = line
+	lb.head = (lb.head + 1) % lb.size
+	if lb.count < lb.size {
+		lb.count++
+	}
+	return line
+}
+
+// GetAll returns all log lines in order
+func (lb *LogBuffer) GetAll() []LogLine {
+	return lb.GetRecent(-1)
+}
+
+// GetRecent returns the most recent n lines, or all lines if n is negative
+func (lb *LogBuffer) GetRecent(n int) []LogLine {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	if n < 0 || n > lb.count {
+		n = lb.count
+	}
+
+	result := make([]LogLine, n)
+	for i := 0; i < n; i++ {
+		idx := (lb.head - n + i + lb.size) % lb.size
+		result[i] = lb.lines[idx]
+	}
+	return result
+}
+
+// LastSeq returns the sequence number of the most recent line, or 0
+func (lb *LogBuffer) LastSeq() int64 {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+	return lb.seq
+}
+
+// addLog records a line of server output and notifies the log observer.
+func (s *ServerInfo) addLog(stream, text string) {
+	if s.logFile != nil {
+		s.logFileMu.Lock()
+		s.writeLogLine(s.Lines.Add(stream, text))
+		s.logFileMu.Unlock()
+	} else {
+		s.Lines.Add(stream, text)
+	}
+	s.Logs.Add(text)
+	switch stream {
+	case StreamStdout:
+		s.StdoutLogs.Add(text)
+	case StreamStderr:
+		s.StderrLogs.Add(text)
+	}
+	if s.onLog != nil {
+		if fn := s.onLog.Load(); fn != nil {
+			(*fn)(s.ID)
+		}
+	}
+}
+
+// newStreamWriter returns a writer that adds each line written to it to the
+// server's logs as soon as it is complete.
+func newStreamWriter(serverInfo *ServerInfo, stream string, redact bool) *lineWriter {
+	return &lineWriter{
+		fn:     func(line string) { serverInfo.addLog(stream, line) },
+		redact: redact,
+	}
+}
+
+// SetLogObserver registers a function called with the server ID whenever a
+// server logs a line, including servers started or restored earlier.
+func (sm *ServerManager) SetLogObserver(fn func(id string)) {
+	sm.logObserver.Store(&fn)
+}
+
+// GetServerLogLines returns timestamped log lines from a server
+func (sm *ServerManager) GetServerLogLines(id string, recent int) ([]LogLine, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+	if recent > 0 {
+		return serverInfo.Lines.GetRecent(recent), nil
+	}
+	return serverInfo.Lines.GetAll(), nil
+}
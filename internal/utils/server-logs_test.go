@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func TestLogBuffer(t *testing.T) {
+	lb := NewLogBuffer(3)
+
+	lb.Add(StreamStdout, "one")
+	lb.Add(StreamStderr, "two")
+	if lines := lb.GetAll(); len(lines) != 2 || lines[0].Text != "one" || lines[1].Stream != StreamStderr {
+		t.Fatalf("Unexpected lines: %+v", lines)
+	}
+
+	lb.Add(StreamStdout, "three")
+	lb.Add(StreamStdout, "four")
+	lines := lb.GetAll()
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	if lines[0].Text != "two" || lines[2].Text != "four" {
+		t.Errorf("Expected oldest line to be dropped, got %+v", lines)
+	}
+	if lines[0].Seq != 2 || lines[2].Seq != 4 || lb.LastSeq() != 4 {
+		t.Errorf("Unexpected sequence numbers: %+v (last %d)", lines, lb.LastSeq())
+	}
+
+	if recent := lb.GetRecent(2); len(recent) != 2 || recent[0].Text != "three" {
+		t.Errorf("Unexpected recent lines: %+v", recent)
+	}
+}
+
+func TestServerLogs_Streams(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	var notified atomic.Int32
+	sm := NewServerManager()
+	sm.SetLogObserver(func(id string) {
+		if id == "streams" {
+			notified.Add(1)
+		}
+	})
+
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	serverInfo, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{
+		ID:      "streams",
+		Command: "sh",
+		Args:    []string{"-c", "echo out; echo err >&2; printf partial"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+		t.Fatal("Expected server to exit")
+	}
+
+	lines, err := sm.GetServerLogLines("streams", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streams := make(map[string]string)
+	for _, line := range lines {
+		if line.Time.IsZero() {
+			t.Errorf("Line %q has no timestamp", line.Text)
+		}
+		streams[line.Text] = line.Stream
+	}
+	if streams["out"] != StreamStdout || streams["err"] != StreamStderr {
+		t.Errorf("Expected lines tagged with their stream, got %+v", lines)
+	}
+	if streams["partial"] != StreamStdout {
+		t.Errorf("Expected unterminated final line to be logged, got %+v", lines)
+	}
+	if got := serverInfo.StderrLogs.GetAll(); len(got) != 1 || got[0] != "err" {
+		t.Errorf("Expected stderr logs [err], got %v", got)
+	}
+	if notified.Load() != 3 {
+		t.Errorf("Expected 3 notifications, got %d", notified.Load())
+	}
+}
+
+func TestServerLogs_LinesArriveWhileRunning(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "streaming",
+		Command: "sh",
+		Args:    []string{"-c", "echo ready; sleep 30"},
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if lines := serverInfo.Lines.GetAll(); len(lines) == 1 && lines[0].Text == "ready" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected line to be logged while the server runs, got %+v", serverInfo.Lines.GetAll())
+}
+
+func TestSetLogObserver_ExistingServers(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{ID: "observed", Command: "sleep", Args: []string{"5"}})
+
+	// Registered after the server was started, as when servers are restored
+	// before the resources are
+	var notified atomic.Value
+	sm.SetLogObserver(func(id string) { notified.Store(id) })
+	serverInfo.addLog(StreamSystem, "after the observer")
+	if id, _ := notified.Load().(string); id != "observed" {
+		t.Errorf("Expected the observer to be notified for the earlier server, got %q", id)
+	}
+}
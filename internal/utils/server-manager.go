@@ -4,10 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
 
 	"github.com/inja-online/golang-mcp/internal/config"
@@ -15,65 +16,100 @@ import (
 
 // ServerInfo holds information about a running server
 type ServerInfo struct {
-	ID         string
-	Name       string
-	PID        int
-	Command    string
-	Args       []string
-	WorkingDir string
-	StartTime  time.Time
-	Process    *exec.Cmd
-	Logs       *RingBuffer
-	StdoutLogs *RingBuffer
-	StderrLogs *RingBuffer
-	ExitCode   *int
-	Status     string // "running", "stopped", "error"
-	Metadata   map[string]interface{}
-	ctx        context.Context
-	cancel     context.CancelFunc
-	logMutex   sync.RWMutex
+	ID          string
+	Name        string
+	PID         int
+	Command     string
+	Args        []string
+	WorkingDir  string
+	StartTime   time.Time
+	Process     *exec.Cmd
+	Logs        *RingBuffer
+	Lines       *LogBuffer // timestamped lines of all streams
+	StdoutLogs  *RingBuffer
+	StderrLogs  *RingBuffer
+	ExitCode    *int
+	Status      string // "running", "stopped", "error"
+	Metadata    map[string]interface{}
+	HealthCheck *HealthCheck
+
+	RestartPolicy string       // "never", "on-failure" or "always"
+	MaxRestarts   int          // maximum consecutive automatic restarts
+	Restarts      int          // number of restarts so far
+	ExitHistory   []ExitRecord // most recent exits, oldest first
+
+	opts      ServerOptions
+	cfg       *config.Config
+	ctx       context.Context
+	cancel    context.CancelFunc
+	logMutex  sync.RWMutex
+	health    string
+	healthErr string
+	onLog     *atomic.Pointer[func(id string)] // log observer of the manager
+	logFile   *rotatingFile                    // on-disk log, nil unless server log files are enabled
+	logFileMu sync.Mutex                       // keeps lines in the log file in sequence order
+	lastStop  *StopResult
+	lastBuild *BuildStatus
+	samples   []ResourceSample // resource usage of the current run, oldest first
+	sampleErr string
+	profiles  []ProfileArtifact // captured pprof profiles, oldest first
+	stdin     io.WriteCloser    // standard input of the current process, nil once closed
+	argv      []string          // command line of the current process
+	runSeq    int64             // sequence number of the last line before the current process started
+	done      chan struct{}     // closed when the current process exits
+	stopping  bool              // set by StopServer to suppress restarts
+	retries   int               // consecutive automatic restarts
+	rebuilt   bool              // set when watch mode built the binary for the next start
 }
 
-type safeBuffer struct {
-	buf bytes.Buffer
-	mu  sync.Mutex
-}
+// ServerOptions describes a server to start.
+type ServerOptions struct {
+	ID          string
+	Name        string
+	Command     string
+	Args        []string
+	WorkingDir  string
+	EnvVars     map[string]string
+	LogSize     int
+	HealthCheck *HealthCheck
 
-func newSafeBuffer() *safeBuffer {
-	return &safeBuffer{}
-}
+	RestartPolicy  string        // "never" (default), "on-failure" or "always"
+	MaxRestarts    int           // maximum consecutive automatic restarts, default 5
+	RestartBackoff time.Duration // delay before the first restart, doubled on each retry
 
-func (sb *safeBuffer) Write(p []byte) (n int, err error) {
-	sb.mu.Lock()
-	defer sb.mu.Unlock()
-	return sb.buf.Write(p)
-}
+	Watch *WatchOptions // rebuild and restart on source changes
 
-func (sb *safeBuffer) ReadAndReset() []byte {
-	sb.mu.Lock()
-	defer sb.mu.Unlock()
-	data := sb.buf.Bytes()
-	result := make([]byte, len(data))
-	copy(result, data)
-	sb.buf.Reset()
-	return result
+	Build *BuildOptions // build a package and run the binary instead of Command
+
+	StopTimeout time.Duration // grace period between SIGTERM and SIGKILL, default 10s
+
+	MetricsInterval time.Duration // time between resource usage samples, default 5s
+
+	Ports         []string       // names of free ports to allocate besides {{port:name}} placeholders
+	AssignedPorts map[string]int // ports already reserved for the server, e.g. by its stack
 }
 
-func (sb *safeBuffer) Len() int {
-	sb.mu.Lock()
-	defer sb.mu.Unlock()
-	return sb.buf.Len()
+// IsRunning reports whether the server process is still running.
+func (s *ServerInfo) IsRunning() bool {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	return s.Status == "running"
 }
 
-type safeWriter struct {
-	buf *safeBuffer
+// ProcessState is the state of the process a server is running or last ran.
+type ProcessState struct {
+	Status    string
+	PID       int
+	StartTime time.Time
+	ExitCode  *int
 }
 
-func (sw *safeWriter) Write(p []byte) (n int, err error) {
-	sw.buf.mu.Lock()
-	n, err = sw.buf.buf.Write(p)
-	sw.buf.mu.Unlock()
-	return n, err
+// ProcessState returns the status, PID, start time and exit code of the
+// server's current or last process.
+func (s *ServerInfo) ProcessState() ProcessState {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	return ProcessState{Status: s.Status, PID: s.PID, StartTime: s.StartTime, ExitCode: s.ExitCode}
 }
 
 // RingBuffer is a circular buffer for storing strings.
@@ -135,9 +171,73 @@ func (rb *RingBuffer) GetRecent(n int) []string {
 	return result
 }
 
+// lineWriter is an io.Writer that splits its input into lines and appends
+// each complete line to one or more ring buffers, or passes it to fn. A
+// trailing partial line is held back until the next newline or Flush.
+type lineWriter struct {
+	buffers []*RingBuffer
+	fn      func(line string)
+	partial []byte
+	redact  bool
+	mu      sync.Mutex
+}
+
+// newLineWriter creates a line writer feeding the given ring buffers.
+func newLineWriter(buffers ...*RingBuffer) *lineWriter {
+	return &lineWriter{buffers: buffers}
+}
+
+func (lw *lineWriter) Write(p []byte) (n int, err error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	data := append(lw.partial, p...)
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		lw.add(string(bytes.TrimRight(data[:idx], "\r")))
+		data = data[idx+1:]
+	}
+	lw.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Flush writes any buffered partial line.
+func (lw *lineWriter) Flush() {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if len(lw.partial) > 0 {
+		lw.add(string(lw.partial))
+		lw.partial = nil
+	}
+}
+
+func (lw *lineWriter) add(line string) {
+	if lw.redact {
+		line = RedactSecrets(line)
+	}
+	for _, rb := range lw.buffers {
+		rb.Add(line)
+	}
+	if lw.fn != nil {
+		lw.fn(line)
+	}
+}
+
 // ServerManager manages multiple MCP servers.
 type ServerManager struct {
-	servers sync.Map
+	servers     sync.Map
+	logObserver atomic.Pointer[func(id string)]
+	starting    sync.Map            // server ID -> struct{} while the server is being started
+	stacks      sync.Map            // stack name -> *stackState
+	stackMu     sync.Mutex          // serializes bringing stacks up and down
+	ports       map[int]interface{} // reserved port -> owning *ServerInfo or *stackState
+	portMu      sync.Mutex
+	stateFile   string     // where the registry is persisted, empty if it is not
+	stateMu     sync.Mutex // serializes writing the state file
 }
 
 // NewServerManager creates a new server manager
@@ -147,116 +247,261 @@ func NewServerManager() *ServerManager {
 
 // StartServer starts a Go server in the background
 func (sm *ServerManager) StartServer(ctx context.Context, cfg *config.Config, id, name, command string, args []string, workingDir string, envVars map[string]string, logSize int) (*ServerInfo, error) {
-	if logSize <= 0 {
-		logSize = 1000 // Default log size
-	}
-
-	// Create context for this server
-	serverCtx, cancel := context.WithCancel(ctx)
-
-	// Create command
-	cmd := exec.CommandContext(serverCtx, command, args...)
+	return sm.StartServerWithOptions(ctx, cfg, ServerOptions{
+		ID:         id,
+		Name:       name,
+		Command:    command,
+		Args:       args,
+		WorkingDir: workingDir,
+		EnvVars:    envVars,
+		LogSize:    logSize,
+	})
+}
 
-	// Set working directory
-	if workingDir != "" {
-		cmd.Dir = workingDir
-	} else {
-		cmd.Dir = cfg.WorkingDirectory
+// StartServerWithOptions starts a server in the background. If a health check
+// is configured, the server is probed until it is stopped. If a restart policy
+// is configured, the server is relaunched when it exits. In watch mode, it is
+// rebuilt and restarted when its sources change.
+func (sm *ServerManager) StartServerWithOptions(ctx context.Context, cfg *config.Config, opts ServerOptions) (*ServerInfo, error) {
+	// Only a stopped server may be replaced by one with the same ID
+	if _, loaded := sm.starting.LoadOrStore(opts.ID, struct{}{}); loaded {
+		return nil, fmt.Errorf("server %s is already starting", opts.ID)
+	}
+	defer sm.starting.Delete(opts.ID)
+	if previous, ok := sm.servers.Load(opts.ID); ok {
+		if status := previous.(*ServerInfo).ProcessState().Status; status == "running" || status == "restarting" {
+			return nil, fmt.Errorf("server %s is already %s; stop it first", opts.ID, status)
+		}
 	}
 
-	// Set up environment
-	env := os.Environ()
-	goEnv := cfg.GetGoEnv()
-	for k, v := range goEnv {
-		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	if opts.LogSize <= 0 {
+		opts.LogSize = 1000 // Default log size
 	}
-	for k, v := range envVars {
-		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = defaultStopTimeout
+	}
+	if opts.MetricsInterval <= 0 {
+		opts.MetricsInterval = defaultMetricsInterval
 	}
-	cmd.Env = env
 
-	stdoutBuf := newSafeBuffer()
-	stderrBuf := newSafeBuffer()
-	cmd.Stdout = &safeWriter{buf: stdoutBuf}
-	cmd.Stderr = &safeWriter{buf: stderrBuf}
+	if err := cfg.CheckWorkingDir(opts.WorkingDir); err != nil {
+		return nil, err
+	}
+	if opts.HealthCheck != nil {
+		if err := opts.HealthCheck.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := opts.validateRestartPolicy(); err != nil {
+		return nil, err
+	}
+	if opts.Build != nil {
+		build := *opts.Build
+		if err := build.validate(&opts); err != nil {
+			return nil, err
+		}
+		opts.Build = &build
+	}
+	if opts.Watch != nil {
+		workingDir := opts.WorkingDir
+		if workingDir == "" {
+			workingDir = cfg.WorkingDirectory
+		}
+		if err := opts.Watch.validate(workingDir, cfg.ServerLogDir, serverBuildDir(opts.ID)); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := cmd.Start(); err != nil {
-		cancel()
-		return nil, fmt.Errorf("failed to start server: %w", err)
+	// Open the on-disk log, continuing the line numbering of earlier runs
+	var logFile *rotatingFile
+	var lastSeq int64
+	if cfg.ServerLogDir != "" {
+		var err error
+		logFile, lastSeq, err = openServerLog(cfg, opts.ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Create server info
 	serverInfo := &ServerInfo{
-		ID:         id,
-		Name:       name,
-		PID:        cmd.Process.Pid,
-		Command:    command,
-		Args:       args,
-		WorkingDir: workingDir,
-		StartTime:  time.Now(),
-		Process:    cmd,
-		Logs:       NewRingBuffer(logSize),
-		StdoutLogs: NewRingBuffer(logSize),
-		StderrLogs: NewRingBuffer(logSize),
-		Status:     "running",
-		Metadata:   make(map[string]interface{}),
-		ctx:        serverCtx,
-		cancel:     cancel,
+		ID:            opts.ID,
+		Name:          opts.Name,
+		Command:       opts.Command,
+		Args:          opts.Args,
+		WorkingDir:    opts.WorkingDir,
+		Logs:          NewRingBuffer(opts.LogSize),
+		StdoutLogs:    NewRingBuffer(opts.LogSize),
+		StderrLogs:    NewRingBuffer(opts.LogSize),
+		Lines:         NewLogBuffer(opts.LogSize),
+		onLog:         &sm.logObserver,
+		logFile:       logFile,
+		Metadata:      make(map[string]interface{}),
+		HealthCheck:   opts.HealthCheck,
+		RestartPolicy: opts.RestartPolicy,
+		MaxRestarts:   opts.MaxRestarts,
+		opts:          opts,
+		cfg:           cfg,
 	}
 
-	// Store server
-	sm.servers.Store(id, serverInfo)
+	serverInfo.Lines.seq = lastSeq
 
-	// Start log collection goroutine
-	go sm.collectLogs(serverInfo, stdoutBuf, stderrBuf)
+	// Reserve free ports and substitute them for {{port:name}} placeholders
+	ports, err := sm.allocatePorts(&opts, serverInfo)
+	if err != nil {
+		sm.releasePorts(serverInfo)
+		if logFile != nil {
+			logFile.Close()
+		}
+		return nil, err
+	}
+	if ports != nil {
+		serverInfo.Args = opts.Args
+		serverInfo.HealthCheck = opts.HealthCheck
+		serverInfo.opts = opts
+		serverInfo.Metadata["ports"] = ports
+	}
 
-	// Start monitoring goroutine
-	go sm.monitorServer(serverInfo)
+	if err := sm.start(ctx, serverInfo); err != nil {
+		sm.releasePorts(serverInfo)
+		if logFile != nil {
+			logFile.Close()
+		}
+		return nil, err
+	}
+
+	// Store server, releasing the log file and ports of a previous stopped
+	// server with the same ID
+	if previous, loaded := sm.servers.Swap(opts.ID, serverInfo); loaded {
+		if previousLog := previous.(*ServerInfo).logFile; previousLog != nil {
+			previousLog.Close()
+		}
+		sm.releasePorts(previous)
+	}
+	sm.saveState()
 
 	return serverInfo, nil
 }
 
-func (sm *ServerManager) collectLogs(serverInfo *ServerInfo, stdoutBuf, stderrBuf *safeBuffer) {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
+// start launches the server process along with its health monitoring,
+// metrics and watch goroutines, which run until the server is stopped.
+func (sm *ServerManager) start(ctx context.Context, serverInfo *ServerInfo) error {
+	// Create context for this server. It must outlive the request that
+	// started the server, so cancellation of ctx is not propagated.
+	serverCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
 
-	for {
-		select {
-		case <-serverInfo.ctx.Done():
-			return
-		case <-ticker.C:
-			if stdoutBuf.Len() > 0 {
-				data := stdoutBuf.ReadAndReset()
-				lines := bytes.Split(data, []byte("\n"))
-				for _, line := range lines {
-					if len(line) > 0 {
-						lineStr := string(line)
-						serverInfo.Logs.Add(lineStr)
-						serverInfo.StdoutLogs.Add(lineStr)
-					}
-				}
-			}
-
-			if stderrBuf.Len() > 0 {
-				data := stderrBuf.ReadAndReset()
-				lines := bytes.Split(data, []byte("\n"))
-				for _, line := range lines {
-					if len(line) > 0 {
-						lineStr := string(line)
-						serverInfo.Logs.Add(lineStr)
-						serverInfo.StderrLogs.Add(lineStr)
-					}
-				}
-			}
-		}
+	serverInfo.logMutex.Lock()
+	serverInfo.ctx = serverCtx
+	serverInfo.cancel = cancel
+	serverInfo.stopping = false
+	serverInfo.logMutex.Unlock()
+
+	if err := sm.prepareBinary(serverCtx, serverInfo); err != nil {
+		cancel()
+		return err
+	}
+	if err := sm.launch(serverCtx, serverInfo); err != nil {
+		cancel()
+		return err
+	}
+
+	if serverInfo.HealthCheck != nil {
+		go sm.monitorHealth(serverCtx, serverInfo, serverInfo.HealthCheck)
 	}
+	go sm.monitorMetrics(serverCtx, serverInfo)
+	if serverInfo.opts.Watch != nil {
+		go sm.watchServer(serverCtx, serverInfo)
+	}
+	return nil
 }
 
-// monitorServer monitors the server process
-func (sm *ServerManager) monitorServer(serverInfo *ServerInfo) {
-	err := serverInfo.Process.Wait()
+// launch starts one run of the server process and its monitoring goroutine.
+func (sm *ServerManager) launch(ctx context.Context, serverInfo *ServerInfo) error {
+	serverInfo.logMutex.RLock()
+	opts, cfg := serverInfo.opts, serverInfo.cfg
+	serverInfo.logMutex.RUnlock()
+
+	// Create command in its own process group, which is killed if ctx is cancelled
+	cmd := exec.CommandContext(ctx, opts.Command, opts.Args...)
+	setProcessGroup(cmd)
+
+	// Set working directory
+	if opts.WorkingDir != "" {
+		cmd.Dir = opts.WorkingDir
+	} else {
+		cmd.Dir = cfg.WorkingDirectory
+	}
+
+	// Set up environment according to the env policy
+	env, err := BuildEnv(cfg, opts.EnvVars)
+	if err != nil {
+		return fmt.Errorf("environment validation failed: %w", err)
+	}
+	cmd.Env = env
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	// Output is logged line by line as it arrives
+	output, err := newServerOutput(serverInfo, cfg, cmd)
+	if err != nil {
+		stdin.Close()
+		return fmt.Errorf("failed to capture output: %w", err)
+	}
+
+	entry := AuditEntry{
+		Kind:         AuditKindServerStart,
+		Tool:         ToolNameFromContext(ctx),
+		Command:      cmd.Path,
+		Args:         RedactArgs(opts.Args),
+		WorkingDir:   cmd.Dir,
+		EnvOverrides: RedactEnv(opts.EnvVars),
+		Permission:   PermissionNotRequired,
+	}
+
+	runSeq := serverInfo.Lines.LastSeq()
+	if err := cmd.Start(); err != nil {
+		entry.Error = err.Error()
+		RecordAudit(entry)
+		output.finish()
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+	RecordAudit(entry)
+	output.started()
+
+	done := make(chan struct{})
+	serverInfo.logMutex.Lock()
+	serverInfo.Process = cmd
+	serverInfo.PID = cmd.Process.Pid
+	serverInfo.StartTime = time.Now()
+	serverInfo.Status = "running"
+	serverInfo.ExitCode = nil
+	serverInfo.done = done
+	serverInfo.stdin = stdin
+	serverInfo.argv = cmd.Args
+	serverInfo.runSeq = runSeq
+	if serverInfo.HealthCheck != nil {
+		serverInfo.health = HealthStarting
+		serverInfo.healthErr = ""
+	}
+	serverInfo.logMutex.Unlock()
+	sm.saveState()
+
+	// Start monitoring goroutine
+	go sm.monitorServer(ctx, serverInfo, cmd, output, done)
+
+	return nil
+}
+
+// monitorServer waits for one run of the server process to exit, records
+// the exit and restarts the server if its restart policy asks for it.
+func (sm *ServerManager) monitorServer(ctx context.Context, serverInfo *ServerInfo, cmd *exec.Cmd, output *serverOutput, done chan struct{}) {
+	err := cmd.Wait()
+	output.finish()
 
 	serverInfo.logMutex.Lock()
+	record := ExitRecord{Time: time.Now(), Uptime: time.Since(serverInfo.StartTime).Round(time.Millisecond).String()}
 	if err != nil {
 		if exitError, ok := err.(*exec.ExitError); ok {
 			exitCode := exitError.ExitCode()
@@ -265,43 +510,64 @@ func (sm *ServerManager) monitorServer(serverInfo *ServerInfo) {
 		} else {
 			serverInfo.Status = "error"
 		}
+		record.Error = err.Error()
 	} else {
 		exitCode := 0
 		serverInfo.ExitCode = &exitCode
 		serverInfo.Status = "stopped"
 	}
-	serverInfo.logMutex.Unlock()
-}
+	record.ExitCode = serverInfo.ExitCode
+	serverInfo.recordExit(record)
 
-// StopServer stops a running server by ID.
-func (sm *ServerManager) StopServer(id string, force bool) error {
-	value, ok := sm.servers.Load(id)
-	if !ok {
-		return fmt.Errorf("server not found: %s", id)
+	delay, restart := serverInfo.nextRestart(err)
+	if restart {
+		serverInfo.Status = "restarting"
 	}
+	serverInfo.logMutex.Unlock()
+	sm.saveState()
+	close(done)
 
-	serverInfo := value.(*ServerInfo)
-
-	serverInfo.logMutex.RLock()
-	status := serverInfo.Status
-	serverInfo.logMutex.RUnlock()
-
-	if status != "running" {
-		return fmt.Errorf("server is not running: %s", id)
+	if restart {
+		sm.relaunch(ctx, serverInfo, delay)
 	}
+}
 
-	if force {
-		if err := serverInfo.Process.Process.Kill(); err != nil {
-			return fmt.Errorf("failed to kill server: %w", err)
+// relaunch starts a server that exited again after the restart delay,
+// unless it is stopped in the meantime.
+func (sm *ServerManager) relaunch(ctx context.Context, serverInfo *ServerInfo, delay time.Duration) {
+	select {
+	case <-ctx.Done():
+		serverInfo.logMutex.Lock()
+		if serverInfo.Status == "restarting" {
+			serverInfo.Status = "stopped"
 		}
-	} else {
-		serverInfo.cancel()
-		if err := serverInfo.Process.Process.Signal(syscall.SIGTERM); err != nil {
-			return serverInfo.Process.Process.Kill()
+		serverInfo.logMutex.Unlock()
+		return
+	case <-time.After(delay):
+	}
+
+	// The server may have been stopped while the delay expired
+	serverInfo.logMutex.Lock()
+	if ctx.Err() != nil {
+		if serverInfo.Status == "restarting" {
+			serverInfo.Status = "stopped"
 		}
+		serverInfo.logMutex.Unlock()
+		return
 	}
+	serverInfo.logMutex.Unlock()
 
-	return nil
+	if err := sm.launch(ctx, serverInfo); err != nil {
+		serverInfo.logMutex.Lock()
+		if ctx.Err() != nil {
+			// Stopped after the check above; the process was not started
+			serverInfo.Status = "stopped"
+		} else {
+			serverInfo.Status = "error"
+			serverInfo.recordExit(ExitRecord{Time: time.Now(), Error: err.Error()})
+		}
+		serverInfo.logMutex.Unlock()
+	}
 }
 
 // ListServers returns a list of all servers
@@ -324,6 +590,38 @@ func (sm *ServerManager) GetServer(id string) (*ServerInfo, error) {
 	return value.(*ServerInfo), nil
 }
 
+// RemoveServer removes a server that is not running from the registry,
+// releasing its ports and closing its log file.
+func (sm *ServerManager) RemoveServer(id string) error {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return err
+	}
+
+	serverInfo.logMutex.RLock()
+	status := serverInfo.Status
+	serverInfo.logMutex.RUnlock()
+	if status == "running" || status == "restarting" {
+		return fmt.Errorf("server %s is %s, stop it first", id, status)
+	}
+	if !sm.servers.CompareAndDelete(id, serverInfo) {
+		return fmt.Errorf("server not found: %s", id)
+	}
+
+	if serverInfo.cancel != nil {
+		serverInfo.cancel()
+	}
+	if serverInfo.logFile != nil {
+		serverInfo.logFile.Close()
+	}
+	if serverInfo.opts.Build != nil {
+		os.RemoveAll(serverBuildDir(id))
+	}
+	sm.releasePorts(serverInfo)
+	sm.saveState()
+	return nil
+}
+
 // GetServerLogs returns logs from a server
 func (sm *ServerManager) GetServerLogs(id string, recent int) ([]string, error) {
 	serverInfo, err := sm.GetServer(id)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
@@ -214,6 +215,32 @@ func TestServerManager_StopServer(t *testing.T) {
 	}
 }
 
+func TestServerManager_StartServerDuplicateID(t *testing.T) {
+	sm := NewServerManager()
+	first := startRestartServer(t, sm, ServerOptions{ID: "dup", Command: "sleep", Args: []string{"5"}})
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+
+	_, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{ID: "dup", Command: "sleep", Args: []string{"5"}})
+	if err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Fatalf("Expected already running error, got %v", err)
+	}
+	if current, _ := sm.GetServer("dup"); current != first || !first.IsRunning() {
+		t.Error("Expected the running server to be kept")
+	}
+
+	// A stopped server may be replaced
+	if err := sm.StopServer("dup", true); err != nil {
+		t.Fatalf("StopServer failed: %v", err)
+	}
+	second, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{ID: "dup", Command: "sleep", Args: []string{"5"}})
+	if err != nil {
+		t.Fatalf("Failed to replace stopped server: %v", err)
+	}
+	if current, _ := sm.GetServer("dup"); current != second {
+		t.Error("Expected the stopped server to be replaced")
+	}
+}
+
 func TestServerManager_GetServerLogs(t *testing.T) {
 	cfg := &config.Config{
 		DisableNotifications: true,
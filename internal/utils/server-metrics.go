@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	defaultMetricsInterval = 5 * time.Second
+	metricsHistorySize     = 120 // ten minutes at the default interval
+
+	// RSS is flagged as growing when it rose in most of the recent samples
+	// and by at least memoryGrowthRatio overall
+	memoryGrowthMinSamples = 6
+	memoryGrowthRatio      = 1.2
+	memoryGrowthSteps      = 0.8 // fraction of samples in which RSS must rise
+
+	// clockTicksPerSecond is USER_HZ, the unit of CPU times in /proc, which
+	// is 100 on every Linux architecture Go supports
+	clockTicksPerSecond = 100
+)
+
+var errMetricsUnsupported = errors.New("resource metrics are only available on Linux")
+
+// ResourceSample is one measurement of the resources used by a server's
+// process group.
+type ResourceSample struct {
+	Time        time.Time `json:"time"`
+	CPUPercent  float64   `json:"cpu_percent"` // of one CPU, since the previous sample
+	RSSBytes    int64     `json:"rss_bytes"`
+	OpenFDs     int       `json:"open_fds"`
+	Threads     int       `json:"threads"`
+	Processes   int       `json:"processes"`
+	ListenPorts []int     `json:"listen_ports,omitempty"` // TCP ports in LISTEN state
+}
+
+// ResourceUsage summarizes the recent resource samples of a server.
+type ResourceUsage struct {
+	Latest        *ResourceSample  `json:"latest,omitempty"`
+	Samples       []ResourceSample `json:"samples,omitempty"` // oldest first
+	PeakRSSBytes  int64            `json:"peak_rss_bytes"`
+	AvgCPUPercent float64          `json:"avg_cpu_percent"`
+	MemoryGrowing bool             `json:"memory_growing"` // RSS has risen steadily across the samples
+	Error         string           `json:"error,omitempty"`
+}
+
+// ResourceUsage returns the resource samples of the server's current run,
+// or nil if none have been taken.
+func (s *ServerInfo) ResourceUsage() *ResourceUsage {
+	s.logMutex.RLock()
+	samples := append([]ResourceSample(nil), s.samples...)
+	sampleErr := s.sampleErr
+	s.logMutex.RUnlock()
+
+	if len(samples) == 0 && sampleErr == "" {
+		return nil
+	}
+	usage := &ResourceUsage{Samples: samples, Error: sampleErr}
+	if len(samples) == 0 {
+		return usage
+	}
+	usage.Latest = &samples[len(samples)-1]
+	var cpu float64
+	for _, sample := range samples {
+		cpu += sample.CPUPercent
+		if sample.RSSBytes > usage.PeakRSSBytes {
+			usage.PeakRSSBytes = sample.RSSBytes
+		}
+	}
+	usage.AvgCPUPercent = cpu / float64(len(samples))
+	usage.MemoryGrowing = memoryGrowing(samples)
+	return usage
+}
+
+// memoryGrowing reports whether RSS rose in most of the samples and grew by
+// at least memoryGrowthRatio from the first to the last.
+func memoryGrowing(samples []ResourceSample) bool {
+	if len(samples) < memoryGrowthMinSamples {
+		return false
+	}
+	first, last := samples[0].RSSBytes, samples[len(samples)-1].RSSBytes
+	if first <= 0 || float64(last) < float64(first)*memoryGrowthRatio {
+		return false
+	}
+	rises := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i].RSSBytes > samples[i-1].RSSBytes {
+			rises++
+		}
+	}
+	return float64(rises) >= float64(len(samples)-1)*memoryGrowthSteps
+}
+
+// groupUsage is the raw usage of a process group read from the system
+type groupUsage struct {
+	cpuTicks    uint64 // user and system time of all processes, in clock ticks
+	rssBytes    int64
+	openFDs     int
+	threads     int
+	processes   int
+	listenPorts []int
+}
+
+// monitorMetrics samples the resource usage of the server's process group
+// while it runs until it is stopped. Samples are kept per run, so a restart
+// starts a new series.
+func (sm *ServerManager) monitorMetrics(ctx context.Context, serverInfo *ServerInfo) {
+	interval := serverInfo.opts.MetricsInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var startTime, lastTime time.Time
+	var lastTicks uint64
+	for {
+		serverInfo.logMutex.Lock()
+		running := serverInfo.Status == "running"
+		pid := serverInfo.PID
+		if !serverInfo.StartTime.Equal(startTime) {
+			// The server was restarted; start a new series
+			startTime = serverInfo.StartTime
+			lastTime = time.Time{}
+			serverInfo.samples = nil
+		}
+		serverInfo.logMutex.Unlock()
+
+		if running {
+			usage, err := sampleProcessGroup(pid)
+			now := time.Now()
+
+			serverInfo.logMutex.Lock()
+			if err != nil {
+				serverInfo.sampleErr = err.Error()
+			} else if serverInfo.StartTime.Equal(startTime) {
+				sample := ResourceSample{
+					Time:        now,
+					RSSBytes:    usage.rssBytes,
+					OpenFDs:     usage.openFDs,
+					Threads:     usage.threads,
+					Processes:   usage.processes,
+					ListenPorts: usage.listenPorts,
+				}
+				// Processes that exited since the last sample take their
+				// CPU time with them, so the total may go down
+				if !lastTime.IsZero() && usage.cpuTicks >= lastTicks {
+					seconds := float64(usage.cpuTicks-lastTicks) / clockTicksPerSecond
+					sample.CPUPercent = seconds / now.Sub(lastTime).Seconds() * 100
+				}
+				lastTime, lastTicks = now, usage.cpuTicks
+				serverInfo.sampleErr = ""
+				serverInfo.samples = append(serverInfo.samples, sample)
+				if len(serverInfo.samples) > metricsHistorySize {
+					serverInfo.samples = serverInfo.samples[len(serverInfo.samples)-metricsHistorySize:]
+				}
+			}
+			serverInfo.logMutex.Unlock()
+
+			if errors.Is(err, errMetricsUnsupported) {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sampleProcessGroup reads the usage of every process in the process group
+// from /proc. Listening ports are those of TCP sockets held open by any
+// process in the group.
+func sampleProcessGroup(pgid int) (*groupUsage, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &groupUsage{}
+	sockets := make(map[string]bool)
+	pageSize := int64(os.Getpagesize())
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := readProcStat(pid)
+		if err != nil || stat.pgrp != pgid {
+			// The process may have exited since the directory was read
+			continue
+		}
+		usage.processes++
+		usage.cpuTicks += stat.utime + stat.stime
+		usage.threads += stat.threads
+		usage.rssBytes += stat.rssPages * pageSize
+
+		fds, err := os.ReadDir(filepath.Join("/proc", entry.Name(), "fd"))
+		if err != nil {
+			continue
+		}
+		usage.openFDs += len(fds)
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join("/proc", entry.Name(), "fd", fd.Name()))
+			if err != nil {
+				continue
+			}
+			if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+				sockets[strings.TrimSuffix(inode, "]")] = true
+			}
+		}
+	}
+	if usage.processes == 0 {
+		return nil, fmt.Errorf("no processes found in process group %d", pgid)
+	}
+
+	if len(sockets) > 0 {
+		ports := make(map[int]bool)
+		for _, name := range []string{"tcp", "tcp6"} {
+			// The group leader's view, in case it runs in its own network namespace
+			listenPorts(filepath.Join("/proc", strconv.Itoa(pgid), "net", name), sockets, ports)
+		}
+		for port := range ports {
+			usage.listenPorts = append(usage.listenPorts, port)
+		}
+		sort.Ints(usage.listenPorts)
+	}
+	return usage, nil
+}
+
+// procStat holds the fields of /proc/<pid>/stat used for metrics
+type procStat struct {
+	state    byte
+	pgrp     int
+	utime    uint64
+	stime    uint64
+	threads  int
+	rssPages int64
+}
+
+// readProcStat parses /proc/<pid>/stat, see proc(5)
+func readProcStat(pid int) (*procStat, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, err
+	}
+	// The command name is in parentheses and may contain spaces
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return nil, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	// Fields from the state (field 3) onwards
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("malformed stat of process %d", pid)
+	}
+
+	stat := &procStat{state: fields[0][0]}
+	if stat.pgrp, err = strconv.Atoi(fields[2]); err != nil {
+		return nil, err
+	}
+	if stat.utime, err = strconv.ParseUint(fields[11], 10, 64); err != nil {
+		return nil, err
+	}
+	if stat.stime, err = strconv.ParseUint(fields[12], 10, 64); err != nil {
+		return nil, err
+	}
+	if stat.threads, err = strconv.Atoi(fields[17]); err != nil {
+		return nil, err
+	}
+	if stat.rssPages, err = strconv.ParseInt(fields[21], 10, 64); err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+// tcpListen is the LISTEN state in /proc/net/tcp
+const tcpListen = "0A"
+
+// listenPorts adds the local ports of sockets in the LISTEN state whose inode
+// is in sockets, reading a /proc/net/tcp style table
+func listenPorts(path string, sockets map[string]bool, ports map[int]bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListen || !sockets[fields[9]] {
+			continue
+		}
+		_, portHex, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if port, err := strconv.ParseUint(portHex, 16, 16); err == nil {
+			ports[int(port)] = true
+		}
+	}
+}
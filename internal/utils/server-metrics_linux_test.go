@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"net"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSampleProcessGroup(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	usage, err := sampleProcessGroup(syscall.Getpgrp())
+	if err != nil {
+		t.Fatalf("Failed to sample process group: %v", err)
+	}
+	if usage.processes < 1 || usage.threads < 1 || usage.rssBytes <= 0 || usage.openFDs < 1 {
+		t.Errorf("Expected usage of the test process, got %+v", usage)
+	}
+	if !slices.Contains(usage.listenPorts, port) {
+		t.Errorf("Expected listening port %d in %v", port, usage.listenPorts)
+	}
+
+	if _, err := sampleProcessGroup(1 << 30); err == nil {
+		t.Error("Expected error for a process group without processes")
+	}
+}
+
+func TestMonitorMetrics(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:              "metrics",
+		Command:         "sh",
+		Args:            []string{"-c", "sleep 30 & wait"},
+		MetricsInterval: 50 * time.Millisecond,
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	var usage *ResourceUsage
+	for time.Now().Before(deadline) {
+		usage = serverInfo.ResourceUsage()
+		if usage != nil && len(usage.Samples) >= 2 && usage.Latest.Processes >= 2 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if usage == nil || usage.Latest == nil {
+		t.Fatal("Expected resource samples")
+	}
+	if usage.Latest.Processes < 2 {
+		t.Errorf("Expected the shell and its child to be sampled, got %+v", usage.Latest)
+	}
+	if usage.Latest.RSSBytes <= 0 {
+		t.Errorf("Expected RSS to be sampled, got %+v", usage.Latest)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package utils
+
+// sampleProcessGroup is only available on Linux, where usage is read from /proc.
+func sampleProcessGroup(pgid int) (*groupUsage, error) {
+	return nil, errMetricsUnsupported
+}
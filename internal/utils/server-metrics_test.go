@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryGrowing(t *testing.T) {
+	series := func(rss ...int64) []ResourceSample {
+		samples := make([]ResourceSample, len(rss))
+		for i, n := range rss {
+			samples[i] = ResourceSample{Time: time.Unix(int64(i*5), 0), RSSBytes: n}
+		}
+		return samples
+	}
+
+	tests := []struct {
+		name    string
+		samples []ResourceSample
+		want    bool
+	}{
+		{"steady growth", series(100, 110, 120, 130, 140, 150), true},
+		{"growth with a dip", series(100, 110, 120, 115, 130, 140, 150, 160, 170, 180, 190), true},
+		{"flat", series(100, 100, 100, 100, 100, 100), false},
+		{"small growth", series(100, 101, 102, 103, 104, 105), false},
+		{"spike", series(100, 100, 100, 100, 100, 200), false},
+		{"too few samples", series(100, 200, 300), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memoryGrowing(tt.samples); got != tt.want {
+				t.Errorf("memoryGrowing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceUsage(t *testing.T) {
+	serverInfo := &ServerInfo{}
+	if serverInfo.ResourceUsage() != nil {
+		t.Error("Expected no usage before the first sample")
+	}
+
+	serverInfo.samples = []ResourceSample{
+		{CPUPercent: 10, RSSBytes: 300},
+		{CPUPercent: 30, RSSBytes: 200},
+	}
+	usage := serverInfo.ResourceUsage()
+	if usage.Latest == nil || usage.Latest.RSSBytes != 200 {
+		t.Errorf("Expected latest sample, got %+v", usage.Latest)
+	}
+	if usage.PeakRSSBytes != 300 || usage.AvgCPUPercent != 20 {
+		t.Errorf("Expected peak 300 and average 20, got %+v", usage)
+	}
+}
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// outputPollInterval is how often the output files of a server are read
+// when no write has been noticed
+const outputPollInterval = 100 * time.Millisecond
+
+// serverOutput connects the stdout and stderr of one run of a server to its
+// logs. Without server log files, output is read from pipes. With them, the
+// process writes to files in the log directory, which are tailed: a process
+// writing to a pipe is killed by SIGPIPE once the MCP server exits, while a
+// file keeps accepting its output, so the process survives to be reattached.
+// Writes to the files are noticed with inotify where available, so lines are
+// logged as soon as they are written and in the order they were written
+// across both streams; elsewhere the files are polled.
+type serverOutput struct {
+	stdout *lineWriter
+	stderr *lineWriter
+	files  []*os.File // the process's ends of the output files
+	tails  []*outputTail
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// outputTail reads what a process appends to one of its output files
+type outputTail struct {
+	path    string
+	file    *os.File
+	offset  int64
+	maxSize int64 // the file is emptied once it is read past this size
+	w       *lineWriter
+}
+
+// serverOutputPath returns the file a stream of a server is written to.
+// Its suffix keeps it apart from the server's log files.
+func serverOutputPath(dir, id, stream string) string {
+	return filepath.Join(dir, url.PathEscape(id)+"."+stream)
+}
+
+// newServerOutput sets up the output of cmd. started must be called once
+// the process has started and finish once it has exited.
+func newServerOutput(serverInfo *ServerInfo, cfg *config.Config, cmd *exec.Cmd) (*serverOutput, error) {
+	out := newOutputWriters(serverInfo, cfg)
+	if cfg.ServerLogDir == "" {
+		cmd.Stdout = out.stdout
+		cmd.Stderr = out.stderr
+		cmd.WaitDelay = serverPipeWaitDelay
+		return out, nil
+	}
+
+	for _, stream := range []string{StreamStdout, StreamStderr} {
+		path := serverOutputPath(cfg.ServerLogDir, serverInfo.ID, stream)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			out.finish()
+			return nil, err
+		}
+		out.files = append(out.files, file)
+		if err := out.tail(path, stream, 0, cfg.ServerLogMaxSize); err != nil {
+			out.finish()
+			return nil, err
+		}
+	}
+	cmd.Stdout = out.files[0]
+	cmd.Stderr = out.files[1]
+	return out, nil
+}
+
+// attachServerOutput tails the output files of a reattached process from
+// their current end. It returns nil if the process does not write to files.
+func attachServerOutput(serverInfo *ServerInfo, cfg *config.Config) *serverOutput {
+	if cfg.ServerLogDir == "" {
+		return nil
+	}
+	out := newOutputWriters(serverInfo, cfg)
+	for _, stream := range []string{StreamStdout, StreamStderr} {
+		path := serverOutputPath(cfg.ServerLogDir, serverInfo.ID, stream)
+		info, err := os.Stat(path)
+		if err == nil {
+			err = out.tail(path, stream, info.Size(), cfg.ServerLogMaxSize)
+		}
+		if err != nil {
+			for _, tail := range out.tails {
+				tail.file.Close()
+			}
+			return nil
+		}
+	}
+	out.started()
+	return out
+}
+
+func newOutputWriters(serverInfo *ServerInfo, cfg *config.Config) *serverOutput {
+	redact := !cfg.DisableRedaction
+	return &serverOutput{
+		stdout: newStreamWriter(serverInfo, StreamStdout, redact),
+		stderr: newStreamWriter(serverInfo, StreamStderr, redact),
+		stop:   make(chan struct{}),
+	}
+}
+
+// tail opens an output file for reading from offset
+func (out *serverOutput) tail(path, stream string, offset, maxSize int64) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	w := out.stdout
+	if stream == StreamStderr {
+		w = out.stderr
+	}
+	out.tails = append(out.tails, &outputTail{path: path, file: file, offset: offset, maxSize: maxSize, w: w})
+	return nil
+}
+
+// started closes the copies of the output files held by the MCP server,
+// which the process has inherited, and starts tailing them.
+func (out *serverOutput) started() {
+	for _, file := range out.files {
+		file.Close()
+	}
+	out.files = nil
+	if len(out.tails) == 0 {
+		return
+	}
+	out.wg.Add(1)
+	go func() {
+		defer out.wg.Done()
+		out.run()
+	}()
+}
+
+// run reads the output files until stop is closed, then reads them a last
+// time. A file is read when a write to it is noticed, and all of them every
+// poll interval.
+func (out *serverOutput) run() {
+	var changes <-chan int
+	paths := make([]string, len(out.tails))
+	for i, tail := range out.tails {
+		paths[i] = tail.path
+	}
+	if notifier, err := newOutputNotifier(paths); err == nil {
+		defer notifier.Close()
+		changes = notifier.changes
+	}
+	ticker := time.NewTicker(outputPollInterval)
+	defer ticker.Stop()
+
+	out.readAll()
+	for {
+		select {
+		case <-out.stop:
+			out.readAll()
+			return
+		case i := <-changes:
+			out.tails[i].read()
+		case <-ticker.C:
+			out.readAll()
+		}
+	}
+}
+
+func (out *serverOutput) readAll() {
+	for _, tail := range out.tails {
+		tail.read()
+	}
+}
+
+// finish reads the rest of the output, logs a final line that was not
+// terminated by a newline and removes the output files.
+func (out *serverOutput) finish() {
+	out.once.Do(func() {
+		close(out.stop)
+		out.wg.Wait()
+		for _, file := range out.files {
+			file.Close()
+		}
+		for _, tail := range out.tails {
+			tail.file.Close()
+			_ = os.Remove(tail.path)
+		}
+		out.stdout.Flush()
+		out.stderr.Flush()
+	})
+}
+
+// read logs what was appended to the file since the last read. Once the
+// file has grown past its maximum size it is emptied; the process appends,
+// so it continues at the start. Output written between the last read and
+// the truncation is lost.
+func (t *outputTail) read() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.file.ReadAt(buf, t.offset)
+		if n > 0 {
+			t.w.Write(buf[:n])
+			t.offset += int64(n)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if info, err := t.file.Stat(); err == nil && info.Size() < t.offset {
+		// Truncated by someone else
+		t.offset = 0
+	}
+	if t.maxSize > 0 && t.offset >= t.maxSize {
+		if t.file.Truncate(0) == nil {
+			t.offset = 0
+		}
+	}
+}
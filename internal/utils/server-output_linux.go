@@ -0,0 +1,64 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// outputNotifier reports writes to the output files of a server by their
+// index, in the order they happened.
+type outputNotifier struct {
+	file    *os.File
+	changes chan int
+}
+
+func newOutputNotifier(paths []string) (*outputNotifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// As for the watch mode watcher, the runtime poller serves the fd so
+	// that Close unblocks Read
+	n := &outputNotifier{file: os.NewFile(uintptr(fd), "inotify"), changes: make(chan int, 64)}
+	files := make(map[int32]int, len(paths))
+	for i, path := range paths {
+		wd, err := syscall.InotifyAddWatch(fd, path, syscall.IN_MODIFY)
+		if err != nil {
+			n.file.Close()
+			return nil, err
+		}
+		files[int32(wd)] = i
+	}
+	go n.run(files)
+	return n, nil
+}
+
+func (n *outputNotifier) Close() error {
+	return n.file.Close()
+}
+
+func (n *outputNotifier) run(files map[int32]int) {
+	buf := make([]byte, 16*1024)
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= count; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+			i, ok := files[event.Wd]
+			if !ok {
+				continue
+			}
+			// When the reader falls behind the files are polled anyway
+			select {
+			case n.changes <- i:
+			default:
+			}
+		}
+	}
+}
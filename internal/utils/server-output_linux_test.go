@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func TestServerOutput_FilesKeepOrder(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	sm := NewServerManager()
+	dir := t.TempDir()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: dir, ServerLogDir: dir}
+	serverInfo, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{
+		ID:      "ordered",
+		Command: "sh",
+		Args:    []string{"-c", "echo one; sleep 0.02; echo two >&2; sleep 0.02; echo three; sleep 5"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = sm.StopServer("ordered", true) })
+
+	// Well within the poll interval of each line, and in order across streams
+	if !waitForLog(serverInfo, "three", time.Second) {
+		t.Fatalf("Expected output to be logged, got %v", serverInfo.Logs.GetAll())
+	}
+	var got []string
+	for _, line := range serverInfo.Lines.GetAll() {
+		if line.Stream != StreamSystem {
+			got = append(got, line.Stream+":"+line.Text)
+		}
+	}
+	want := []string{"stdout:one", "stderr:two", "stdout:three"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected lines %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected lines %v, got %v", want, got)
+			break
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package utils
+
+import "errors"
+
+// outputNotifier is only available on Linux; other platforms poll.
+type outputNotifier struct {
+	changes chan int
+}
+
+func newOutputNotifier(paths []string) (*outputNotifier, error) {
+	return nil, errors.New("inotify is not supported on this platform")
+}
+
+func (n *outputNotifier) Close() error {
+	return nil
+}
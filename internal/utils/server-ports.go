@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	// portPlaceholder matches {{port:name}} in server arguments, environment
+	// values and health checks
+	portPlaceholder = regexp.MustCompile(`\{\{port:([A-Za-z0-9_-]+)\}\}`)
+	portNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// maxPortAttempts bounds how often allocation retries a port the kernel
+// hands out that is already reserved for another server
+const maxPortAttempts = 100
+
+// reservePort finds a free TCP port on the loopback interface that is not
+// reserved for another server or stack and reserves it for owner.
+func (sm *ServerManager) reservePort(owner interface{}) (int, error) {
+	sm.portMu.Lock()
+	defer sm.portMu.Unlock()
+
+	for i := 0; i < maxPortAttempts; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return 0, err
+		}
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+		if _, taken := sm.ports[port]; taken {
+			continue
+		}
+		if sm.ports == nil {
+			sm.ports = make(map[int]interface{})
+		}
+		sm.ports[port] = owner
+		return port, nil
+	}
+	return 0, fmt.Errorf("no free port found after %d attempts", maxPortAttempts)
+}
+
+// releasePorts releases every port reserved for owner
+func (sm *ServerManager) releasePorts(owner interface{}) {
+	sm.portMu.Lock()
+	defer sm.portMu.Unlock()
+	for port, o := range sm.ports {
+		if o == owner {
+			delete(sm.ports, port)
+		}
+	}
+}
+
+// allocatePorts reserves a port for every name in opts.Ports and every
+// {{port:name}} placeholder not already in opts.AssignedPorts, and replaces
+// the placeholders with the port numbers.
+func (sm *ServerManager) allocatePorts(opts *ServerOptions, owner interface{}) (map[string]int, error) {
+	names := append([]string(nil), opts.Ports...)
+	collect := func(value string) {
+		for _, match := range portPlaceholder.FindAllStringSubmatch(value, -1) {
+			names = append(names, match[1])
+		}
+	}
+	for _, arg := range opts.Args {
+		collect(arg)
+	}
+	for _, value := range opts.EnvVars {
+		collect(value)
+	}
+	if opts.HealthCheck != nil {
+		collect(opts.HealthCheck.TCPAddress)
+		collect(opts.HealthCheck.HTTPURL)
+	}
+
+	ports := make(map[string]int)
+	for name, port := range opts.AssignedPorts {
+		ports[name] = port
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !portNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid port name %q", name)
+		}
+		if _, ok := ports[name]; ok {
+			continue
+		}
+		port, err := sm.reservePort(owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate port %s: %w", name, err)
+		}
+		ports[name] = port
+	}
+	if len(ports) == 0 {
+		return nil, nil
+	}
+
+	expand := func(value string) string {
+		return portPlaceholder.ReplaceAllStringFunc(value, func(ref string) string {
+			name := portPlaceholder.FindStringSubmatch(ref)[1]
+			return strconv.Itoa(ports[name])
+		})
+	}
+	args := make([]string, len(opts.Args))
+	for i, arg := range opts.Args {
+		args[i] = expand(arg)
+	}
+	opts.Args = args
+	if opts.EnvVars != nil {
+		env := make(map[string]string, len(opts.EnvVars))
+		for key, value := range opts.EnvVars {
+			env[key] = expand(value)
+		}
+		opts.EnvVars = env
+	}
+	if opts.HealthCheck != nil {
+		hc := *opts.HealthCheck
+		hc.TCPAddress = expand(hc.TCPAddress)
+		hc.HTTPURL = expand(hc.HTTPURL)
+		opts.HealthCheck = &hc
+	}
+	return ports, nil
+}
+
+// Ports returns the ports allocated for the server by name, or nil.
+func (s *ServerInfo) Ports() map[string]int {
+	ports, _ := s.Metadata["ports"].(map[string]int)
+	return ports
+}
+
+// holdPorts reserves ports already known to belong to owner, such as those
+// of a server restored from the state file
+func (sm *ServerManager) holdPorts(ports map[string]int, owner interface{}) {
+	sm.portMu.Lock()
+	defer sm.portMu.Unlock()
+	for _, port := range ports {
+		if sm.ports == nil {
+			sm.ports = make(map[int]interface{})
+		}
+		sm.ports[port] = owner
+	}
+}
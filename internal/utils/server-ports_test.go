@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func TestAllocatePorts(t *testing.T) {
+	sm := NewServerManager()
+	owner := &ServerInfo{}
+	opts := ServerOptions{
+		Args:    []string{"-addr", ":{{port:http}}", "-grpc={{port:grpc}}", "-again={{port:http}}"},
+		EnvVars: map[string]string{"ADMIN_ADDR": "127.0.0.1:{{port:admin}}", "MODE": "dev"},
+		HealthCheck: &HealthCheck{
+			HTTPURL: "http://127.0.0.1:{{port:http}}/healthz",
+		},
+		Ports:         []string{"metrics"},
+		AssignedPorts: map[string]int{"grpc": 9090},
+	}
+	ports, err := sm.allocatePorts(&opts, owner)
+	if err != nil {
+		t.Fatalf("Failed to allocate ports: %v", err)
+	}
+	if len(ports) != 4 || ports["grpc"] != 9090 {
+		t.Fatalf("Expected http, grpc, admin and metrics ports, got %v", ports)
+	}
+	http := strconv.Itoa(ports["http"])
+	if opts.Args[1] != ":"+http || opts.Args[2] != "-grpc=9090" || opts.Args[3] != "-again="+http {
+		t.Errorf("Unexpected args: %v", opts.Args)
+	}
+	if opts.EnvVars["ADMIN_ADDR"] != "127.0.0.1:"+strconv.Itoa(ports["admin"]) || opts.EnvVars["MODE"] != "dev" {
+		t.Errorf("Unexpected env: %v", opts.EnvVars)
+	}
+	if opts.HealthCheck.HTTPURL != "http://127.0.0.1:"+http+"/healthz" {
+		t.Errorf("Unexpected health URL: %s", opts.HealthCheck.HTTPURL)
+	}
+
+	// Reserved ports are not handed out again until released
+	for _, name := range []string{"http", "admin", "metrics"} {
+		if sm.ports[ports[name]] != owner {
+			t.Errorf("Expected port %s to be reserved", name)
+		}
+	}
+	if _, reserved := sm.ports[9090]; reserved {
+		t.Error("Expected assigned port not to be reserved again")
+	}
+	sm.releasePorts(owner)
+	if len(sm.ports) != 0 {
+		t.Errorf("Expected ports to be released, got %v", sm.ports)
+	}
+
+	if _, err := sm.allocatePorts(&ServerOptions{Ports: []string{"bad name"}}, owner); err == nil {
+		t.Error("Expected error for invalid port name")
+	}
+}
+
+func TestStartServer_Ports(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	start := func() *ServerInfo {
+		serverInfo, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{
+			ID:      "ported",
+			Command: "sh",
+			Args:    []string{"-c", "echo port {{port:http}} $ADDR"},
+			EnvVars: map[string]string{"ADDR": ":{{port:http}}"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to start server: %v", err)
+		}
+		return serverInfo
+	}
+
+	serverInfo := start()
+	port := serverInfo.Ports()["http"]
+	if port == 0 {
+		t.Fatalf("Expected port in metadata, got %v", serverInfo.Metadata)
+	}
+	if !waitForLog(serverInfo, "port "+strconv.Itoa(port)+" :"+strconv.Itoa(port), 5*time.Second) {
+		t.Errorf("Expected placeholders to be substituted, got %v", serverInfo.Logs.GetAll())
+	}
+	waitForStatus(serverInfo, "stopped", 5*time.Second)
+
+	// Starting the server again releases the ports of the previous run
+	start()
+	sm.portMu.Lock()
+	reserved := len(sm.ports)
+	sm.portMu.Unlock()
+	if reserved != 1 {
+		t.Errorf("Expected only the new server's port to be reserved, got %d", reserved)
+	}
+}
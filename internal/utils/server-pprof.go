@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+const (
+	defaultCPUProfileDuration = 10 * time.Second
+	defaultPprofPrefix        = "/debug/pprof"
+	maxServerProfiles         = 20 // profiles remembered per server
+)
+
+// pprofEndpoints maps profile types to their net/http/pprof endpoint
+var pprofEndpoints = map[string]string{
+	"cpu":       "profile",
+	"heap":      "heap",
+	"allocs":    "allocs",
+	"goroutine": "goroutine",
+	"block":     "block",
+	"mutex":     "mutex",
+}
+
+// ProfileOptions configures capturing a profile from a managed server.
+type ProfileOptions struct {
+	Profile    string        // cpu, heap, allocs, goroutine, block or mutex
+	Duration   time.Duration // how long to record a CPU profile, default 10s
+	Port       string        // allocated port name or port number, see SendHTTP
+	PathPrefix string        // where net/http/pprof is served, default /debug/pprof
+	Output     string        // file to write, relative to the server's working directory; default a file in the server's profile directory
+}
+
+// ProfileArtifact is a profile captured from a managed server.
+type ProfileArtifact struct {
+	ServerID string    `json:"server_id"`
+	Profile  string    `json:"profile"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration,omitempty"` // recording time of a CPU profile
+}
+
+// ProfileTypes returns the profile types that can be captured, sorted.
+func ProfileTypes() []string {
+	types := make([]string, 0, len(pprofEndpoints))
+	for name := range pprofEndpoints {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// serverProfileDir returns the directory profiles of a server are stored in
+// when no output file is given
+func serverProfileDir(id string) string {
+	return filepath.Join(os.TempDir(), "mcp-go-profiles", url.PathEscape(id))
+}
+
+// CaptureProfile fetches a profile from a managed server that serves
+// net/http/pprof over loopback and stores it as a file. The server must
+// enable block and mutex profiling itself for those profiles to have samples.
+func (sm *ServerManager) CaptureProfile(ctx context.Context, id string, opts ProfileOptions) (*ProfileArtifact, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, ok := pprofEndpoints[opts.Profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile type %q, supported: %s", opts.Profile, strings.Join(ProfileTypes(), ", "))
+	}
+	port, err := serverInfo.httpPort(opts.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	// A relative output file is relative to the server's working directory,
+	// and the file must be in the workspace like the server itself
+	path := opts.Output
+	if path != "" {
+		if !filepath.IsAbs(path) {
+			dir := serverInfo.WorkingDir
+			if dir == "" && serverInfo.cfg != nil {
+				dir = serverInfo.cfg.WorkingDirectory
+			}
+			path = filepath.Join(dir, path)
+		}
+		if serverInfo.cfg != nil {
+			if err := serverInfo.cfg.CheckWorkingDir(filepath.Dir(path)); err != nil {
+				return nil, fmt.Errorf("invalid output file: %w", err)
+			}
+		}
+	}
+
+	prefix := strings.TrimSuffix(opts.PathPrefix, "/")
+	if prefix == "" {
+		prefix = defaultPprofPrefix
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		return nil, fmt.Errorf("path prefix must start with /, got %q", opts.PathPrefix)
+	}
+	target := "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)) + prefix + "/" + endpoint
+
+	// The request lasts as long as the CPU profile is recorded
+	timeout := defaultHTTPTimeout
+	artifact := &ProfileArtifact{ServerID: id, Profile: opts.Profile, Time: time.Now()}
+	if opts.Profile == "cpu" {
+		duration := opts.Duration
+		if duration <= 0 {
+			duration = defaultCPUProfileDuration
+		}
+		seconds := int(duration.Round(time.Second).Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		target += "?seconds=" + strconv.Itoa(seconds)
+		timeout += time.Duration(seconds) * time.Second
+		artifact.Duration = (time.Duration(seconds) * time.Second).String()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := loopbackClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s profile: %w", opts.Profile, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("server %s does not serve %s/ (is net/http/pprof imported?)", id, prefix)
+		}
+		return nil, fmt.Errorf("fetching %s profile returned status %d: %s", opts.Profile, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if path == "" {
+		path = filepath.Join(serverProfileDir(id), fmt.Sprintf("%s-%s.pb.gz", opts.Profile, artifact.Time.Format("20060102-150405.000")))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	size, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to save %s profile: %w", opts.Profile, err)
+	}
+	artifact.Path = path
+	artifact.Size = size
+
+	serverInfo.logMutex.Lock()
+	serverInfo.profiles = append(serverInfo.profiles, *artifact)
+	if len(serverInfo.profiles) > maxServerProfiles {
+		serverInfo.profiles = serverInfo.profiles[len(serverInfo.profiles)-maxServerProfiles:]
+	}
+	serverInfo.logMutex.Unlock()
+
+	return artifact, nil
+}
+
+// Profiles returns the profiles captured from the server, oldest first.
+func (s *ServerInfo) Profiles() []ProfileArtifact {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	return append([]ProfileArtifact(nil), s.profiles...)
+}
+
+// PreviousProfile returns the most recent profile of the given type captured
+// before the one at path, or nil.
+func (s *ServerInfo) PreviousProfile(profile, path string) *ProfileArtifact {
+	profiles := s.Profiles()
+	for i := len(profiles) - 1; i >= 0; i-- {
+		if profiles[i].Profile == profile && profiles[i].Path != path {
+			artifact := profiles[i]
+			return &artifact
+		}
+	}
+	return nil
+}
+
+// SummarizeProfile returns the top entries of a profile as reported by
+// go tool pprof. If base is set, the profile is compared with it and the
+// entries show the growth since base.
+func SummarizeProfile(ctx context.Context, cfg *config.Config, path, base string, nodes int) (string, error) {
+	if nodes <= 0 {
+		nodes = 20
+	}
+	args := []string{"tool", "pprof", "-top", fmt.Sprintf("-nodecount=%d", nodes)}
+	if base != "" {
+		args = append(args, "-diff_base="+base)
+	}
+	args = append(args, path)
+
+	result, err := ExecuteGoCommand(ctx, cfg, "go", args, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("go tool pprof failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// newPprofServer registers a managed server that serves net/http/pprof
+func newPprofServer(t *testing.T, sm *ServerManager, id string) *ServerInfo {
+	t.Helper()
+	return newHTTPServer(t, sm, id, func(s *ServerInfo, w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutPrefix(r.URL.Path, "/debug/pprof/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if name == "profile" {
+			pprof.Profile(w, r)
+			return
+		}
+		pprof.Handler(name).ServeHTTP(w, r)
+	})
+}
+
+func TestCaptureProfile(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := newPprofServer(t, sm, "pprof")
+	output := filepath.Join(t.TempDir(), "heap.pb.gz")
+
+	heap, err := sm.CaptureProfile(context.Background(), "pprof", ProfileOptions{Profile: "heap", Output: output})
+	if err != nil {
+		t.Fatalf("Failed to capture heap profile: %v", err)
+	}
+	if heap.Path != output || heap.Size == 0 {
+		t.Errorf("Expected profile written to %s, got %+v", output, heap)
+	}
+	if info, err := os.Stat(output); err != nil || info.Size() != heap.Size {
+		t.Errorf("Expected %d bytes on disk: %v", heap.Size, err)
+	}
+
+	goroutine, err := sm.CaptureProfile(context.Background(), "pprof", ProfileOptions{Profile: "goroutine"})
+	if err != nil {
+		t.Fatalf("Failed to capture goroutine profile: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(serverProfileDir("pprof")) })
+	if filepath.Dir(goroutine.Path) != serverProfileDir("pprof") {
+		t.Errorf("Expected profile in the server's profile directory, got %s", goroutine.Path)
+	}
+
+	if profiles := serverInfo.Profiles(); len(profiles) != 2 {
+		t.Errorf("Expected 2 profiles to be remembered, got %d", len(profiles))
+	}
+	if prev := serverInfo.PreviousProfile("heap", "other.pb.gz"); prev == nil || prev.Path != output {
+		t.Errorf("Expected the heap profile as previous, got %+v", prev)
+	}
+	if prev := serverInfo.PreviousProfile("heap", output); prev != nil {
+		t.Errorf("Expected no heap profile before the first, got %+v", prev)
+	}
+}
+
+func TestCaptureProfile_Errors(t *testing.T) {
+	sm := NewServerManager()
+	newHTTPServer(t, sm, "plain", func(s *ServerInfo, w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	newHTTPServer(t, sm, "redirect", func(s *ServerInfo, w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/elsewhere" {
+			w.Write([]byte("not a profile"))
+			return
+		}
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	})
+
+	if _, err := sm.CaptureProfile(context.Background(), "plain", ProfileOptions{Profile: "threads"}); err == nil {
+		t.Error("Expected error for unknown profile type")
+	}
+	_, err := sm.CaptureProfile(context.Background(), "plain", ProfileOptions{Profile: "heap", Output: filepath.Join(t.TempDir(), "heap")})
+	if err == nil || !strings.Contains(err.Error(), "net/http/pprof") {
+		t.Errorf("Expected error hinting at net/http/pprof, got %v", err)
+	}
+	_, err = sm.CaptureProfile(context.Background(), "redirect", ProfileOptions{Profile: "heap", Output: filepath.Join(t.TempDir(), "heap")})
+	if err == nil || !strings.Contains(err.Error(), "status 302") {
+		t.Errorf("Expected the redirect not to be followed, got %v", err)
+	}
+}
+
+func TestCaptureProfile_OutputPath(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := newPprofServer(t, sm, "pprof")
+	root := t.TempDir()
+	serverInfo.WorkingDir = filepath.Join(root, "app")
+	serverInfo.cfg = &config.Config{WorkspaceRoots: []string{root}, RestrictToWorkspace: true}
+
+	artifact, err := sm.CaptureProfile(context.Background(), "pprof", ProfileOptions{Profile: "heap", Output: "profiles/heap.pb.gz"})
+	if err != nil {
+		t.Fatalf("CaptureProfile failed: %v", err)
+	}
+	if want := filepath.Join(root, "app", "profiles", "heap.pb.gz"); artifact.Path != want {
+		t.Errorf("Expected the output relative to the server's working directory %s, got %s", want, artifact.Path)
+	}
+
+	outside := filepath.Join(t.TempDir(), "heap.pb.gz")
+	for _, output := range []string{"../../escaped.pb.gz", outside} {
+		_, err := sm.CaptureProfile(context.Background(), "pprof", ProfileOptions{Profile: "heap", Output: output})
+		if err == nil || !strings.Contains(err.Error(), "outside the configured workspace roots") {
+			t.Errorf("Expected output %s to be rejected, got %v", output, err)
+		}
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Errorf("Expected no file outside the workspace, got %v", err)
+	}
+}
+
+func TestSummarizeProfile(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("Go command not available")
+	}
+	sm := NewServerManager()
+	newPprofServer(t, sm, "pprof")
+	dir := t.TempDir()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: dir}
+
+	base, err := sm.CaptureProfile(context.Background(), "pprof", ProfileOptions{Profile: "heap", Output: filepath.Join(dir, "base.pb.gz")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := sm.CaptureProfile(context.Background(), "pprof", ProfileOptions{Profile: "heap", Output: filepath.Join(dir, "current.pb.gz")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := SummarizeProfile(context.Background(), cfg, current.Path, "", 5)
+	if err != nil {
+		t.Fatalf("Failed to summarize profile: %v", err)
+	}
+	if !strings.Contains(summary, "flat") {
+		t.Errorf("Expected pprof top output, got %q", summary)
+	}
+	if _, err := SummarizeProfile(context.Background(), cfg, current.Path, base.Path, 5); err != nil {
+		t.Errorf("Failed to diff profiles: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !unix
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op where process groups are not supported; only
+// the server process itself is signalled.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup is not supported, so servers are killed straight away
+func terminateProcessGroup(pid int) error {
+	return errGracefulStopUnsupported
+}
+
+// killProcessGroup kills the server process
+func killProcessGroup(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return errProcessGone
+	}
+	if err := process.Kill(); err != nil {
+		if errors.Is(err, os.ErrProcessDone) {
+			return errProcessGone
+		}
+		return err
+	}
+	return nil
+}
+
+// processGroupAlive reports false as the group cannot be inspected; the
+// server process itself is tracked through its done channel.
+func processGroupAlive(pid int) bool {
+	return false
+}
+
+// parseSignal fails as signals other than kill cannot be sent on this platform
+func parseSignal(name string) (int, string, error) {
+	return 0, "", errSignalsUnsupported
+}
+
+// sendSignal is not supported on this platform
+func sendSignal(pid int, sig int, group bool) error {
+	return errSignalsUnsupported
+}
@@ -0,0 +1,89 @@
+//go:build unix
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group led by the
+// server process, so that children such as the binary built by "go run"
+// can be signalled along with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd.Process.Pid)
+	}
+}
+
+// terminateProcessGroup asks every process in the group to shut down
+func terminateProcessGroup(pid int) error {
+	return signalProcessGroup(pid, syscall.SIGTERM)
+}
+
+// killProcessGroup kills every process in the group
+func killProcessGroup(pid int) error {
+	return signalProcessGroup(pid, syscall.SIGKILL)
+}
+
+// processGroupAlive reports whether any process in the group is still running
+func processGroupAlive(pid int) bool {
+	return signalProcessGroup(pid, 0) == nil
+}
+
+func signalProcessGroup(pid int, sig syscall.Signal) error {
+	err := syscall.Kill(-pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return errProcessGone
+	}
+	return err
+}
+
+// signalNames are the signals that can be sent to a server by name
+var signalNames = map[string]syscall.Signal{
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGINT":   syscall.SIGINT,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGTERM":  syscall.SIGTERM,
+	"SIGKILL":  syscall.SIGKILL,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGWINCH": syscall.SIGWINCH,
+	"SIGSTOP":  syscall.SIGSTOP,
+	"SIGCONT":  syscall.SIGCONT,
+}
+
+// parseSignal returns the signal with the given name, with or without the
+// SIG prefix and in any case, or its number
+func parseSignal(name string) (syscall.Signal, string, error) {
+	if n, err := strconv.Atoi(name); err == nil {
+		for sigName, sig := range signalNames {
+			if int(sig) == n {
+				return sig, sigName, nil
+			}
+		}
+		return 0, "", fmt.Errorf("unsupported signal number %d", n)
+	}
+	sigName := normalizeSignalName(name)
+	sig, ok := signalNames[sigName]
+	if !ok {
+		return 0, "", fmt.Errorf("unsupported signal %q", name)
+	}
+	return sig, sigName, nil
+}
+
+// sendSignal sends sig to the server process, or to its whole process group
+func sendSignal(pid int, sig syscall.Signal, group bool) error {
+	if group {
+		return signalProcessGroup(pid, sig)
+	}
+	err := syscall.Kill(pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return errProcessGone
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Restart policies of a managed server
+const (
+	RestartNever     = "never"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+const (
+	defaultMaxRestarts    = 5
+	defaultRestartBackoff = time.Second
+	maxRestartBackoff     = time.Minute
+	maxExitHistory        = 10
+
+	// A run lasting at least this long resets the consecutive restart count
+	restartResetAfter = time.Minute
+)
+
+// ExitRecord describes one exit of a server process.
+type ExitRecord struct {
+	Time     time.Time `json:"time"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+	Uptime   string    `json:"uptime,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// validateRestartPolicy checks the restart policy and fills in defaults
+func (opts *ServerOptions) validateRestartPolicy() error {
+	switch opts.RestartPolicy {
+	case "":
+		opts.RestartPolicy = RestartNever
+	case RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		return fmt.Errorf("invalid restart policy %q: must be %s, %s or %s", opts.RestartPolicy, RestartNever, RestartOnFailure, RestartAlways)
+	}
+	if opts.MaxRestarts < 0 {
+		return fmt.Errorf("max restarts must not be negative")
+	}
+	if opts.MaxRestarts == 0 {
+		opts.MaxRestarts = defaultMaxRestarts
+	}
+	if opts.RestartBackoff <= 0 {
+		opts.RestartBackoff = defaultRestartBackoff
+	}
+	return nil
+}
+
+// RestartState returns the restart count and a copy of the exit history.
+func (s *ServerInfo) RestartState() (int, []ExitRecord) {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	return s.Restarts, append([]ExitRecord(nil), s.ExitHistory...)
+}
+
+// recordExit appends to the exit history. Must be called with logMutex held.
+func (s *ServerInfo) recordExit(record ExitRecord) {
+	s.ExitHistory = append(s.ExitHistory, record)
+	if len(s.ExitHistory) > maxExitHistory {
+		s.ExitHistory = s.ExitHistory[len(s.ExitHistory)-maxExitHistory:]
+	}
+}
+
+// nextRestart decides whether the process that just exited with waitErr is
+// restarted automatically and after what delay. The delay doubles with each
+// consecutive restart. Must be called with logMutex held.
+func (s *ServerInfo) nextRestart(waitErr error) (time.Duration, bool) {
+	if s.stopping {
+		return 0, false
+	}
+	switch s.RestartPolicy {
+	case RestartAlways:
+	case RestartOnFailure:
+		if waitErr == nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if time.Since(s.StartTime) >= restartResetAfter {
+		s.retries = 0
+	}
+	if s.retries >= s.MaxRestarts {
+		return 0, false
+	}
+
+	delay := s.opts.RestartBackoff << s.retries
+	if delay > maxRestartBackoff || delay <= 0 {
+		delay = maxRestartBackoff
+	}
+	s.retries++
+	s.Restarts++
+	return delay, true
+}
+
+// RestartServer stops a server if it is running and launches it again with
+// its original command, arguments, environment and working directory.
+func (sm *ServerManager) RestartServer(ctx context.Context, id string) (*ServerInfo, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverInfo.logMutex.RLock()
+	status := serverInfo.Status
+	done := serverInfo.done
+	serverInfo.logMutex.RUnlock()
+
+	if status == "running" || status == "restarting" {
+		if err := sm.StopServer(id, false); err != nil {
+			return nil, err
+		}
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		return nil, fmt.Errorf("server %s did not exit", id)
+	}
+
+	// Stop the log collection and health monitoring of the previous run
+	serverInfo.cancel()
+
+	serverInfo.logMutex.Lock()
+	serverInfo.Restarts++
+	serverInfo.retries = 0
+	serverInfo.logMutex.Unlock()
+
+	if err := sm.start(ctx, serverInfo); err != nil {
+		serverInfo.logMutex.Lock()
+		serverInfo.Status = "error"
+		serverInfo.recordExit(ExitRecord{Time: time.Now(), Error: err.Error()})
+		serverInfo.logMutex.Unlock()
+		return nil, err
+	}
+	return serverInfo, nil
+}
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+func startRestartServer(t *testing.T, sm *ServerManager, opts ServerOptions) *ServerInfo {
+	t.Helper()
+	if _, err := exec.LookPath(opts.Command); err != nil {
+		t.Skipf("%s not available", opts.Command)
+	}
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	serverInfo, err := sm.StartServerWithOptions(context.Background(), cfg, opts)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = sm.StopServer(opts.ID, true) })
+	return serverInfo
+}
+
+// waitForStatus polls until the server reaches status or the timeout expires
+func waitForStatus(serverInfo *ServerInfo, status string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		serverInfo.logMutex.RLock()
+		current := serverInfo.Status
+		serverInfo.logMutex.RUnlock()
+		if current == status {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
+
+// waitForLog polls until the server has logged text or the timeout expires
+func waitForLog(serverInfo *ServerInfo, text string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for _, line := range serverInfo.Lines.GetAll() {
+			if strings.Contains(line.Text, text) {
+				return true
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
+
+func TestRestartPolicy_OnFailure(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:             "restart-fail",
+		Command:        "false",
+		RestartPolicy:  RestartOnFailure,
+		MaxRestarts:    2,
+		RestartBackoff: 10 * time.Millisecond,
+	})
+
+	if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+		t.Fatal("Expected server to stop after exhausting restarts")
+	}
+	restarts, history := serverInfo.RestartState()
+	if restarts != 2 {
+		t.Errorf("Expected 2 restarts, got %d", restarts)
+	}
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 exits in history, got %d", len(history))
+	}
+	if history[0].ExitCode == nil || *history[0].ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %v", history[0].ExitCode)
+	}
+}
+
+func TestRestartPolicy_OnFailureCleanExit(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:             "restart-clean",
+		Command:        "true",
+		RestartPolicy:  RestartOnFailure,
+		RestartBackoff: 10 * time.Millisecond,
+	})
+
+	if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+		t.Fatal("Expected server to stop")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if restarts, _ := serverInfo.RestartState(); restarts != 0 {
+		t.Errorf("Expected no restarts after clean exit, got %d", restarts)
+	}
+}
+
+func TestRestartPolicy_StopSuppressesRestart(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:             "restart-stop",
+		Command:        "sleep",
+		Args:           []string{"5"},
+		RestartPolicy:  RestartAlways,
+		RestartBackoff: 10 * time.Millisecond,
+	})
+
+	if err := sm.StopServer("restart-stop", false); err != nil {
+		t.Fatalf("StopServer failed: %v", err)
+	}
+	if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+		t.Fatal("Expected server to stop")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if restarts, _ := serverInfo.RestartState(); restarts != 0 {
+		t.Errorf("Expected no restarts after stop, got %d", restarts)
+	}
+}
+
+func TestRestartPolicy_StopDuringBackoff(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		sm := NewServerManager()
+		serverInfo := startRestartServer(t, sm, ServerOptions{
+			ID:             "restart-backoff",
+			Command:        "false",
+			RestartPolicy:  RestartAlways,
+			MaxRestarts:    100,
+			RestartBackoff: time.Millisecond,
+		})
+		if !waitForStatus(serverInfo, "restarting", 5*time.Second) {
+			t.Fatal("Expected server to wait for a restart")
+		}
+
+		// Racing the end of the backoff, the server must end up stopped
+		_ = sm.StopServer("restart-backoff", false)
+		if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+			t.Fatalf("Expected server to stop, got %s", serverInfo.ProcessState().Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if status := serverInfo.ProcessState().Status; status != "stopped" {
+			t.Fatalf("Expected server to stay stopped, got %s", status)
+		}
+	}
+}
+
+func TestRestartServer(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "restart-manual",
+		Command: "sleep",
+		Args:    []string{"5"},
+	})
+	firstPID := serverInfo.PID
+
+	restarted, err := sm.RestartServer(context.Background(), "restart-manual")
+	if err != nil {
+		t.Fatalf("RestartServer failed: %v", err)
+	}
+	if restarted != serverInfo {
+		t.Error("Expected the same server info to be reused")
+	}
+	if !serverInfo.IsRunning() {
+		t.Error("Expected server to be running after restart")
+	}
+
+	serverInfo.logMutex.RLock()
+	pid := serverInfo.PID
+	serverInfo.logMutex.RUnlock()
+	if pid == firstPID {
+		t.Error("Expected a new process after restart")
+	}
+	if restarts, history := serverInfo.RestartState(); restarts != 1 || len(history) != 1 {
+		t.Errorf("Expected 1 restart and 1 exit, got %d and %d", restarts, len(history))
+	}
+}
+
+func TestValidateRestartPolicy(t *testing.T) {
+	opts := ServerOptions{}
+	if err := opts.validateRestartPolicy(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.RestartPolicy != RestartNever || opts.MaxRestarts != defaultMaxRestarts || opts.RestartBackoff != defaultRestartBackoff {
+		t.Errorf("Expected defaults, got %+v", opts)
+	}
+
+	opts = ServerOptions{RestartPolicy: "sometimes"}
+	if err := opts.validateRestartPolicy(); err == nil {
+		t.Error("Expected error for invalid restart policy")
+	}
+}
@@ -0,0 +1,446 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// StackFileName is the stack definition looked up in the working directory
+// when no file is given.
+const StackFileName = "mcp-stack.yaml"
+
+const defaultStackReadyTimeout = 30 * time.Second
+
+var (
+	stackNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+	stackVarPattern  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// StackDefinition describes servers that are started and stopped together.
+type StackDefinition struct {
+	Name     string                   `yaml:"name"`
+	Env      map[string]string        `yaml:"env"` // passed to every service
+	Services map[string]*StackService `yaml:"services"`
+
+	path string
+}
+
+// StackService describes one server of a stack.
+type StackService struct {
+	Command       string            `yaml:"command"`
+	Args          []string          `yaml:"args"`
+	WorkingDir    string            `yaml:"working_dir"` // relative to the stack file
+	Env           map[string]string `yaml:"env"`
+	Ports         []string          `yaml:"ports"` // names of free ports to allocate
+	DependsOn     []string          `yaml:"depends_on"`
+	Health        *StackHealth      `yaml:"health"`
+	ReadyTimeout  string            `yaml:"ready_timeout"`
+	RestartPolicy string            `yaml:"restart_policy"`
+	MaxRestarts   int               `yaml:"max_restarts"`
+	StopTimeout   string            `yaml:"stop_timeout"`
+	LogSize       int               `yaml:"log_size"`
+}
+
+// StackHealth is the health check of a stack service.
+type StackHealth struct {
+	TCP        string `yaml:"tcp"`
+	HTTP       string `yaml:"http"`
+	Status     int    `yaml:"status"`
+	LogPattern string `yaml:"log_pattern"`
+	Interval   string `yaml:"interval"`
+}
+
+// LoadStack reads and validates a stack definition. The stack is named after
+// the directory of the file unless it sets a name.
+func LoadStack(path string) (*StackDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var def StackDefinition
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid stack file %s: %w", path, err)
+	}
+	def.path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if def.Name == "" {
+		def.Name = filepath.Base(filepath.Dir(def.path))
+	}
+	if err := def.validate(); err != nil {
+		return nil, fmt.Errorf("invalid stack file %s: %w", path, err)
+	}
+	return &def, nil
+}
+
+// validate checks names, commands and dependencies of the stack
+func (def *StackDefinition) validate() error {
+	if !stackNamePattern.MatchString(def.Name) {
+		return fmt.Errorf("invalid stack name %q", def.Name)
+	}
+	if len(def.Services) == 0 {
+		return fmt.Errorf("stack has no services")
+	}
+	for name, svc := range def.Services {
+		if !stackNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid service name %q", name)
+		}
+		if svc == nil || svc.Command == "" {
+			return fmt.Errorf("service %s has no command", name)
+		}
+		for _, dep := range svc.DependsOn {
+			if _, ok := def.Services[dep]; !ok {
+				return fmt.Errorf("service %s depends on unknown service %s", name, dep)
+			}
+		}
+		for _, port := range svc.Ports {
+			if !stackNamePattern.MatchString(port) {
+				return fmt.Errorf("service %s has invalid port name %q", name, port)
+			}
+		}
+	}
+	_, err := def.startOrder()
+	return err
+}
+
+// startOrder returns the services so that each comes after its
+// dependencies, in name order where the dependencies allow.
+func (def *StackDefinition) startOrder() ([]string, error) {
+	pending := make(map[string]int, len(def.Services))
+	for name, svc := range def.Services {
+		pending[name] = len(svc.DependsOn)
+	}
+
+	var order []string
+	for len(pending) > 0 {
+		var ready []string
+		for name, deps := range pending {
+			if deps == 0 {
+				ready = append(ready, name)
+			}
+		}
+		if len(ready) == 0 {
+			var cycle []string
+			for name := range pending {
+				cycle = append(cycle, name)
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("dependency cycle between services %s", strings.Join(cycle, ", "))
+		}
+		sort.Strings(ready)
+		for _, name := range ready {
+			delete(pending, name)
+			order = append(order, name)
+		}
+		for name := range pending {
+			for _, dep := range def.Services[name].DependsOn {
+				for _, started := range ready {
+					if dep == started {
+						pending[name]--
+					}
+				}
+			}
+		}
+	}
+	return order, nil
+}
+
+// stackPortVar returns the environment variable holding an allocated port,
+// e.g. API_HTTP_PORT for port "http" of service "api"
+func stackPortVar(service, port string) string {
+	name := strings.ToUpper(service + "_" + port + "_PORT")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// expandStackVars replaces ${NAME} with the value of NAME in vars. Other
+// references are left for the service's shell or program to expand.
+func expandStackVars(value string, vars map[string]string) string {
+	return stackVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		if v, ok := vars[ref[2:len(ref)-1]]; ok {
+			return v
+		}
+		return ref
+	})
+}
+
+// stackState is a stack that has been brought up
+type stackState struct {
+	def     *StackDefinition
+	order   []string
+	ports   map[string]int // environment variable -> allocated port
+	started time.Time
+}
+
+// StackStatus describes a stack and its services.
+type StackStatus struct {
+	Name     string               `json:"name"`
+	File     string               `json:"file"`
+	Started  time.Time            `json:"started"`
+	Ports    map[string]int       `json:"ports,omitempty"`
+	Services []StackServiceStatus `json:"services"` // in start order
+}
+
+// StackServiceStatus describes one service of a stack.
+type StackServiceStatus struct {
+	Service   string   `json:"service"`
+	ServerID  string   `json:"server_id"`
+	Status    string   `json:"status"` // server status, or "not started"
+	Health    string   `json:"health,omitempty"`
+	PID       int      `json:"pid,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	Stopped   string   `json:"stopped,omitempty"` // stop outcome when the stack was brought down
+}
+
+// StackServerID returns the ID of the managed server running a stack service.
+func StackServerID(stack, service string) string {
+	return stack + "." + service
+}
+
+// StackUp starts the services of a stack in dependency order. Each service
+// with a health check must become healthy before the services depending on
+// it are started. Free ports are reserved up front and passed to every
+// service as environment variables along with the stack's env. If a service
+// fails, the services already started are stopped again.
+func (sm *ServerManager) StackUp(ctx context.Context, cfg *config.Config, def *StackDefinition) (*StackStatus, error) {
+	sm.stackMu.Lock()
+	defer sm.stackMu.Unlock()
+
+	order, err := def.startOrder()
+	if err != nil {
+		return nil, err
+	}
+	// Bringing the stack up again would lose track of the ports and servers
+	// of the running one, even if its services have exited since
+	if _, ok := sm.stacks.Load(def.Name); ok {
+		return nil, fmt.Errorf("stack %s is already up; bring it down first", def.Name)
+	}
+	for _, name := range order {
+		if serverInfo, err := sm.GetServer(StackServerID(def.Name, name)); err == nil && serverInfo.IsRunning() {
+			return nil, fmt.Errorf("stack %s is already running", def.Name)
+		}
+	}
+
+	state := &stackState{def: def, order: order, ports: make(map[string]int), started: time.Now()}
+	assigned := make(map[string]map[string]int)
+	vars := make(map[string]string)
+	for _, name := range order {
+		assigned[name] = make(map[string]int)
+		for _, port := range def.Services[name].Ports {
+			p, err := sm.reservePort(state)
+			if err != nil {
+				sm.releasePorts(state)
+				return nil, fmt.Errorf("failed to allocate port %s of service %s: %w", port, name, err)
+			}
+			envVar := stackPortVar(name, port)
+			state.ports[envVar] = p
+			assigned[name][port] = p
+			vars[envVar] = strconv.Itoa(p)
+		}
+	}
+	shared := make(map[string]string, len(def.Env)+len(vars))
+	for key, value := range def.Env {
+		shared[key] = expandStackVars(value, vars)
+	}
+	for key, value := range vars {
+		shared[key] = value
+	}
+	sm.stacks.Store(def.Name, state)
+
+	for i, name := range order {
+		if err := sm.startStackService(ctx, cfg, def, name, shared, assigned[name]); err != nil {
+			sm.stopStackServices(def.Name, order[:i+1])
+			sm.stacks.Delete(def.Name)
+			sm.releasePorts(state)
+			return nil, fmt.Errorf("service %s: %w", name, err)
+		}
+	}
+	return sm.stackStatus(state), nil
+}
+
+// startStackService starts one service and waits for it to become healthy
+func (sm *ServerManager) startStackService(ctx context.Context, cfg *config.Config, def *StackDefinition, name string, shared map[string]string, ports map[string]int) error {
+	svc := def.Services[name]
+	id := StackServerID(def.Name, name)
+
+	env := make(map[string]string, len(shared)+len(svc.Env))
+	for key, value := range shared {
+		env[key] = value
+	}
+	for key, value := range svc.Env {
+		env[key] = expandStackVars(value, shared)
+	}
+	args := make([]string, len(svc.Args))
+	for i, arg := range svc.Args {
+		args[i] = expandStackVars(arg, shared)
+	}
+	workingDir := filepath.Dir(def.path)
+	if svc.WorkingDir != "" {
+		workingDir = svc.WorkingDir
+		if !filepath.IsAbs(workingDir) {
+			workingDir = filepath.Join(filepath.Dir(def.path), workingDir)
+		}
+	}
+
+	opts := ServerOptions{
+		ID:            id,
+		Name:          name,
+		Command:       svc.Command,
+		Args:          args,
+		WorkingDir:    workingDir,
+		EnvVars:       env,
+		LogSize:       svc.LogSize,
+		RestartPolicy: svc.RestartPolicy,
+		MaxRestarts:   svc.MaxRestarts,
+		AssignedPorts: ports,
+	}
+	if svc.StopTimeout != "" {
+		d, err := time.ParseDuration(svc.StopTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid stop_timeout: %w", err)
+		}
+		opts.StopTimeout = d
+	}
+	if svc.Health != nil {
+		opts.HealthCheck = &HealthCheck{
+			TCPAddress:     expandStackVars(svc.Health.TCP, shared),
+			HTTPURL:        expandStackVars(svc.Health.HTTP, shared),
+			ExpectedStatus: svc.Health.Status,
+			LogPattern:     svc.Health.LogPattern,
+		}
+		if svc.Health.Interval != "" {
+			d, err := time.ParseDuration(svc.Health.Interval)
+			if err != nil {
+				return fmt.Errorf("invalid health interval: %w", err)
+			}
+			opts.HealthCheck.Interval = d
+		}
+	}
+	readyTimeout := defaultStackReadyTimeout
+	if svc.ReadyTimeout != "" {
+		d, err := time.ParseDuration(svc.ReadyTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid ready_timeout: %w", err)
+		}
+		readyTimeout = d
+	}
+
+	if _, err := sm.StartServerWithOptions(ctx, cfg, opts); err != nil {
+		return err
+	}
+	if opts.HealthCheck != nil {
+		return sm.WaitReady(ctx, id, readyTimeout)
+	}
+	return nil
+}
+
+// StackDown stops the services of a stack in reverse dependency order.
+func (sm *ServerManager) StackDown(name string) (*StackStatus, error) {
+	sm.stackMu.Lock()
+	defer sm.stackMu.Unlock()
+
+	value, ok := sm.stacks.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("stack not found: %s", name)
+	}
+	state := value.(*stackState)
+	outcomes := sm.stopStackServices(name, state.order)
+	sm.stacks.Delete(name)
+	sm.releasePorts(state)
+
+	status := sm.stackStatus(state)
+	for i := range status.Services {
+		status.Services[i].Stopped = outcomes[status.Services[i].Service]
+	}
+	return status, nil
+}
+
+// stopStackServices stops the given services in reverse order and returns
+// how each of them stopped
+func (sm *ServerManager) stopStackServices(stack string, services []string) map[string]string {
+	outcomes := make(map[string]string, len(services))
+	for i := len(services) - 1; i >= 0; i-- {
+		name := services[i]
+		id := StackServerID(stack, name)
+		serverInfo, err := sm.GetServer(id)
+		if err != nil {
+			continue
+		}
+		serverInfo.logMutex.RLock()
+		status := serverInfo.Status
+		serverInfo.logMutex.RUnlock()
+		if status != "running" && status != "restarting" {
+			outcomes[name] = StopAlreadyExited
+			continue
+		}
+		result, err := sm.StopServerWithOptions(id, StopOptions{})
+		if err != nil {
+			outcomes[name] = err.Error()
+			continue
+		}
+		outcomes[name] = result.Outcome
+	}
+	return outcomes
+}
+
+// StackStatus returns the status of a stack that is up.
+func (sm *ServerManager) StackStatus(name string) (*StackStatus, error) {
+	value, ok := sm.stacks.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("stack not found: %s", name)
+	}
+	return sm.stackStatus(value.(*stackState)), nil
+}
+
+// ListStacks returns the status of every stack that is up, by name.
+func (sm *ServerManager) ListStacks() []*StackStatus {
+	var stacks []*StackStatus
+	sm.stacks.Range(func(key, value interface{}) bool {
+		stacks = append(stacks, sm.stackStatus(value.(*stackState)))
+		return true
+	})
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+	return stacks
+}
+
+func (sm *ServerManager) stackStatus(state *stackState) *StackStatus {
+	status := &StackStatus{
+		Name:    state.def.Name,
+		File:    state.def.path,
+		Started: state.started,
+		Ports:   state.ports,
+	}
+	for _, name := range state.order {
+		svc := StackServiceStatus{
+			Service:   name,
+			ServerID:  StackServerID(state.def.Name, name),
+			Status:    "not started",
+			DependsOn: state.def.Services[name].DependsOn,
+		}
+		if serverInfo, err := sm.GetServer(svc.ServerID); err == nil {
+			serverInfo.logMutex.RLock()
+			svc.Status = serverInfo.Status
+			svc.PID = serverInfo.PID
+			serverInfo.logMutex.RUnlock()
+			svc.Health, _ = serverInfo.HealthState()
+		}
+		status.Services = append(status.Services, svc)
+	}
+	return status
+}
@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// writeStack writes a stack file into a new directory and returns its path
+func writeStack(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), StackFileName)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadStack(t *testing.T) {
+	def, err := LoadStack(writeStack(t, `
+name: shop
+services:
+  api:
+    command: api
+    depends_on: [db, cache]
+  worker:
+    command: worker
+    depends_on: [api]
+  db:
+    command: db
+  cache:
+    command: cache
+`))
+	if err != nil {
+		t.Fatalf("Failed to load stack: %v", err)
+	}
+	order, err := def.startOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(order, ","); got != "cache,db,api,worker" {
+		t.Errorf("Expected dependencies first, got %s", got)
+	}
+
+	path := writeStack(t, "services:\n  api:\n    command: api\n")
+	def, err = LoadStack(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != filepath.Base(filepath.Dir(path)) {
+		t.Errorf("Expected stack to be named after its directory, got %s", def.Name)
+	}
+
+	for name, content := range map[string]string{
+		"cycle":          "services:\n  a:\n    command: a\n    depends_on: [b]\n  b:\n    command: b\n    depends_on: [a]\n",
+		"unknown dep":    "services:\n  a:\n    command: a\n    depends_on: [b]\n",
+		"no command":     "services:\n  a:\n    args: [x]\n",
+		"no services":    "name: empty\n",
+		"unknown field":  "services:\n  a:\n    command: a\n    image: nginx\n",
+		"bad name":       "name: a/b\nservices:\n  a:\n    command: a\n",
+		"bad port name":  "services:\n  a:\n    command: a\n    ports: [\"a b\"]\n",
+		"bad service id": "services:\n  a.b:\n    command: a\n",
+	} {
+		if _, err := LoadStack(writeStack(t, content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestExpandStackVars(t *testing.T) {
+	vars := map[string]string{"API_HTTP_PORT": "8080"}
+	got := expandStackVars("http://127.0.0.1:${API_HTTP_PORT}/${OTHER} $HOME", vars)
+	if got != "http://127.0.0.1:8080/${OTHER} $HOME" {
+		t.Errorf("Unexpected expansion: %s", got)
+	}
+	if got := stackPortVar("mock-backend", "http"); got != "MOCK_BACKEND_HTTP_PORT" {
+		t.Errorf("Unexpected port variable: %s", got)
+	}
+}
+
+func TestStackUpDown(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	def, err := LoadStack(writeStack(t, `
+name: test
+env:
+  BACKEND: http://127.0.0.1:${BACKEND_HTTP_PORT}
+services:
+  backend:
+    command: sh
+    args: [-c, 'echo "listening on {{port:http}}"; while :; do sleep 0.1; done']
+    ports: [http]
+    health:
+      log_pattern: listening on
+      interval: 50ms
+  api:
+    command: sh
+    args: [-c, 'echo "api using $BACKEND ${BACKEND_HTTP_PORT}"; while :; do sleep 0.1; done']
+    depends_on: [backend]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	status, err := sm.StackUp(context.Background(), cfg, def)
+	if err != nil {
+		t.Fatalf("Failed to bring stack up: %v", err)
+	}
+	t.Cleanup(func() { _, _ = sm.StackDown("test") })
+
+	port := status.Ports["BACKEND_HTTP_PORT"]
+	if port == 0 {
+		t.Fatalf("Expected a port to be allocated, got %+v", status.Ports)
+	}
+	if len(status.Services) != 2 || status.Services[0].Service != "backend" || status.Services[0].Health != HealthHealthy {
+		t.Fatalf("Expected healthy backend to start first, got %+v", status.Services)
+	}
+
+	backend, err := sm.GetServer(StackServerID("test", "backend"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.Ports()["http"] != port {
+		t.Errorf("Expected the stack's port in the backend's metadata, got %v", backend.Ports())
+	}
+	if !waitForLog(backend, "listening on "+strconv.Itoa(port), time.Second) {
+		t.Errorf("Expected port placeholder to be substituted, got %v", backend.Logs.GetAll())
+	}
+
+	api, err := sm.GetServer(StackServerID("test", "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "api using http://127.0.0.1:" + strconv.Itoa(port) + " " + strconv.Itoa(port)
+	if !waitForLog(api, want, 5*time.Second) {
+		t.Errorf("Expected shared env and expanded args, got %v", api.Logs.GetAll())
+	}
+
+	if _, err := sm.StackUp(context.Background(), cfg, def); err == nil {
+		t.Error("Expected error bringing up a running stack")
+	}
+	if stacks := sm.ListStacks(); len(stacks) != 1 {
+		t.Errorf("Expected 1 stack, got %d", len(stacks))
+	}
+
+	status, err = sm.StackDown("test")
+	if err != nil {
+		t.Fatalf("Failed to bring stack down: %v", err)
+	}
+	for _, svc := range status.Services {
+		if svc.Status != "stopped" || svc.Stopped == "" {
+			t.Errorf("Expected %s to be stopped, got %+v", svc.Service, svc)
+		}
+	}
+	if !api.LastStop().Time.Before(backend.LastStop().Time) {
+		t.Error("Expected api to be stopped before the backend it depends on")
+	}
+	if _, err := sm.StackStatus("test"); err == nil {
+		t.Error("Expected stack to be gone after it was brought down")
+	}
+	sm.portMu.Lock()
+	if _, reserved := sm.ports[port]; reserved {
+		t.Error("Expected the stack's ports to be released")
+	}
+	sm.portMu.Unlock()
+}
+
+func TestStackUp_AlreadyUp(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	def, err := LoadStack(writeStack(t, `
+name: reup
+services:
+  app:
+    command: sleep
+    args: ["5"]
+    ports: [http]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	if _, err := sm.StackUp(context.Background(), cfg, def); err != nil {
+		t.Fatalf("Failed to bring stack up: %v", err)
+	}
+	t.Cleanup(func() { _, _ = sm.StackDown("reup") })
+
+	// Still up after its only service was stopped
+	if err := sm.StopServer(StackServerID("reup", "app"), true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.StackUp(context.Background(), cfg, def); err == nil || !strings.Contains(err.Error(), "already up") {
+		t.Fatalf("Expected already up error, got %v", err)
+	}
+	sm.portMu.Lock()
+	reserved := len(sm.ports)
+	sm.portMu.Unlock()
+	if reserved != 1 {
+		t.Errorf("Expected only the port of the first bring-up to be reserved, got %d", reserved)
+	}
+
+	if _, err := sm.StackDown("reup"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.StackUp(context.Background(), cfg, def); err != nil {
+		t.Fatalf("Failed to bring stack up again: %v", err)
+	}
+}
+
+func TestStackUp_FailureStopsStartedServices(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	def, err := LoadStack(writeStack(t, `
+name: failing
+services:
+  backend:
+    command: sh
+    args: [-c, 'while :; do sleep 0.1; done']
+  api:
+    command: sh
+    args: [-c, 'exit 1']
+    depends_on: [backend]
+    health:
+      log_pattern: ready
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir()}
+	if _, err := sm.StackUp(context.Background(), cfg, def); err == nil || !strings.Contains(err.Error(), "api") {
+		t.Fatalf("Expected api to fail, got %v", err)
+	}
+	backend, err := sm.GetServer(StackServerID("failing", "backend"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.IsRunning() {
+		t.Error("Expected backend to be stopped after api failed")
+	}
+	if _, err := sm.StackStatus("failing"); err == nil {
+		t.Error("Expected failed stack not to be registered")
+	}
+}
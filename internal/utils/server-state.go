@@ -0,0 +1,399 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// How servers still running from an earlier run of the MCP server are handled
+const (
+	OrphanReattach = "reattach" // manage them again
+	OrphanStop     = "stop"     // stop them
+	OrphanIgnore   = "ignore"   // leave them running and forget them
+)
+
+const (
+	serverStateVersion = 1
+	// attachedPollInterval is how often a reattached process is checked,
+	// as it cannot be waited for
+	attachedPollInterval = 500 * time.Millisecond
+)
+
+var (
+	errReattachUnsupported = errors.New("verifying processes is only supported on Linux")
+	errProcessMismatch     = errors.New("process ID is used by another command")
+)
+
+// serverStateFile is the layout of the state file
+type serverStateFile struct {
+	Version int            `json:"version"`
+	Servers []serverRecord `json:"servers"`
+}
+
+// serverRecord is the persisted registry entry of a server: the options
+// needed to launch it again and the process it was last running.
+type serverRecord struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Command       string            `json:"command"`
+	Args          []string          `json:"args,omitempty"`
+	WorkingDir    string            `json:"working_dir,omitempty"`
+	EnvVars       map[string]string `json:"env_vars,omitempty"`
+	LogSize       int               `json:"log_size"`
+	HealthCheck   *HealthCheck      `json:"health_check,omitempty"`
+	RestartPolicy string            `json:"restart_policy,omitempty"`
+	MaxRestarts   int               `json:"max_restarts,omitempty"`
+	Watch         *WatchOptions     `json:"watch,omitempty"`
+	Build         *BuildOptions     `json:"build,omitempty"`
+	StopTimeout   time.Duration     `json:"stop_timeout,omitempty"`
+	Ports         map[string]int    `json:"ports,omitempty"`
+
+	Status      string    `json:"status"`
+	PID         int       `json:"pid,omitempty"`
+	CommandLine []string  `json:"command_line,omitempty"` // argv of the process, to detect PID reuse
+	StartTime   time.Time `json:"start_time"`
+	ExitCode    *int      `json:"exit_code,omitempty"`
+	Restarts    int       `json:"restarts,omitempty"`
+}
+
+// RestoreReport lists what happened to the servers in the state file when
+// the registry was restored.
+type RestoreReport struct {
+	Reattached []string `json:"reattached,omitempty"` // still running and managed again
+	Stopped    []string `json:"stopped,omitempty"`    // still running and stopped by the orphan policy
+	Ignored    []string `json:"ignored,omitempty"`    // still running and left alone by the orphan policy
+	Exited     []string `json:"exited,omitempty"`     // exited while the MCP server was not running
+	Unverified []string `json:"unverified,omitempty"` // may still be running, but could not be verified
+	Restored   []string `json:"restored,omitempty"`   // were not running
+}
+
+// record returns the persisted form of the server
+func (s *ServerInfo) record() serverRecord {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	rec := serverRecord{
+		ID:            s.ID,
+		Name:          s.Name,
+		Command:       s.opts.Command,
+		Args:          s.opts.Args,
+		WorkingDir:    s.opts.WorkingDir,
+		EnvVars:       s.opts.EnvVars,
+		LogSize:       s.opts.LogSize,
+		HealthCheck:   s.opts.HealthCheck,
+		RestartPolicy: s.opts.RestartPolicy,
+		MaxRestarts:   s.opts.MaxRestarts,
+		Watch:         s.opts.Watch,
+		Build:         s.opts.Build,
+		StopTimeout:   s.opts.StopTimeout,
+		Status:        s.Status,
+		PID:           s.PID,
+		CommandLine:   s.argv,
+		StartTime:     s.StartTime,
+		ExitCode:      s.ExitCode,
+		Restarts:      s.Restarts,
+	}
+	if ports, ok := s.Metadata["ports"].(map[string]int); ok {
+		rec.Ports = ports
+	}
+	return rec
+}
+
+// saveState writes the registry to the state file, if one is configured.
+// The file holds environment variables of the servers, so it is only
+// readable by the owner.
+func (sm *ServerManager) saveState() {
+	if sm.stateFile == "" {
+		return
+	}
+	sm.stateMu.Lock()
+	defer sm.stateMu.Unlock()
+
+	state := serverStateFile{Version: serverStateVersion, Servers: []serverRecord{}}
+	for _, serverInfo := range sm.ListServers() {
+		state.Servers = append(state.Servers, serverInfo.record())
+	}
+	sort.Slice(state.Servers, func(i, j int) bool {
+		return state.Servers[i].ID < state.Servers[j].ID
+	})
+	if err := writeStateFile(sm.stateFile, &state); err != nil {
+		log.Printf("Failed to save server state to %s: %v", sm.stateFile, err)
+	}
+}
+
+// writeStateFile replaces the state file atomically
+func writeStateFile(path string, state *serverStateFile) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// RestoreServers enables persisting the registry to cfg.ServerStateFile and
+// restores the servers saved by an earlier run of the MCP server. Servers
+// whose process is still running, as verified by its command line, are
+// handled according to cfg.ServerOrphanPolicy; the others are restored as
+// stopped so they can be inspected, restarted or removed.
+func (sm *ServerManager) RestoreServers(cfg *config.Config) (*RestoreReport, error) {
+	sm.stateFile = cfg.ServerStateFile
+	report := &RestoreReport{}
+	if sm.stateFile == "" {
+		return report, nil
+	}
+
+	data, err := os.ReadFile(sm.stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state serverStateFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid server state file %s: %w", sm.stateFile, err)
+	}
+	if state.Version != serverStateVersion {
+		return nil, fmt.Errorf("unsupported server state file version %d", state.Version)
+	}
+
+	var stop []string
+	for _, rec := range state.Servers {
+		if _, err := sm.GetServer(rec.ID); err == nil {
+			continue
+		}
+		running := rec.Status == "running" && rec.PID > 0
+		var verifyErr error
+		if running {
+			verifyErr = verifyProcess(rec.PID, rec.CommandLine)
+		}
+
+		switch {
+		case !running:
+			report.Restored = append(report.Restored, rec.ID)
+		case errors.Is(verifyErr, errReattachUnsupported):
+			report.Unverified = append(report.Unverified, rec.ID)
+		case verifyErr != nil:
+			report.Exited = append(report.Exited, rec.ID)
+		case cfg.ServerOrphanPolicy == OrphanIgnore:
+			report.Ignored = append(report.Ignored, rec.ID)
+			continue
+		case cfg.ServerOrphanPolicy == OrphanStop:
+			stop = append(stop, rec.ID)
+		default:
+			report.Reattached = append(report.Reattached, rec.ID)
+		}
+
+		serverInfo, err := sm.restoreServer(cfg, rec, running && verifyErr == nil)
+		if err != nil {
+			log.Printf("Failed to restore server %s: %v", rec.ID, err)
+			continue
+		}
+		if running && verifyErr != nil {
+			serverInfo.addLog(StreamSystem, fmt.Sprintf("[state] process %d is no longer running: %v", rec.PID, verifyErr))
+		}
+	}
+
+	// Orphans are reattached first so that stopping them is recorded as usual
+	for _, id := range stop {
+		if _, err := sm.StopServerWithOptions(id, StopOptions{}); err != nil {
+			log.Printf("Failed to stop orphaned server %s: %v", id, err)
+			continue
+		}
+		report.Stopped = append(report.Stopped, id)
+	}
+
+	sm.saveState()
+	return report, nil
+}
+
+// restoreServer adds a server from the state file to the registry. If
+// attach is set, its process is still running and is monitored again;
+// otherwise the server is restored as stopped.
+func (sm *ServerManager) restoreServer(cfg *config.Config, rec serverRecord, attach bool) (*ServerInfo, error) {
+	opts := ServerOptions{
+		ID:              rec.ID,
+		Name:            rec.Name,
+		Command:         rec.Command,
+		Args:            rec.Args,
+		WorkingDir:      rec.WorkingDir,
+		EnvVars:         rec.EnvVars,
+		LogSize:         rec.LogSize,
+		HealthCheck:     rec.HealthCheck,
+		RestartPolicy:   rec.RestartPolicy,
+		MaxRestarts:     rec.MaxRestarts,
+		Watch:           rec.Watch,
+		Build:           rec.Build,
+		StopTimeout:     rec.StopTimeout,
+		MetricsInterval: defaultMetricsInterval,
+		AssignedPorts:   rec.Ports,
+	}
+	if opts.LogSize <= 0 {
+		opts.LogSize = 1000
+	}
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = defaultStopTimeout
+	}
+	if opts.HealthCheck != nil {
+		if err := opts.HealthCheck.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := opts.validateRestartPolicy(); err != nil {
+		return nil, err
+	}
+
+	var logFile *rotatingFile
+	var lastSeq int64
+	if cfg.ServerLogDir != "" {
+		var err error
+		logFile, lastSeq, err = openServerLog(cfg, opts.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	serverInfo := &ServerInfo{
+		ID:            opts.ID,
+		Name:          opts.Name,
+		Command:       opts.Command,
+		Args:          opts.Args,
+		WorkingDir:    opts.WorkingDir,
+		StartTime:     rec.StartTime,
+		Logs:          NewRingBuffer(opts.LogSize),
+		StdoutLogs:    NewRingBuffer(opts.LogSize),
+		StderrLogs:    NewRingBuffer(opts.LogSize),
+		Lines:         NewLogBuffer(opts.LogSize),
+		ExitCode:      rec.ExitCode,
+		Status:        rec.Status,
+		onLog:         &sm.logObserver,
+		logFile:       logFile,
+		Metadata:      make(map[string]interface{}),
+		HealthCheck:   opts.HealthCheck,
+		RestartPolicy: opts.RestartPolicy,
+		MaxRestarts:   opts.MaxRestarts,
+		Restarts:      rec.Restarts,
+		opts:          opts,
+		cfg:           cfg,
+		ctx:           ctx,
+		cancel:        cancel,
+		done:          done,
+	}
+	serverInfo.Lines.seq = lastSeq
+	if len(rec.Ports) > 0 {
+		serverInfo.Metadata["ports"] = rec.Ports
+		sm.holdPorts(rec.Ports, serverInfo)
+	}
+
+	if !attach {
+		if serverInfo.Status == "running" || serverInfo.Status == "restarting" {
+			serverInfo.Status = "stopped"
+			serverInfo.recordExit(ExitRecord{Time: time.Now(), Error: "exited while not managed"})
+		}
+		cancel()
+		close(done)
+		sm.servers.Store(opts.ID, serverInfo)
+		return serverInfo, nil
+	}
+
+	serverInfo.PID = rec.PID
+	serverInfo.argv = rec.CommandLine
+	if serverInfo.HealthCheck != nil {
+		serverInfo.health = HealthStarting
+	}
+	sm.servers.Store(opts.ID, serverInfo)
+	// Output written to files can be read again; output written to pipes of
+	// the earlier MCP server is lost
+	output := attachServerOutput(serverInfo, cfg)
+	if output != nil {
+		serverInfo.addLog(StreamSystem, fmt.Sprintf("[state] reattached to process %d", rec.PID))
+	} else {
+		serverInfo.addLog(StreamSystem, fmt.Sprintf("[state] reattached to process %d; its output is no longer captured", rec.PID))
+	}
+
+	go sm.monitorAttached(ctx, serverInfo, output, done)
+	if serverInfo.HealthCheck != nil {
+		go sm.monitorHealth(ctx, serverInfo, serverInfo.HealthCheck)
+	}
+	go sm.monitorMetrics(ctx, serverInfo)
+	if opts.Watch != nil {
+		go sm.watchServer(ctx, serverInfo)
+	}
+	return serverInfo, nil
+}
+
+// errExitUnknown is the exit of a reattached process, whose status cannot be read
+var errExitUnknown = errors.New("exit status unknown")
+
+// monitorAttached polls a reattached process, which is not a child of the
+// MCP server and cannot be waited for, and records when it exits. Its exit
+// code is unknown. Its output, if any, is read until then. Like a process
+// the MCP server started, it is restarted according to its restart policy;
+// as the exit code is unknown, on-failure treats every exit as a failure.
+func (sm *ServerManager) monitorAttached(ctx context.Context, serverInfo *ServerInfo, output *serverOutput, done chan struct{}) {
+	ticker := time.NewTicker(attachedPollInterval)
+	defer ticker.Stop()
+	if output != nil {
+		defer output.finish()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if processRunning(serverInfo.PID) {
+			continue
+		}
+		if output != nil {
+			output.finish()
+		}
+
+		serverInfo.logMutex.Lock()
+		serverInfo.Status = "stopped"
+		serverInfo.recordExit(ExitRecord{
+			Time:   time.Now(),
+			Uptime: time.Since(serverInfo.StartTime).Round(time.Millisecond).String(),
+			Error:  errExitUnknown.Error(),
+		})
+		delay, restart := serverInfo.nextRestart(errExitUnknown)
+		if restart {
+			serverInfo.Status = "restarting"
+		}
+		serverInfo.logMutex.Unlock()
+		sm.saveState()
+		close(done)
+
+		if restart {
+			sm.relaunch(ctx, serverInfo, delay)
+		}
+		return
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// verifyProcess checks that the process is still running the recorded
+// command line, so that a process ID reused by another program is not
+// mistaken for the server.
+func verifyProcess(pid int, commandLine []string) error {
+	if !processRunning(pid) {
+		return errProcessGone
+	}
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return errProcessGone
+	}
+	args := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	if len(commandLine) == 0 || strings.Join(args, "\x00") != strings.Join(commandLine, "\x00") {
+		return errProcessMismatch
+	}
+	return nil
+}
+
+// processRunning reports whether the process exists and is not a zombie
+func processRunning(pid int) bool {
+	stat, err := readProcStat(pid)
+	return err == nil && stat.state != 'Z'
+}
@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// startOrphan starts a process in its own process group, as a managed
+// server left running by an earlier MCP server, and returns its record
+func startOrphan(t *testing.T, id string) (serverRecord, <-chan struct{}) {
+	t.Helper()
+	cmd := exec.Command("sleep", "30")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() { _ = killProcessGroup(cmd.Process.Pid) })
+	return serverRecord{
+		ID:          id,
+		Command:     "sleep",
+		Args:        []string{"30"},
+		Status:      "running",
+		PID:         cmd.Process.Pid,
+		CommandLine: cmd.Args,
+		StartTime:   time.Now(),
+	}, exited
+}
+
+func restoreWithPolicy(t *testing.T, policy string, records ...serverRecord) (*ServerManager, *RestoreReport, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "servers.json")
+	if err := writeStateFile(path, &serverStateFile{Version: serverStateVersion, Servers: records}); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir(), ServerStateFile: path, ServerOrphanPolicy: policy}
+	report, err := sm.RestoreServers(cfg)
+	if err != nil {
+		t.Fatalf("Failed to restore servers: %v", err)
+	}
+	return sm, report, path
+}
+
+func TestRestoreServers_Reattach(t *testing.T) {
+	rec, exited := startOrphan(t, "orphan")
+	reused, _ := startOrphan(t, "reused")
+	reused.CommandLine = []string{"other", "command"}
+
+	sm, report, path := restoreWithPolicy(t, OrphanReattach, rec, reused)
+	if len(report.Reattached) != 1 || report.Reattached[0] != "orphan" {
+		t.Errorf("Expected orphan to be reattached, got %+v", report)
+	}
+	if len(report.Exited) != 1 || report.Exited[0] != "reused" {
+		t.Errorf("Expected a reused process ID not to be reattached, got %+v", report)
+	}
+
+	serverInfo, err := sm.GetServer("orphan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !serverInfo.IsRunning() || serverInfo.PID != rec.PID {
+		t.Fatalf("Expected reattached server to be running as %d", rec.PID)
+	}
+	if saved := readStateFile(t, path)["orphan"]; saved.Status != "running" || saved.PID != rec.PID {
+		t.Errorf("Expected the reattached server to be saved, got %+v", saved)
+	}
+
+	result, err := sm.StopServerWithOptions("orphan", StopOptions{GracePeriod: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to stop reattached server: %v", err)
+	}
+	if result.Outcome != StopGraceful {
+		t.Errorf("Expected graceful stop, got %s", result.Outcome)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the orphaned process to exit")
+	}
+}
+
+func TestRestoreServers_ReattachedExit(t *testing.T) {
+	rec, _ := startOrphan(t, "orphan")
+	sm, _, _ := restoreWithPolicy(t, OrphanReattach, rec)
+	serverInfo, err := sm.GetServer("orphan")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := killProcessGroup(rec.PID); err != nil {
+		t.Fatal(err)
+	}
+	if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+		t.Error("Expected the exit of a reattached process to be noticed")
+	}
+}
+
+func TestRestoreServers_ReattachedRestart(t *testing.T) {
+	rec, _ := startOrphan(t, "orphan")
+	rec.RestartPolicy = RestartOnFailure
+	sm, _, _ := restoreWithPolicy(t, OrphanReattach, rec)
+	serverInfo, err := sm.GetServer("orphan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sm.StopServer("orphan", true) })
+
+	if err := killProcessGroup(rec.PID); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if state := serverInfo.ProcessState(); state.Status == "running" && state.PID != rec.PID {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	state := serverInfo.ProcessState()
+	if state.Status != "running" || state.PID == rec.PID {
+		t.Fatalf("Expected the reattached server to be restarted by its policy, got %+v", state)
+	}
+	if restarts, history := serverInfo.RestartState(); restarts != 1 || len(history) != 1 || history[0].Error != "exit status unknown" {
+		t.Errorf("Expected 1 restart after an unknown exit, got %d and %+v", restarts, history)
+	}
+}
+
+func TestRestoreServers_OrphanPolicies(t *testing.T) {
+	stopped, exited := startOrphan(t, "stopped")
+	sm, report, _ := restoreWithPolicy(t, OrphanStop, stopped)
+	if len(report.Stopped) != 1 {
+		t.Errorf("Expected the orphan to be stopped, got %+v", report)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the orphaned process to exit")
+	}
+	if serverInfo, err := sm.GetServer("stopped"); err != nil || serverInfo.IsRunning() {
+		t.Errorf("Expected the stopped orphan to stay in the registry: %v", err)
+	}
+
+	ignored, _ := startOrphan(t, "ignored")
+	sm, report, path := restoreWithPolicy(t, OrphanIgnore, ignored)
+	if len(report.Ignored) != 1 {
+		t.Errorf("Expected the orphan to be ignored, got %+v", report)
+	}
+	if _, err := sm.GetServer("ignored"); err == nil {
+		t.Error("Expected the ignored orphan not to be managed")
+	}
+	if _, ok := readStateFile(t, path)["ignored"]; ok {
+		t.Error("Expected the ignored orphan to be dropped from the state file")
+	}
+	if !processRunning(ignored.PID) {
+		t.Error("Expected the ignored orphan to keep running")
+	}
+}
+
+// TestHelperServerParent is run as a separate process by
+// TestRestoreServers_ParentKilled: it starts a server that keeps writing
+// output and waits to be killed.
+func TestHelperServerParent(t *testing.T) {
+	if os.Getenv("MCP_TEST_SERVER_PARENT") != "1" {
+		t.Skip("helper process")
+	}
+	cfg := &config.Config{
+		DisableNotifications: true,
+		WorkingDirectory:     os.Getenv("MCP_TEST_DIR"),
+		ServerStateFile:      filepath.Join(os.Getenv("MCP_TEST_DIR"), "servers.json"),
+		ServerLogDir:         filepath.Join(os.Getenv("MCP_TEST_DIR"), "logs"),
+	}
+	sm := NewServerManager()
+	if _, err := sm.RestoreServers(cfg); err != nil {
+		t.Fatal(err)
+	}
+	_, err := sm.StartServerWithOptions(context.Background(), cfg, ServerOptions{
+		ID:      "writer",
+		Command: "sh",
+		Args:    []string{"-c", `while :; do echo tick; echo tock >&2; sleep 0.02; done`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Minute)
+}
+
+func TestRestoreServers_ParentKilled(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	parent := exec.Command(os.Args[0], "-test.run=^TestHelperServerParent$")
+	parent.Env = append(os.Environ(), "MCP_TEST_SERVER_PARENT=1", "MCP_TEST_DIR="+dir)
+	if err := parent.Start(); err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, "servers.json")
+	var rec serverRecord
+	deadline := time.Now().Add(10 * time.Second)
+	for rec.PID == 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+		if _, err := os.Stat(statePath); err == nil {
+			rec = readStateFile(t, statePath)["writer"]
+		}
+	}
+	if rec.PID == 0 {
+		parent.Process.Kill()
+		parent.Wait()
+		t.Fatal("Expected the helper to start the server")
+	}
+	t.Cleanup(func() { _ = killProcessGroup(rec.PID) })
+
+	// The server keeps writing after the MCP server is gone
+	time.Sleep(200 * time.Millisecond)
+	if err := parent.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	parent.Wait()
+	time.Sleep(500 * time.Millisecond)
+	if !processRunning(rec.PID) {
+		t.Fatal("Expected the server to survive its parent")
+	}
+
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: dir, ServerStateFile: statePath, ServerLogDir: filepath.Join(dir, "logs")}
+	report, err := sm.RestoreServers(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Reattached) != 1 {
+		t.Fatalf("Expected the server to be reattached, got %+v", report)
+	}
+	serverInfo, err := sm.GetServer("writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !waitForLog(serverInfo, "tick", 5*time.Second) || !waitForLog(serverInfo, "tock", time.Second) {
+		t.Fatalf("Expected output of the reattached server to be captured, got %v", serverInfo.Logs.GetAll())
+	}
+
+	if _, err := sm.StopServerWithOptions("writer", StopOptions{GracePeriod: 5 * time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(serverOutputPath(cfg.ServerLogDir, "writer", StreamStdout)); !os.IsNotExist(err) {
+		t.Errorf("Expected the output file to be removed once the server stopped: %v", err)
+	}
+}
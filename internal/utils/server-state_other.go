@@ -0,0 +1,14 @@
+//go:build !linux
+
+package utils
+
+// verifyProcess cannot inspect the command line of a process without /proc,
+// so servers are never reattached on this platform.
+func verifyProcess(pid int, commandLine []string) error {
+	return errReattachUnsupported
+}
+
+// processRunning is only used for reattached servers, which require Linux
+func processRunning(pid int) bool {
+	return false
+}
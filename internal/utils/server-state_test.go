@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/inja-online/golang-mcp/internal/config"
+)
+
+// readStateFile reads the records of the state file by server ID
+func readStateFile(t *testing.T, path string) map[string]serverRecord {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read state file: %v", err)
+	}
+	var state serverStateFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("Invalid state file: %v", err)
+	}
+	records := make(map[string]serverRecord)
+	for _, rec := range state.Servers {
+		records[rec.ID] = rec
+	}
+	return records
+}
+
+func TestServerState_Persisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "servers.json")
+	sm := NewServerManager()
+	if _, err := sm.RestoreServers(&config.Config{ServerStateFile: path}); err != nil {
+		t.Fatalf("Failed to restore from missing state file: %v", err)
+	}
+
+	startRestartServer(t, sm, ServerOptions{
+		ID:      "persisted",
+		Command: "sleep",
+		Args:    []string{"30"},
+		EnvVars: map[string]string{"MODE": "dev"},
+	})
+	rec, ok := readStateFile(t, path)["persisted"]
+	if !ok {
+		t.Fatal("Expected the server in the state file")
+	}
+	if rec.Status != "running" || rec.PID == 0 || len(rec.CommandLine) != 2 || rec.EnvVars["MODE"] != "dev" {
+		t.Errorf("Unexpected record: %+v", rec)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the state file to be private: %v", err)
+	}
+
+	if err := sm.RemoveServer("persisted"); err == nil {
+		t.Error("Expected error removing a running server")
+	}
+	if err := sm.StopServer("persisted", true); err != nil {
+		t.Fatal(err)
+	}
+	if rec := readStateFile(t, path)["persisted"]; rec.Status != "stopped" {
+		t.Errorf("Expected stopped status to be saved, got %q", rec.Status)
+	}
+
+	if err := sm.RemoveServer("persisted"); err != nil {
+		t.Fatalf("Failed to remove server: %v", err)
+	}
+	if _, err := sm.GetServer("persisted"); err == nil {
+		t.Error("Expected server to be removed")
+	}
+	if records := readStateFile(t, path); len(records) != 0 {
+		t.Errorf("Expected no servers in the state file, got %v", records)
+	}
+}
+
+func TestRestoreServers_Stopped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.json")
+	exitCode := 2
+	state := serverStateFile{Version: serverStateVersion, Servers: []serverRecord{
+		{ID: "old", Command: "go", Args: []string{"run", "."}, Status: "stopped", ExitCode: &exitCode, Ports: map[string]int{"http": 45678}},
+		{ID: "gone", Command: "go", Status: "running", PID: 999999999, CommandLine: []string{"go"}},
+	}}
+	if err := writeStateFile(path, &state); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewServerManager()
+	report, err := sm.RestoreServers(&config.Config{ServerStateFile: path, ServerOrphanPolicy: OrphanReattach})
+	if err != nil {
+		t.Fatalf("Failed to restore servers: %v", err)
+	}
+	if len(report.Restored) != 1 || report.Restored[0] != "old" {
+		t.Errorf("Expected old to be restored, got %+v", report)
+	}
+	if len(report.Exited)+len(report.Unverified) != 1 {
+		t.Errorf("Expected gone to be reported, got %+v", report)
+	}
+
+	old, err := sm.GetServer("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.IsRunning() || old.ExitCode == nil || *old.ExitCode != 2 || old.Ports()["http"] != 45678 {
+		t.Errorf("Unexpected restored server: status %s, ports %v", old.Status, old.Ports())
+	}
+	gone, err := sm.GetServer("gone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gone.IsRunning() {
+		t.Error("Expected a server whose process is gone to be restored as stopped")
+	}
+	if err := sm.RemoveServer("gone"); err != nil {
+		t.Errorf("Failed to remove restored server: %v", err)
+	}
+
+	state.Version = 99
+	if err := writeStateFile(path, &state); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewServerManager().RestoreServers(&config.Config{ServerStateFile: path}); err == nil {
+		t.Error("Expected error for unsupported state file version")
+	}
+}
+
+func TestRestoreServers_Restart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.json")
+	state := serverStateFile{Version: serverStateVersion, Servers: []serverRecord{
+		{ID: "again", Command: "sh", Args: []string{"-c", "echo started $MODE; sleep 30"}, EnvVars: map[string]string{"MODE": "dev"}, Status: "stopped"},
+	}}
+	if err := writeStateFile(path, &state); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewServerManager()
+	cfg := &config.Config{DisableNotifications: true, WorkingDirectory: t.TempDir(), ServerStateFile: path}
+	if _, err := sm.RestoreServers(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	serverInfo, err := sm.RestartServer(context.Background(), "again")
+	if err != nil {
+		t.Fatalf("Failed to restart restored server: %v", err)
+	}
+	t.Cleanup(func() { _ = sm.StopServer("again", true) })
+	if !waitForLog(serverInfo, "started dev", 5*time.Second) {
+		t.Error("Expected the server to run with its saved command and environment")
+	}
+}
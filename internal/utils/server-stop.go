@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// How a managed server terminated when it was stopped
+const (
+	StopGraceful      = "graceful"       // exited within the grace period after SIGTERM
+	StopKilled        = "killed"         // killed after the grace period, or on request
+	StopAlreadyExited = "already_exited" // had exited before it was signalled
+)
+
+const (
+	defaultStopTimeout = 10 * time.Second
+	killTimeout        = 5 * time.Second
+)
+
+var (
+	errProcessGone             = errors.New("process already exited")
+	errGracefulStopUnsupported = errors.New("graceful stop is not supported on this platform")
+)
+
+// StopOptions configures how a server is stopped.
+type StopOptions struct {
+	Force       bool          // kill immediately instead of sending SIGTERM first
+	GracePeriod time.Duration // time to wait before killing, default the server's StopTimeout
+}
+
+// StopResult describes how a server terminated.
+type StopResult struct {
+	Outcome  string    `json:"outcome"` // StopGraceful, StopKilled or StopAlreadyExited
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+}
+
+// LastStop returns how the server terminated when it was last stopped, or nil.
+func (s *ServerInfo) LastStop() *StopResult {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	if s.lastStop == nil {
+		return nil
+	}
+	result := *s.lastStop
+	return &result
+}
+
+// StopServer stops a running server by ID, waiting up to its grace period
+// for it to shut down before killing it.
+func (sm *ServerManager) StopServer(id string, force bool) error {
+	_, err := sm.StopServerWithOptions(id, StopOptions{Force: force})
+	return err
+}
+
+// StopServerWithOptions stops a running server and its process group. Unless
+// forced, the group is sent SIGTERM and killed if any process is still
+// running after the grace period. It returns once the server has exited.
+func (sm *ServerManager) StopServerWithOptions(id string, opts StopOptions) (*StopResult, error) {
+	serverInfo, err := sm.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverInfo.logMutex.Lock()
+	status := serverInfo.Status
+	if status == "running" || status == "restarting" {
+		serverInfo.stopping = true
+	}
+	pid := serverInfo.PID
+	done := serverInfo.done
+	serverInfo.logMutex.Unlock()
+
+	start := time.Now()
+	result := &StopResult{Time: start}
+
+	// A server waiting to be restarted has no process to signal
+	if status == "restarting" {
+		serverInfo.cancel()
+		result.Outcome = StopAlreadyExited
+		return sm.finishStop(serverInfo, result, start), nil
+	}
+	if status != "running" {
+		return nil, fmt.Errorf("server is not running: %s", id)
+	}
+
+	grace := opts.GracePeriod
+	if grace <= 0 {
+		grace = serverInfo.opts.StopTimeout
+	}
+
+	select {
+	case <-done:
+		result.Outcome = StopAlreadyExited
+	default:
+	}
+
+	if result.Outcome == "" && !opts.Force {
+		err := terminateProcessGroup(pid)
+		switch {
+		case errors.Is(err, errProcessGone):
+			result.Outcome = StopAlreadyExited
+		case err == nil:
+			if waitProcessGroup(pid, done, grace) {
+				result.Outcome = StopGraceful
+			}
+		}
+		// Any other error means SIGTERM could not be sent, so kill instead
+	}
+
+	if result.Outcome == "" {
+		if err := killProcessGroup(pid); err != nil && !errors.Is(err, errProcessGone) {
+			return nil, fmt.Errorf("failed to kill server: %w", err)
+		}
+		result.Outcome = StopKilled
+	}
+
+	select {
+	case <-done:
+	case <-time.After(killTimeout):
+		return nil, fmt.Errorf("server %s did not exit", id)
+	}
+
+	// Stop health monitoring and watching now the process is gone
+	serverInfo.cancel()
+	return sm.finishStop(serverInfo, result, start), nil
+}
+
+// finishStop records the result of stopping the server
+func (sm *ServerManager) finishStop(serverInfo *ServerInfo, result *StopResult, start time.Time) *StopResult {
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+
+	serverInfo.logMutex.Lock()
+	result.ExitCode = serverInfo.ExitCode
+	serverInfo.lastStop = result
+	serverInfo.logMutex.Unlock()
+
+	serverInfo.addLog(StreamSystem, fmt.Sprintf("[stop] server stopped (%s) after %s", result.Outcome, result.Duration))
+	stopped := *result
+	return &stopped
+}
+
+// waitProcessGroup waits until the server process has exited and no process
+// is left in its group, and reports whether that happened within timeout.
+func waitProcessGroup(pid int, done <-chan struct{}, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	select {
+	case <-done:
+	case <-deadline.C:
+		return false
+	}
+
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for processGroupAlive(pid) {
+		select {
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+//go:build unix
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopServer_Graceful(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "stop-graceful",
+		Command: "sh",
+		Args:    []string{"-c", `trap "exit 0" TERM; echo ready; while :; do sleep 0.1; done`},
+	})
+	if !waitForLog(serverInfo, "ready", 5*time.Second) {
+		t.Fatal("Expected server to start")
+	}
+
+	result, err := sm.StopServerWithOptions("stop-graceful", StopOptions{GracePeriod: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+	if result.Outcome != StopGraceful {
+		t.Errorf("Expected graceful stop, got %s", result.Outcome)
+	}
+	if result.ExitCode == nil || *result.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %v", result.ExitCode)
+	}
+	if last := serverInfo.LastStop(); last == nil || last.Outcome != StopGraceful {
+		t.Errorf("Expected last stop to be recorded, got %+v", last)
+	}
+	if !waitForLog(serverInfo, "[stop] server stopped (graceful)", time.Second) {
+		t.Error("Expected stop to be logged")
+	}
+}
+
+func TestStopServer_KillsAfterGracePeriod(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "stop-killed",
+		Command: "sh",
+		Args:    []string{"-c", `trap "" TERM; echo ready; while :; do sleep 0.1; done`},
+	})
+	if !waitForLog(serverInfo, "ready", 5*time.Second) {
+		t.Fatal("Expected server to start")
+	}
+
+	start := time.Now()
+	result, err := sm.StopServerWithOptions("stop-killed", StopOptions{GracePeriod: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+	if result.Outcome != StopKilled {
+		t.Errorf("Expected server to be killed, got %s", result.Outcome)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected stop to wait for the grace period, took %s", elapsed)
+	}
+	if serverInfo.IsRunning() {
+		t.Error("Expected server not to be running")
+	}
+}
+
+func TestStopServer_KillsProcessGroup(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "stop-group",
+		Command: "sh",
+		Args:    []string{"-c", `sleep 30 & echo ready; wait`},
+	})
+	if !waitForLog(serverInfo, "ready", 5*time.Second) {
+		t.Fatal("Expected server to start")
+	}
+	pid := serverInfo.PID
+
+	if _, err := sm.StopServerWithOptions("stop-group", StopOptions{GracePeriod: 2 * time.Second}); err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+	if processGroupAlive(pid) {
+		t.Error("Expected the child process to be stopped with the server")
+	}
+}
+
+func TestStopServer_AlreadyExited(t *testing.T) {
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "stop-exited",
+		Command: "sh",
+		Args:    []string{"-c", "exit 0"},
+	})
+	if !waitForStatus(serverInfo, "stopped", 5*time.Second) {
+		t.Fatal("Expected server to exit")
+	}
+
+	if _, err := sm.StopServerWithOptions("stop-exited", StopOptions{}); err == nil {
+		t.Error("Expected error stopping a server that is not running")
+	}
+	if _, err := sm.StopServerWithOptions("unknown", StopOptions{}); err == nil {
+		t.Error("Expected error for unknown server")
+	}
+}
@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWatchDebounce     = 500 * time.Millisecond
+	defaultWatchPollInterval = time.Second
+)
+
+// defaultWatchIgnore is always ignored in addition to the configured globs
+var defaultWatchIgnore = []string{".*", "*~", "*.swp", "*.tmp", "node_modules", "vendor"}
+
+// WatchOptions configures rebuild-and-restart of a managed server when files
+// in its source tree change.
+type WatchOptions struct {
+	Dir          string        `json:"dir,omitempty"`           // directory tree to watch, default the working directory
+	Package      string        `json:"package,omitempty"`       // package to build before restarting, default "."
+	Ignore       []string      `json:"ignore,omitempty"`        // globs matched against names and relative paths
+	Debounce     time.Duration `json:"debounce,omitempty"`      // quiet period before rebuilding
+	PollInterval time.Duration `json:"poll_interval,omitempty"` // scan interval when inotify is unavailable
+
+	managed []string // directories in Dir the manager writes to, relative and slash-separated
+}
+
+// BuildStatus describes the most recent build of a managed server.
+type BuildStatus struct {
+	Time     time.Time `json:"time"`
+	Success  bool      `json:"success"`
+	Duration string    `json:"duration"`
+	Output   string    `json:"output,omitempty"`
+	Command  string    `json:"command,omitempty"` // go build command of a server built from a package
+	Binary   string    `json:"binary,omitempty"`  // binary built from a package
+}
+
+// validate checks the watch options and fills in defaults. managedDirs are
+// the directories the manager writes to, such as the server log directory,
+// which are ignored when they are inside the watched tree so that writing to
+// them does not trigger a rebuild.
+func (wo *WatchOptions) validate(workingDir string, managedDirs ...string) error {
+	if wo.Dir == "" {
+		wo.Dir = workingDir
+	}
+	info, err := os.Stat(wo.Dir)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("watch directory %s is not a directory", wo.Dir)
+	}
+	wo.managed = nil
+	for _, dir := range managedDirs {
+		if dir == "" {
+			continue
+		}
+		if rel, ok := relativeTo(wo.Dir, dir); ok {
+			wo.managed = append(wo.managed, rel)
+		}
+	}
+	for _, pattern := range wo.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ignore glob %q: %w", pattern, err)
+		}
+	}
+	if wo.Package == "" {
+		wo.Package = "."
+	}
+	if wo.Debounce <= 0 {
+		wo.Debounce = defaultWatchDebounce
+	}
+	if wo.PollInterval <= 0 {
+		wo.PollInterval = defaultWatchPollInterval
+	}
+	return nil
+}
+
+// ignored reports whether the file or directory at rel (relative to the
+// watched directory, slash-separated) is excluded from watching.
+func (wo *WatchOptions) ignored(rel string) bool {
+	for _, dir := range wo.managed {
+		if rel == dir || strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	name := path.Base(rel)
+	for _, patterns := range [][]string{defaultWatchIgnore, wo.Ignore} {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, rel); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relativeTo returns the slash-separated path of dir relative to root if dir
+// is inside root, but is not root itself.
+func relativeTo(root, dir string) (string, bool) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// BuildState returns the most recent build of a server built from a package
+// or of a watch-mode rebuild, or nil if none ran.
+func (s *ServerInfo) BuildState() *BuildStatus {
+	s.logMutex.RLock()
+	defer s.logMutex.RUnlock()
+	if s.lastBuild == nil {
+		return nil
+	}
+	build := *s.lastBuild
+	return &build
+}
+
+// fileWatcher reports changes in a directory tree.
+type fileWatcher interface {
+	Changes() <-chan string
+	Close() error
+}
+
+// newFileWatcher watches the tree with inotify where available and falls
+// back to polling otherwise.
+func newFileWatcher(wo *WatchOptions) (fileWatcher, string) {
+	if w, err := newInotifyWatcher(wo); err == nil {
+		return w, "inotify"
+	}
+	return newPollWatcher(wo), "polling"
+}
+
+// watchServer rebuilds and restarts the server when its sources change,
+// until ctx is cancelled.
+func (sm *ServerManager) watchServer(ctx context.Context, serverInfo *ServerInfo) {
+	wo := serverInfo.opts.Watch
+	w, mode := newFileWatcher(wo)
+	defer w.Close()
+	serverInfo.addLog(StreamSystem, fmt.Sprintf("[watch] watching %s using %s", wo.Dir, mode))
+
+	for {
+		var changed string
+		select {
+		case <-ctx.Done():
+			return
+		case changed = <-w.Changes():
+		}
+
+		// Wait until no further changes arrive for the debounce period
+		timer := time.NewTimer(wo.Debounce)
+	debounce:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-w.Changes():
+				timer.Reset(wo.Debounce)
+			case <-timer.C:
+				break debounce
+			}
+		}
+
+		serverInfo.addLog(StreamSystem, fmt.Sprintf("[watch] %s changed, rebuilding", changed))
+		if !sm.rebuild(ctx, serverInfo) {
+			continue
+		}
+
+		serverInfo.addLog(StreamSystem, "[watch] build succeeded, restarting")
+		// RestartServer cancels ctx and starts a new watcher for the new run
+		if _, err := sm.RestartServer(context.WithoutCancel(ctx), serverInfo.ID); err != nil {
+			serverInfo.addLog(StreamSystem, fmt.Sprintf("[watch] restart failed: %v", err))
+			continue
+		}
+		return
+	}
+}
+
+// rebuild runs go build for the watched package and records the result.
+// Build errors are added to the server's logs. A server built from a package
+// is rebuilt with its build options, and the new binary is run on restart.
+func (sm *ServerManager) rebuild(ctx context.Context, serverInfo *ServerInfo) bool {
+	wo, cfg := serverInfo.opts.Watch, serverInfo.cfg
+	if serverInfo.opts.Build != nil {
+		if !sm.buildBinary(ctx, serverInfo).Success {
+			serverInfo.addLog(StreamSystem, "[watch] build failed, server not restarted")
+			return false
+		}
+		serverInfo.logMutex.Lock()
+		serverInfo.rebuilt = true
+		serverInfo.logMutex.Unlock()
+		return true
+	}
+
+	outDir, err := os.MkdirTemp("", "mcp-go-watch-")
+	if err != nil {
+		serverInfo.addLog(StreamSystem, fmt.Sprintf("[watch] build failed: %v", err))
+		return false
+	}
+	defer os.RemoveAll(outDir)
+
+	start := time.Now()
+	args := []string{"build", "-o", outDir + string(filepath.Separator), wo.Package}
+	result, err := ExecuteGoCommand(ctx, cfg, "go", args, wo.Dir, serverInfo.opts.EnvVars)
+
+	build := &BuildStatus{Time: start, Duration: time.Since(start).Round(time.Millisecond).String()}
+	switch {
+	case err != nil:
+		build.Output = err.Error()
+	case result.ExitCode != 0:
+		build.Output = strings.TrimSpace(result.Stderr)
+	default:
+		build.Success = true
+	}
+
+	serverInfo.logMutex.Lock()
+	serverInfo.lastBuild = build
+	serverInfo.logMutex.Unlock()
+
+	if !build.Success {
+		serverInfo.addLog(StreamSystem, "[watch] build failed, server not restarted")
+		for _, line := range strings.Split(build.Output, "\n") {
+			serverInfo.addLog(StreamStderr, "[build] "+line)
+		}
+	}
+	return build.Success
+}
+
+// pollWatcher detects changes by periodically scanning modification times.
+type pollWatcher struct {
+	wo      *WatchOptions
+	changes chan string
+	done    chan struct{}
+}
+
+func newPollWatcher(wo *WatchOptions) *pollWatcher {
+	pw := &pollWatcher{wo: wo, changes: make(chan string, 1), done: make(chan struct{})}
+	go pw.run()
+	return pw
+}
+
+func (pw *pollWatcher) Changes() <-chan string {
+	return pw.changes
+}
+
+func (pw *pollWatcher) Close() error {
+	close(pw.done)
+	return nil
+}
+
+func (pw *pollWatcher) run() {
+	ticker := time.NewTicker(pw.wo.PollInterval)
+	defer ticker.Stop()
+
+	previous := pw.scan()
+	for {
+		select {
+		case <-pw.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := pw.scan()
+		if changed := diffSnapshots(previous, current); changed != "" {
+			select {
+			case pw.changes <- changed:
+			default:
+			}
+		}
+		previous = current
+	}
+}
+
+// scan records the modification time and size of every watched file
+func (pw *pollWatcher) scan() map[string]string {
+	snapshot := make(map[string]string)
+	_ = filepath.WalkDir(pw.wo.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(pw.wo.Dir, p)
+		if rel != "." && pw.wo.ignored(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			snapshot[rel] = fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+		}
+		return nil
+	})
+	return snapshot
+}
+
+// diffSnapshots returns a path that differs between the snapshots, if any
+func diffSnapshots(previous, current map[string]string) string {
+	for p, stamp := range current {
+		if previous[p] != stamp {
+			return p
+		}
+	}
+	for p := range previous {
+		if _, ok := current[p]; !ok {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+//go:build linux
+
+package utils
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF
+
+// inotifyWatcher watches a directory tree with one inotify watch per directory.
+type inotifyWatcher struct {
+	wo      *WatchOptions
+	fd      int
+	file    *os.File
+	changes chan string
+	dirs    map[int32]string // watch descriptor -> directory
+	mu      sync.Mutex
+}
+
+func newInotifyWatcher(wo *WatchOptions) (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// A non-blocking fd is served by the runtime poller, so Close unblocks
+	// Read. File.Fd would switch it back to blocking mode, so keep fd.
+	iw := &inotifyWatcher{
+		wo:      wo,
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		changes: make(chan string, 1),
+		dirs:    make(map[int32]string),
+	}
+	if err := iw.addTree(wo.Dir); err != nil {
+		iw.file.Close()
+		return nil, err
+	}
+	go iw.run()
+	return iw, nil
+}
+
+func (iw *inotifyWatcher) Changes() <-chan string {
+	return iw.changes
+}
+
+func (iw *inotifyWatcher) Close() error {
+	return iw.file.Close()
+}
+
+// addTree adds watches for dir and every non-ignored directory below it
+func (iw *inotifyWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if rel, _ := filepath.Rel(iw.wo.Dir, p); rel != "." && iw.wo.ignored(filepath.ToSlash(rel)) {
+			return filepath.SkipDir
+		}
+		wd, err := syscall.InotifyAddWatch(iw.fd, p, inotifyMask)
+		if err != nil {
+			return err
+		}
+		iw.mu.Lock()
+		iw.dirs[int32(wd)] = p
+		iw.mu.Unlock()
+		return nil
+	})
+}
+
+func (iw *inotifyWatcher) run() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := iw.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			iw.mu.Lock()
+			dir := iw.dirs[event.Wd]
+			iw.mu.Unlock()
+			if dir == "" {
+				continue
+			}
+			full := filepath.Join(dir, string(bytes.TrimRight(nameBytes, "\x00")))
+			rel, _ := filepath.Rel(iw.wo.Dir, full)
+			if rel != "." && iw.wo.ignored(filepath.ToSlash(rel)) {
+				continue
+			}
+
+			// Watch directories created after the watcher started
+			if event.Mask&syscall.IN_CREATE != 0 && event.Mask&syscall.IN_ISDIR != 0 {
+				_ = iw.addTree(full)
+			}
+
+			select {
+			case iw.changes <- rel:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package utils
+
+import "errors"
+
+// newInotifyWatcher is only available on Linux; other platforms poll.
+func newInotifyWatcher(wo *WatchOptions) (fileWatcher, error) {
+	return nil, errors.New("inotify is not supported on this platform")
+}
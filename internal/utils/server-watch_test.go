@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchOptions_Ignored(t *testing.T) {
+	wo := &WatchOptions{Ignore: []string{"*.log", "testdata/*"}}
+
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"main.go", false},
+		{"cmd/server/main.go", false},
+		{".git", true},
+		{"pkg/.hidden.go", true},
+		{"main.go~", true},
+		{"vendor", true},
+		{"node_modules", true},
+		{"server.log", true},
+		{"logs/server.log", true},
+		{"testdata/input.txt", true},
+		{"pkg/testdata/input.txt", false},
+	}
+
+	for _, tt := range tests {
+		if got := wo.ignored(tt.path); got != tt.ignored {
+			t.Errorf("ignored(%q) = %v, want %v", tt.path, got, tt.ignored)
+		}
+	}
+}
+
+func TestWatchOptions_Validate(t *testing.T) {
+	dir := t.TempDir()
+
+	wo := &WatchOptions{}
+	if err := wo.validate(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if wo.Dir != dir || wo.Package != "." || wo.Debounce != defaultWatchDebounce || wo.PollInterval != defaultWatchPollInterval {
+		t.Errorf("Defaults not applied: %+v", wo)
+	}
+
+	wo = &WatchOptions{Dir: filepath.Join(dir, "missing")}
+	if err := wo.validate(dir); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+
+	wo = &WatchOptions{Ignore: []string{"["}}
+	if err := wo.validate(dir); err == nil {
+		t.Error("Expected error for invalid glob")
+	}
+
+	// Directories the manager writes to are ignored inside the watched tree
+	wo = &WatchOptions{}
+	if err := wo.validate(dir, filepath.Join(dir, "var", "logs"), t.TempDir()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for path, want := range map[string]bool{"var/logs": true, "var/logs/server.log": true, "var/logs.go": false, "var": false} {
+		if got := wo.ignored(path); got != want {
+			t.Errorf("ignored(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if len(wo.managed) != 1 {
+		t.Errorf("Expected only the log directory inside the tree to be ignored, got %v", wo.managed)
+	}
+}
+
+func TestPollWatcher_DetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wo := &WatchOptions{Dir: dir, PollInterval: 20 * time.Millisecond}
+	w := newPollWatcher(wo)
+	defer w.Close()
+
+	// Let the watcher take its first snapshot
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "ignored.tmp"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "handler.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changed := <-w.Changes():
+		if changed != "handler.go" {
+			t.Errorf("Expected handler.go to change, got %s", changed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a change to be reported")
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	previous := map[string]string{"a.go": "1/10", "b.go": "1/20"}
+
+	if changed := diffSnapshots(previous, map[string]string{"a.go": "1/10", "b.go": "1/20"}); changed != "" {
+		t.Errorf("Expected no change, got %s", changed)
+	}
+	if changed := diffSnapshots(previous, map[string]string{"a.go": "2/10", "b.go": "1/20"}); changed != "a.go" {
+		t.Errorf("Expected a.go to change, got %s", changed)
+	}
+	if changed := diffSnapshots(previous, map[string]string{"a.go": "1/10"}); changed != "b.go" {
+		t.Errorf("Expected b.go to be removed, got %s", changed)
+	}
+}
+
+func TestWatchServer_RebuildAndRestart(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("go.mod", "module example.com/watch\n\ngo 1.21\n")
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+
+	sm := NewServerManager()
+	serverInfo := startRestartServer(t, sm, ServerOptions{
+		ID:      "watch-rebuild",
+		Command: "sleep",
+		Args:    []string{"30"},
+		Watch:   &WatchOptions{Dir: dir, Debounce: 50 * time.Millisecond, PollInterval: 50 * time.Millisecond},
+	})
+
+	waitForBuild := func(previous *BuildStatus) *BuildStatus {
+		t.Helper()
+		deadline := time.Now().Add(30 * time.Second)
+		for time.Now().Before(deadline) {
+			if build := serverInfo.BuildState(); build != nil && (previous == nil || build.Time.After(previous.Time)) {
+				return build
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatal("Expected a build to run")
+		return nil
+	}
+
+	// Give the watcher time to start before changing files
+	time.Sleep(200 * time.Millisecond)
+	writeFile("main.go", "package main\n\nfunc main() { undefined() }\n")
+
+	failed := waitForBuild(nil)
+	if failed.Success {
+		t.Fatal("Expected the build to fail")
+	}
+	if !strings.Contains(strings.Join(serverInfo.StderrLogs.GetAll(), "\n"), "[build]") {
+		t.Error("Expected build errors in the stderr logs")
+	}
+	if restarts, _ := serverInfo.RestartState(); restarts != 0 {
+		t.Errorf("Expected no restart after a failed build, got %d", restarts)
+	}
+
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	if build := waitForBuild(failed); !build.Success {
+		t.Fatalf("Expected the build to succeed, got: %s", build.Output)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if restarts, _ := serverInfo.RestartState(); restarts == 1 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if restarts, _ := serverInfo.RestartState(); restarts != 1 {
+		t.Errorf("Expected 1 restart after a successful build, got %d", restarts)
+	}
+	if !waitForStatus(serverInfo, "running", 5*time.Second) {
+		t.Error("Expected the server to be running after the restart")
+	}
+}